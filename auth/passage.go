@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// PassageClaims 受密码保护文章的解锁凭证
+type PassageClaims struct {
+	PassageID   int    `json:"passage_id"`
+	Fingerprint string `json:"fp"` // 密码哈希的指纹，修改密码后旧凭证失效
+	jwt.RegisteredClaims
+}
+
+// passageSecret 解锁凭证的签名密钥，由 JWT secret 派生，避免解锁凭证被当作登录 token 使用
+func passageSecret() []byte {
+	sum := sha256.Sum256(append([]byte("passage-unlock:"), jwtSecret...))
+	return sum[:]
+}
+
+// GeneratePassageToken 生成文章解锁凭证，在 ttl 后过期
+func GeneratePassageToken(passageID int, fingerprint string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &PassageClaims{
+		PassageID:   passageID,
+		Fingerprint: fingerprint,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "myblog-gogogo",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(passageSecret())
+	if err != nil {
+		return "", fmt.Errorf("failed to generate passage token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// ValidatePassageToken 验证文章解锁凭证是否属于该文章且对应当前密码
+func ValidatePassageToken(tokenString string, passageID int, fingerprint string) error {
+	token, err := jwt.ParseWithClaims(tokenString, &PassageClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return passageSecret(), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to parse passage token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*PassageClaims)
+	if !ok || !token.Valid {
+		return errors.New("invalid passage token")
+	}
+	if claims.PassageID != passageID || claims.Fingerprint != fingerprint {
+		return errors.New("passage token does not match")
+	}
+	return nil
+}
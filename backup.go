@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"myblog-gogogo/config"
+	"myblog-gogogo/db"
+	"myblog-gogogo/pkg/beautify"
+	"myblog-gogogo/pkg/logger"
+	"myblog-gogogo/service/backup"
+)
+
+// runBackup 子命令 backup：将数据库快照与内容目录写入一个 tar.gz 归档后退出，服务运行时也可以执行
+// 用法：myblog-gogogo backup [-out backups] [-keep 0] [-db-conn ...]
+func runBackup(args []string) int {
+	if err := extractEmbeddedResources(); err != nil {
+		beautify.Errorf("资源释放失败: %v", err)
+		return 1
+	}
+
+	out := flag.String("out", backup.DefaultDir, "Directory to write the backup archive to")
+	keep := flag.Int("keep", 0, "Keep only the newest N backups in the output directory (0 keeps all)")
+	os.Args = append([]string{os.Args[0]}, args...)
+	cfg := config.Load()
+	logger.SetLevelFromString(cfg.LogLevel)
+
+	beautify.Header("全站备份")
+	beautify.Section("数据库初始化")
+	beautify.Indent()
+	if err := db.InitDB(cfg.DBDriver, cfg.DBConnStr, cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.DBConnMaxLifetime, cfg.DBConnMaxIdleTime); err != nil {
+		beautify.ErrorLeaf(fmt.Sprintf("初始化失败: %v", err))
+		return 1
+	}
+	defer db.CloseDB()
+	beautify.SuccessLeaf(fmt.Sprintf("连接: %s", cfg.DBConnStr))
+	beautify.Outdent()
+
+	beautify.Section(fmt.Sprintf("备份到 %s", *out))
+	beautify.Indent()
+	info, manifest, err := backup.CreateFile(*out, ".")
+	if err != nil {
+		beautify.ErrorLeaf(err.Error())
+		beautify.Outdent()
+		return 1
+	}
+	beautify.Leaf(fmt.Sprintf("文件 %d 个，共 %d 字节，压缩后 %d 字节", len(manifest.Files), manifest.TotalSize(), info.Size))
+	removed, err := backup.Prune(*out, *keep)
+	if err != nil {
+		beautify.ErrorLeaf(fmt.Sprintf("清理旧备份失败: %v", err))
+	}
+	for _, name := range removed {
+		beautify.Leaf("已删除旧备份: " + name)
+	}
+	beautify.Outdent()
+
+	beautify.Successf("备份完成: %s", info.Name)
+	return 0
+}
+
+// runRestore 子命令 restore：校验备份归档并恢复数据库与内容目录后退出，恢复前需要停止服务
+// 用法：myblog-gogogo restore -file backups/dango-backup-xxx.tar.gz [-dry-run] [-force] [-db-conn ...]
+func runRestore(args []string) int {
+	// 读取配置时会在 data 目录生成 JWT secret，恢复时被归档中的替换
+	if err := os.MkdirAll("data", 0755); err != nil {
+		beautify.Errorf("创建目录失败: %v", err)
+		return 1
+	}
+
+	file := flag.String("file", "", "Backup archive to restore")
+	dryRun := flag.Bool("dry-run", false, "Only validate the archive and the target instance")
+	force := flag.Bool("force", false, "Restore even if the instance already has content")
+	os.Args = append([]string{os.Args[0]}, args...)
+	cfg := config.Load()
+	logger.SetLevelFromString(cfg.LogLevel)
+	if *file == "" {
+		beautify.Error("请使用 -file 指定备份归档")
+		return 2
+	}
+	if cfg.DBDriver != "sqlite3" {
+		beautify.Errorf("恢复只支持 SQLite，当前驱动为 %s", cfg.DBDriver)
+		return 2
+	}
+
+	title := "全站恢复"
+	if *dryRun {
+		title = "全站恢复（预演）"
+	}
+	beautify.Header(title)
+	beautify.Section(fmt.Sprintf("校验 %s", *file))
+	beautify.Indent()
+	report, err := backup.Restore(*file, backup.RestoreOptions{
+		Root:   ".",
+		DBPath: cfg.DBConnStr,
+		Force:  *force,
+		DryRun: *dryRun,
+	})
+	if report != nil {
+		beautify.SuccessLeaf(fmt.Sprintf("清单校验通过：格式版本 %d，创建于 %s，文件 %d 个，共 %d 字节",
+			report.Manifest.Format, report.Manifest.CreatedAt.Format("2006-01-02 15:04:05"), len(report.Manifest.Files), report.Manifest.TotalSize()))
+		for _, existing := range report.Existing {
+			beautify.Warn("当前实例已有内容: " + existing)
+		}
+	}
+	beautify.Outdent()
+
+	switch {
+	case errors.Is(err, backup.ErrNotEmpty):
+		beautify.Error("当前实例已有内容，确认覆盖请加 -force，原有数据会移动到回滚目录")
+		return 1
+	case err != nil:
+		beautify.Errorf("恢复失败: %v", err)
+		if report != nil && report.Rollback != "" {
+			beautify.Warn("原有数据已移动到 " + report.Rollback)
+		}
+		return 1
+	case report.DryRun:
+		if len(report.Existing) > 0 && !*force {
+			beautify.Warn("实际恢复时需要加 -force")
+		}
+		beautify.Success("预演完成，未写入任何文件")
+	default:
+		if report.Rollback != "" {
+			beautify.Leaf("原有数据已移动到 " + report.Rollback + "，确认无误后可以删除")
+		}
+		beautify.Success("恢复完成，请重新启动服务")
+	}
+	return 0
+}
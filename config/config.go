@@ -35,6 +35,11 @@ type Config struct {
 	DBMaxIdleConns    int    // 最大空闲连接数
 	DBConnMaxLifetime int    // 连接最大存活时间(分钟)
 	DBConnMaxIdleTime int    // 连接最大空闲时间(分钟)
+	// 定时发布配置
+	SchedulerInterval int // 定时发布检查间隔(秒)
+	// 定时备份配置
+	BackupInterval int // 定时备份间隔(小时)，0 表示不启用
+	BackupKeep     int // 保留的本地备份数量
 }
 
 // Load 从命令行参数加载配置
@@ -58,6 +63,9 @@ func Load() *Config {
 	dbMaxIdleConns := flag.Int("db-max-idle-conns", 5, "Database max idle connections")
 	dbConnMaxLifetime := flag.Int("db-conn-max-lifetime", 30, "Database connection max lifetime in minutes")
 	dbConnMaxIdleTime := flag.Int("db-conn-max-idle-time", 10, "Database connection max idle time in minutes")
+	schedulerInterval := flag.Int("scheduler-interval", 60, "Scheduled publishing check interval in seconds")
+	backupInterval := flag.Int("backup-interval", 0, "Scheduled local backup interval in hours (0 to disable)")
+	backupKeep := flag.Int("backup-keep", 7, "Number of local backups to keep")
 	flag.Parse()
 
 	// 如果使用 SQLite 且路径是相对路径，将其转换为绝对路径
@@ -121,6 +129,9 @@ func Load() *Config {
 		DBMaxIdleConns:          *dbMaxIdleConns,
 		DBConnMaxLifetime:       *dbConnMaxLifetime,
 		DBConnMaxIdleTime:       *dbConnMaxIdleTime,
+		SchedulerInterval:       *schedulerInterval,
+		BackupInterval:          *backupInterval,
+		BackupKeep:              *backupKeep,
 	}
 }
 
@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"myblog-gogogo/service/backup"
+)
+
+// backupMu 同一时间只执行一个备份
+var backupMu sync.Mutex
+
+// AdminBackupsHandler 全站备份API处理器
+// GET 列出本地备份，POST 立即创建一个备份
+func AdminBackupsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		backups, err := backup.List(backup.DefaultDir)
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "读取备份目录失败: " + err.Error(),
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data":    backups,
+		})
+	case http.MethodPost:
+		if !backupMu.TryLock() {
+			writeAdminJSON(w, http.StatusConflict, map[string]interface{}{
+				"success": false,
+				"message": "备份正在进行中",
+			})
+			return
+		}
+		defer backupMu.Unlock()
+
+		info, manifest, err := backup.CreateFile(backup.DefaultDir, ".")
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "备份失败: " + err.Error(),
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("已备份 %d 个文件", len(manifest.Files)),
+			"data":    info,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// AdminBackupDownloadHandler 下载本地备份
+// GET ?name=dango-backup-xxx.tar.gz
+func AdminBackupDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if !backup.ValidName(name) {
+		http.Error(w, "Invalid backup name", http.StatusBadRequest)
+		return
+	}
+	path := filepath.Join(backup.DefaultDir, name)
+	if _, err := os.Stat(path); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeFile(w, r, path)
+}
+
+// AdminBackupVerifyHandler 校验本地备份的清单与校验和，并检查当前实例是否需要强制恢复
+// POST ?name=dango-backup-xxx.tar.gz；恢复需要停止服务后使用 restore 子命令
+func AdminBackupVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if !backup.ValidName(name) {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "无效的备份文件名",
+		})
+		return
+	}
+	report, err := backup.Restore(filepath.Join(backup.DefaultDir, name), backup.RestoreOptions{DryRun: true})
+	if err != nil {
+		writeAdminJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"success": false,
+			"message": "校验失败: " + err.Error(),
+		})
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    report,
+	})
+}
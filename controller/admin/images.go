@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"myblog-gogogo/service"
+)
+
+// AdminImageBackfillHandler 图片变体补全API处理器
+// GET 查询进度，POST 为 img 与 attachments 中已有的图片生成缩略图与 WebP 版本
+func AdminImageBackfillHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data":    service.GetImageBackfillStatus(),
+		})
+	case http.MethodPost:
+		status, err := service.StartImageBackfill()
+		if errors.Is(err, service.ErrImageBackfillRunning) {
+			writeAdminJSON(w, http.StatusConflict, map[string]interface{}{
+				"success": false,
+				"message": err.Error(),
+				"data":    status,
+			})
+			return
+		}
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "启动图片变体补全失败: " + err.Error(),
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusAccepted, map[string]interface{}{
+			"success": true,
+			"message": "已开始补全图片变体",
+			"data":    status,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"myblog-gogogo/controller"
+	"myblog-gogogo/service/importer"
+)
+
+// AdminImportHandler 导入API处理器
+// POST multipart 表单：file 为站点目录的 zip 压缩包或 WordPress WXR 文件（可与 uploads 目录一起打包为 zip），
+// source 为 hugo、hexo、jekyll 或 wordpress，dry_run=true 时只返回报告不写入
+func AdminImportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseMultipartForm(100 << 20); err != nil {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "解析表单失败: " + err.Error(),
+		})
+		return
+	}
+	source, err := importer.ParseSource(r.FormValue("source"))
+	if err != nil {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "获取文件失败，请确保选择了文件",
+		})
+		return
+	}
+	defer file.Close()
+
+	dir, err := os.MkdirTemp("", "myblog-import-")
+	if err != nil {
+		writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"message": "创建临时目录失败: " + err.Error(),
+		})
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	opts, err := importer.ExtractArchive(source, header.Filename, file, dir)
+	if err != nil {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	opts.DryRun = dryRun
+	opts.Author, _ = controller.GetUsername(r.Context())
+
+	report, err := importer.Run(opts)
+	if err != nil {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "导入失败: " + err.Error(),
+		})
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    report,
+	})
+}
@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"net/http"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/service"
+)
+
+// AdminPassageOrphansHandler 孤立文章API处理器
+// GET 列出没有被其他文章通过 [[双链]] 链接的已发布文章
+func AdminPassageOrphansHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	passages, err := db.GetPassageLinkRepository().GetOrphans()
+	if err != nil {
+		writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"message": "获取孤立文章失败",
+		})
+		return
+	}
+
+	data := make([]map[string]interface{}, 0, len(passages))
+	for i := range passages {
+		data = append(data, map[string]interface{}{
+			"id":         passages[i].ID,
+			"title":      passages[i].Title,
+			"url":        service.PassagePath(&passages[i]),
+			"category":   passages[i].Category,
+			"created_at": passages[i].CreatedAt.Format("2006-01-02"),
+		})
+	}
+
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"total":   len(data),
+	})
+}
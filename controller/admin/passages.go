@@ -10,8 +10,10 @@ import (
 	"strings"
 	"time"
 
+	"myblog-gogogo/controller"
 	"myblog-gogogo/db"
 	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/frontmatter"
 	"myblog-gogogo/service"
 )
 
@@ -23,7 +25,12 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 		// 使用临时结构体处理请求体，因为 Passage 模型已移除 Tags 字段
 		type PassageRequest struct {
 			models.Passage
-			Tags string `json:"tags"` // 临时字段，用于接收请求中的标签
+			Tags       string  `json:"tags"`        // 临时字段，用于接收请求中的标签
+			Series     *string `json:"series"`      // 所属系列名称，空字符串表示不属于任何系列
+			SeriesPart int     `json:"series_part"` // 在系列中的顺序，0 表示排在最后
+			Password   string  `json:"password"`    // 受密码保护文章的访问密码
+			ShowTitle  *bool   `json:"show_title"`  // 是否显示标题，未提供时默认显示
+			passageMetaRequest
 		}
 		var req PassageRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -37,6 +44,21 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		req.Passage.ShowTitle = req.ShowTitle == nil || *req.ShowTitle
+
+		// 内容中带有头部元数据时，以头部为准并从正文中移除
+		fm, err := applySubmittedFrontMatter(&req.Passage, &req.Tags)
+		if err != nil {
+			response := map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("头部元数据解析失败: %v", err),
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
 		// 验证必填字段
 		if req.Title == "" || req.Content == "" {
 			response := map[string]interface{}{
@@ -169,14 +191,17 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 		// 创建 passage 对象
 		passage := models.Passage{
 			Title:       req.Title,
+			Slug:        req.Slug,
 			Content:     req.Content,
+			Summary:     req.Summary,
 			Author:      req.Author,
 			Category:    req.Category,
 			Status:      req.Status,
 			Visibility:  req.Visibility,
 			IsScheduled: req.IsScheduled,
 			PublishedAt: req.PublishedAt,
-			ShowTitle:   req.ShowTitle,
+			ShowTitle:   req.Passage.ShowTitle,
+			TOCDepth:    req.TOCDepth,
 			CreatedAt:   now,
 			UpdatedAt:   now,
 		}
@@ -194,12 +219,24 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			passage.ShowTitle = true
 		}
 
-		// 保存原始内容
+		// 受密码保护的文章必须设置访问密码
+		if err := service.CheckProtectedVisibility(0, passage.Visibility, req.Password); err != nil {
+			response := map[string]interface{}{
+				"success": false,
+				"message": err.Error(),
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		// 保存原始内容，原始 HTML 按当前用户的角色过滤
 		passage.OriginalContent = passage.Content
+		passage.HTMLPolicy = service.HTMLPolicyForRole(controller.GetRole(r.Context()))
 
-		// 转换Markdown为HTML
-		htmlContent, err := service.ConvertToHTMLWithOption([]byte(passage.OriginalContent), passage.ShowTitle)
-		if err != nil {
+		// 转换Markdown为HTML并生成目录
+		if err := service.RenderPassage(&passage); err != nil {
 			response := map[string]interface{}{
 				"success": false,
 				"message": "Markdown转换失败",
@@ -209,7 +246,6 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(response)
 			return
 		}
-		passage.Content = htmlContent
 
 		// 创建markdown文件
 		cleanedTitle := service.SanitizeFilename(passage.Title)
@@ -245,8 +281,19 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			passage.FilePath = relativePath
 		}
 
-		// 写入文件
-		if err := service.UpdateMarkdownFile(filePath, passage.Title, passage.OriginalContent); err != nil {
+		// 写入文件，提交内容带有头部时按原格式回写
+		series, seriesPart := submittedSeries(req.Series, req.SeriesPart, fm)
+		meta := submittedMeta(0, req.passageMetaRequest, fm)
+		if fm != nil {
+			fm.Update(&passage, service.ParseTagNames(req.Tags))
+			if series != nil {
+				fm.UpdateSeries(*series, seriesPart)
+			}
+			if meta != nil {
+				fm.UpdateMeta(meta.Title, meta.Description, meta.Image)
+			}
+		}
+		if err := service.UpdateMarkdownFileWithFrontMatter(filePath, fm, passage.Title, passage.OriginalContent); err != nil {
 			response := map[string]interface{}{
 				"success": false,
 				"message": fmt.Sprintf("创建文件失败: %v", err),
@@ -269,6 +316,12 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(response)
 			return
 		}
+		notifyPublishScheduler(&passage)
+		recordRevision(r, &passage)
+		updatePassageLinks(&passage)
+		updatePassageSeries(passage.ID, series, seriesPart)
+		updatePassagePassword(&passage, req.Password)
+		updatePassageMeta(passage.ID, meta)
 
 		// 处理标签关联
 		if req.Tags != "" {
@@ -348,6 +401,7 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 				"created_at": passage.CreatedAt.Format("2006-01-02"),
 			},
 		}
+		addEmbedWarnings(response, &passage)
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
@@ -401,6 +455,25 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 
+			// 所属系列，不属于任何系列时为空字符串
+			seriesName, seriesPart := "", 0
+			if series, member, err := service.GetPassageSeries(passage.ID); err == nil && series != nil {
+				seriesName, seriesPart = series.Name, member.Position
+			}
+
+			// 分享元数据，未设置时为空字符串
+			meta, err := service.GetPassageMeta(passage.ID)
+			if err != nil {
+				log.Printf("Warning: 获取文章分享元数据失败: %v", err)
+				meta = &models.PassageMeta{}
+			}
+
+			// 是否已设置访问密码，密码本身不返回
+			hasPassword, err := service.HasPassagePassword(passage.ID)
+			if err != nil {
+				log.Printf("Warning: 获取文章密码失败: %v", err)
+			}
+
 			data := map[string]interface{}{
 					"id":             passage.ID,
 					"title":          passage.Title,
@@ -415,6 +488,12 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 					"is_scheduled":    passage.IsScheduled,
 					"published_at":   passage.PublishedAt,
 					"created_at":     passage.CreatedAt.Format("2006-01-02"),
+					"series":         seriesName,
+					"series_part":    seriesPart,
+					"has_password":   hasPassword,
+					"meta_title":       meta.Title,
+					"meta_description": meta.Description,
+					"meta_image":       meta.Image,
 					"content_type":   "markdown", // 标识内容类型
 				}
 
@@ -501,7 +580,11 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 		// 使用临时结构体处理请求体，因为 Passage 模型已移除 Tags 字段
 		type PassageUpdateRequest struct {
 			models.Passage
-			Tags string `json:"tags"` // 临时字段，用于接收请求中的标签
+			Tags       string  `json:"tags"`        // 临时字段，用于接收请求中的标签
+			Series     *string `json:"series"`      // 所属系列名称，未提供时保留原有系列，空字符串表示移出系列
+			SeriesPart int     `json:"series_part"` // 在系列中的顺序，0 表示保留原有顺序
+			Password   string  `json:"password"`    // 受密码保护文章的访问密码，为空时保留原有密码
+			passageMetaRequest
 		}
 		var req PassageUpdateRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -509,6 +592,19 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// 内容中带有头部元数据时，以头部为准并从正文中移除
+		fm, err := applySubmittedFrontMatter(&req.Passage, &req.Tags)
+		if err != nil {
+			response := map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("头部元数据解析失败: %v", err),
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
 		// 验证必填字段
 		if req.Title == "" || req.Content == "" {
 			response := map[string]interface{}{
@@ -579,6 +675,18 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			passage.Visibility = existingPassage.Visibility
 		}
 
+		// 受密码保护的文章必须提交密码或已有密码
+		if err := service.CheckProtectedVisibility(id, passage.Visibility, req.Password); err != nil {
+			response := map[string]interface{}{
+				"success": false,
+				"message": err.Error(),
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
 		// 如果没有提供 is_scheduled，保留原有值
 		if !passage.IsScheduled && existingPassage.IsScheduled {
 			passage.IsScheduled = existingPassage.IsScheduled
@@ -597,6 +705,16 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			passage.Category = existingPassage.Category
 		}
 
+		// 如果没有提供 slug，保留原有值
+		if passage.Slug == "" {
+			passage.Slug = existingPassage.Slug
+		}
+
+		// 如果没有提供目录层数，保留原有值
+		if passage.TOCDepth == 0 {
+			passage.TOCDepth = existingPassage.TOCDepth
+		}
+
 		// 如果没有提供原始内容，将HTML内容转换为Markdown（简化处理）
 		if passage.OriginalContent == "" {
 			// 这里假设前端发送的是Markdown格式的内容
@@ -604,9 +722,10 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			passage.OriginalContent = passage.Content
 		}
 
-		// 转换Markdown为HTML存储到Content字段，根据show_title决定是否移除第一行标题
-		htmlContent, err := service.ConvertToHTMLWithOption([]byte(passage.OriginalContent), passage.ShowTitle)
-		if err != nil {
+		// 转换Markdown为HTML存储到Content字段并生成目录，根据show_title决定是否移除第一行标题
+		// 原始 HTML 按当前用户的角色过滤
+		passage.HTMLPolicy = service.HTMLPolicyForRole(controller.GetRole(r.Context()))
+		if err := service.RenderPassage(&passage); err != nil {
 			response := map[string]interface{}{
 				"success": false,
 				"message": "Markdown转换失败",
@@ -616,7 +735,21 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(response)
 			return
 		}
-		passage.Content = htmlContent
+
+		// 提交内容或原文件带有头部时，保存时回写头部
+		header := fm
+		if header == nil {
+			header = service.ReadFrontMatter(service.PassageMarkdownPath(existingPassage.FilePath))
+		}
+		header.Update(&passage, service.ParseTagNames(req.Tags))
+		series, seriesPart := submittedSeries(req.Series, req.SeriesPart, fm)
+		if series != nil {
+			header.UpdateSeries(*series, seriesPart)
+		}
+		meta := submittedMeta(existingPassage.ID, req.passageMetaRequest, fm)
+		if meta != nil {
+			header.UpdateMeta(meta.Title, meta.Description, meta.Image)
+		}
 
 		// 检查标题是否改变，如果改变了需要重命名文件
 		var newFilePath string
@@ -658,7 +791,7 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 				log.Printf("Warning: failed to rename file %s to %s: %v", oldFilePath, newFilePath, err)
 
 				// 先创建新文件
-				if err := service.UpdateMarkdownFile(newFilePath, passage.Title, passage.OriginalContent); err != nil {
+				if err := service.UpdateMarkdownFileWithFrontMatter(newFilePath, header, passage.Title, passage.OriginalContent); err != nil {
 					response := map[string]interface{}{
 						"success": false,
 						"message": fmt.Sprintf("创建新文件失败: %v", err),
@@ -677,7 +810,7 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 				log.Printf("Renamed file: %s -> %s", filepath.Base(oldFilePath), filepath.Base(newFilePath))
 
 				// 重命名成功，更新文件内容（因为标题可能变化）
-				if err := service.UpdateMarkdownFile(newFilePath, passage.Title, passage.OriginalContent); err != nil {
+				if err := service.UpdateMarkdownFileWithFrontMatter(newFilePath, header, passage.Title, passage.OriginalContent); err != nil {
 					response := map[string]interface{}{
 						"success": false,
 						"message": fmt.Sprintf("更新文件内容失败: %v", err),
@@ -707,7 +840,7 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			if err := service.UpdateMarkdownFile(markdownFilePath, passage.Title, passage.OriginalContent); err != nil {
+			if err := service.UpdateMarkdownFileWithFrontMatter(markdownFilePath, header, passage.Title, passage.OriginalContent); err != nil {
 				response := map[string]interface{}{
 					"success": false,
 					"message": fmt.Sprintf("文件更新失败: %v", err),
@@ -719,6 +852,9 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// 覆盖前保存修订功能启用前的原始内容
+		recordRevisionBaseline(existingPassage)
+
 		// 更新数据库
 		if err := repo.Update(&passage); err != nil {
 			log.Printf("Error updating passage: %v", err)
@@ -731,6 +867,12 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(response)
 			return
 		}
+		notifyPublishScheduler(&passage)
+		recordRevision(r, &passage)
+		updatePassageLinks(&passage)
+		updatePassageSeries(passage.ID, series, seriesPart)
+		updatePassagePassword(&passage, req.Password)
+		updatePassageMeta(passage.ID, meta)
 
 		// 更新标签关联
 		tagRepo := db.GetTagRepository()
@@ -810,6 +952,7 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			"success": true,
 			"message": "文章更新成功",
 		}
+		addEmbedWarnings(response, &passage)
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
@@ -871,6 +1014,12 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			"tags":         true,
 			"summary":      true,
 			"show_title":   true,
+			"series":       true,
+			"series_part":  true,
+			"password":     true,
+			"meta_title":       true,
+			"meta_description": true,
+			"meta_image":       true,
 		}
 
 		// 构建更新数据
@@ -893,6 +1042,26 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// 受密码保护的文章必须提交密码或已有密码
+		password, _ := updateData["password"].(string)
+		visibility := existingPassage.Visibility
+		if v, ok := updateData["visibility"].(string); ok {
+			visibility = v
+		}
+		if err := service.CheckProtectedVisibility(id, visibility, password); err != nil {
+			response := map[string]interface{}{
+				"success": false,
+				"message": err.Error(),
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		// 修改前保存修订功能启用前的原始内容
+		recordRevisionBaseline(existingPassage)
+
 		// 更新现有文章对象
 		if visibility, ok := updateData["visibility"].(string); ok {
 			existingPassage.Visibility = visibility
@@ -929,6 +1098,10 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Failed to update passage", http.StatusInternalServerError)
 			return
 		}
+		notifyPublishScheduler(existingPassage)
+		recordRevision(r, existingPassage)
+		updatePassageLinks(existingPassage)
+		updatePassagePassword(existingPassage, password)
 
 		// 更新标签关联（统一使用 passage_tags 关联表）
 		// 检查是否包含 tags 字段（即使是空字符串也要更新，用于清空标签）
@@ -940,6 +1113,30 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// 系列：series_part 只在同时提供 series 时生效
+		if seriesName, ok := updateData["series"].(string); ok {
+			seriesPart, _ := updateData["series_part"].(float64)
+			updatePassageSeries(id, &seriesName, int(seriesPart))
+		}
+
+		// 分享元数据：只修改提交的字段
+		var metaReq passageMetaRequest
+		if v, ok := updateData["meta_title"].(string); ok {
+			metaReq.MetaTitle = &v
+		}
+		if v, ok := updateData["meta_description"].(string); ok {
+			metaReq.MetaDescription = &v
+		}
+		if v, ok := updateData["meta_image"].(string); ok {
+			metaReq.MetaImage = &v
+		}
+		updatePassageMeta(id, submittedMeta(id, metaReq, nil))
+
+		// 文件带有头部元数据时同步更新，避免文件与数据库不一致
+		if err := service.NewSyncService(repo).RewriteFrontMatter(existingPassage); err != nil {
+			log.Printf("Warning: 更新文件头部元数据失败: %v", err)
+		}
+
 		response := map[string]interface{}{
 			"success": true,
 			"message": "文章属性更新成功",
@@ -1022,6 +1219,7 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 				json.NewEncoder(w).Encode(response)
 				return
 			}
+			updatePassageLinks(passage)
 
 			response := map[string]interface{}{
 				"success": true,
@@ -1044,6 +1242,26 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if err := service.RemovePassageLinks(passage); err != nil {
+			log.Printf("Warning: 更新双链失败: %v", err)
+		}
+
+		// 永久删除时一并移出系列
+		if err := db.GetSeriesRepository().RemoveMembership(id); err != nil {
+			log.Printf("Warning: 移出系列失败: %v", err)
+		}
+
+		// 永久删除时一并删除各用户对该文章的草稿
+		if err := db.GetDraftRepository().DeleteByPassageID(id); err != nil {
+			log.Printf("Warning: 删除文章草稿失败: %v", err)
+		}
+
+		// 永久删除时一并清理修订记录
+		if err := db.GetPassageRevisionRepository().DeleteByPassageID(id); err != nil {
+			log.Printf("Warning: 删除文章修订记录失败: %v", err)
+		}
+		service.RemoveOGImages(id)
+
 		// 尝试删除对应的markdown文件
 		// 根据创建时间构建可能的文件路径
 		createdDate := passage.CreatedAt.Format("2006/01/02")
@@ -1069,6 +1287,142 @@ func AdminPassagesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// recordRevisionBaseline 文章还没有修订记录时，先保存修改前的内容
+func recordRevisionBaseline(passage *models.Passage) {
+	if err := service.NewRevisionService().RecordBaseline(passage); err != nil {
+		log.Printf("Warning: 记录初始修订失败: %v", err)
+	}
+}
+
+// recordRevision 记录后台编辑产生的修订，操作者为当前登录用户
+func recordRevision(r *http.Request, passage *models.Passage) {
+	author, _ := controller.GetUsername(r.Context())
+	if _, err := service.NewRevisionService().Record(passage, author, models.RevisionSourceEditor); err != nil {
+		log.Printf("Warning: 记录修订失败: %v", err)
+	}
+}
+
+// updatePassageLinks 保存文章的双链，并重新渲染链接到该文章的其他文章
+func updatePassageLinks(passage *models.Passage) {
+	if err := service.UpdatePassageLinks(passage); err != nil {
+		log.Printf("Warning: 更新双链失败: %v", err)
+	}
+}
+
+// addEmbedWarnings 正文中嵌入的音乐、附件或文章不存在时，在响应中附带提示
+func addEmbedWarnings(response map[string]interface{}, passage *models.Passage) {
+	if errs := service.EmbedErrors([]byte(passage.OriginalContent)); len(errs) > 0 {
+		response["warnings"] = errs
+	}
+}
+
+// notifyPublishScheduler 文章设置了定时发布时通知调度器重新计算下一次发布时间
+func notifyPublishScheduler(passage *models.Passage) {
+	if !passage.IsScheduled {
+		return
+	}
+	if scheduler := service.GetPublishScheduler(); scheduler != nil {
+		scheduler.Notify()
+	}
+}
+
+// updatePassagePassword 保存文章的访问密码，文章不再受密码保护时删除密码
+func updatePassagePassword(passage *models.Passage, password string) {
+	if err := service.SavePassagePassword(passage, password); err != nil {
+		log.Printf("Warning: 更新文章密码失败: %v", err)
+	}
+}
+
+// passageMetaRequest 请求中的分享元数据，字段为 nil 时保留原值
+type passageMetaRequest struct {
+	MetaTitle       *string `json:"meta_title"`       // 分享标题，为空时使用文章标题
+	MetaDescription *string `json:"meta_description"` // 分享描述，为空时使用摘要
+	MetaImage       *string `json:"meta_image"`       // 分享图片，为空时使用正文第一张图片
+}
+
+// submittedMeta 合并原有设置、提交内容头部与请求中的分享元数据，请求字段优先
+// 头部与请求都没有提供时返回 nil，表示保留原有设置
+func submittedMeta(passageID int, req passageMetaRequest, fm *frontmatter.FrontMatter) *models.PassageMeta {
+	fromHeader := fm != nil && (fm.MetaTitle != "" || fm.MetaDescription != "" || fm.Image != "")
+	if !fromHeader && req.MetaTitle == nil && req.MetaDescription == nil && req.MetaImage == nil {
+		return nil
+	}
+
+	meta := &models.PassageMeta{PassageID: passageID}
+	if passageID > 0 {
+		if existing, err := service.GetPassageMeta(passageID); err == nil {
+			meta = existing
+		}
+	}
+	if fromHeader {
+		meta.Title, meta.Description, meta.Image = fm.MetaTitle, fm.MetaDescription, fm.Image
+	}
+	if req.MetaTitle != nil {
+		meta.Title = *req.MetaTitle
+	}
+	if req.MetaDescription != nil {
+		meta.Description = *req.MetaDescription
+	}
+	if req.MetaImage != nil {
+		meta.Image = *req.MetaImage
+	}
+	return meta
+}
+
+// updatePassageMeta 保存文章的分享元数据，meta 为 nil 时不修改
+func updatePassageMeta(passageID int, meta *models.PassageMeta) {
+	if meta == nil {
+		return
+	}
+	if err := service.SetPassageMeta(passageID, meta.Title, meta.Description, meta.Image); err != nil {
+		log.Printf("Warning: 更新文章分享元数据失败: %v", err)
+	}
+}
+
+// submittedSeries 请求中的系列设置，请求未提供时使用提交内容头部中的系列
+// 两者都没有时返回 nil，表示保留原有系列
+func submittedSeries(series *string, part int, fm *frontmatter.FrontMatter) (*string, int) {
+	if series == nil && fm != nil && fm.Series != "" {
+		series = &fm.Series
+		if fm.SeriesPart != nil {
+			part = *fm.SeriesPart
+		}
+	}
+	return series, part
+}
+
+// updatePassageSeries 保存文章所属的系列，series 为 nil 时不修改
+func updatePassageSeries(passageID int, series *string, part int) {
+	if series == nil {
+		return
+	}
+	if err := service.SetPassageSeries(passageID, *series, part); err != nil {
+		log.Printf("Warning: 更新文章系列失败: %v", err)
+	}
+}
+
+// applySubmittedFrontMatter 解析提交内容开头的头部元数据，应用到文章字段并从内容中移除
+// 请求未提供标签时使用头部中的标签；内容没有头部时返回 nil
+func applySubmittedFrontMatter(passage *models.Passage, tags *string) (*frontmatter.FrontMatter, error) {
+	fm, body, err := frontmatter.Parse([]byte(passage.Content))
+	if err != nil || fm == nil {
+		return nil, err
+	}
+
+	passage.Content = string(body)
+	if passage.OriginalContent != "" {
+		if _, originalBody, err := frontmatter.Parse([]byte(passage.OriginalContent)); err == nil {
+			passage.OriginalContent = string(originalBody)
+		}
+	}
+	fm.ApplyTo(passage)
+
+	if *tags == "" {
+		*tags = fm.TagString()
+	}
+	return fm, nil
+}
+
 // DeleteMarkdownFile 删除markdown文件
 func DeleteMarkdownFile(filePath string) error {
 	// 检查文件是否存在
@@ -0,0 +1,166 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"myblog-gogogo/controller"
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/service"
+)
+
+// AdminPassagePreviewsHandler 文章预览链接API处理器
+// GET ?passage_id= 列出有效的预览链接，GET ?id= 获取预览链接的阅读记录
+// POST 生成预览链接，DELETE ?id= 撤销预览链接
+func AdminPassagePreviewsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if idStr := r.URL.Query().Get("id"); idStr != "" {
+			id := 0
+			if _, err := fmt.Sscanf(idStr, "%d", &id); err != nil || id <= 0 {
+				writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"message": "无效的预览链接ID",
+				})
+				return
+			}
+
+			link, views, err := service.GetPreviewLink(id)
+			if err != nil {
+				writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+					"success": false,
+					"message": "获取预览链接失败",
+				})
+				return
+			}
+			if link == nil {
+				writeAdminJSON(w, http.StatusNotFound, map[string]interface{}{
+					"success": false,
+					"message": "预览链接不存在",
+				})
+				return
+			}
+
+			writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"link":  previewLinkData(link, nil),
+					"views": views,
+				},
+			})
+			return
+		}
+
+		passageID := 0
+		if _, err := fmt.Sscanf(r.URL.Query().Get("passage_id"), "%d", &passageID); err != nil || passageID <= 0 {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": "缺少或无效的文章ID参数",
+			})
+			return
+		}
+
+		passage, err := db.GetPassageRepository().GetByID(passageID)
+		if err != nil || passage == nil {
+			writeAdminJSON(w, http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"message": "文章不存在",
+			})
+			return
+		}
+
+		links, err := service.ListPreviewLinks(passageID)
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "获取预览链接失败",
+			})
+			return
+		}
+
+		data := make([]map[string]interface{}, len(links))
+		for i := range links {
+			data[i] = previewLinkData(&links[i], passage)
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data":    data,
+		})
+
+	case http.MethodPost:
+		var req struct {
+			PassageID      int `json:"passage_id"`
+			ExpiresInHours int `json:"expires_in_hours"` // 有效小时数，0 表示默认 7 天，最长 30 天
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PassageID <= 0 {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": "请求格式错误",
+			})
+			return
+		}
+
+		author, _ := controller.GetUsername(r.Context())
+		link, err := service.CreatePreviewLink(req.PassageID, time.Duration(req.ExpiresInHours)*time.Hour, author)
+		if err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("生成预览链接失败: %v", err),
+			})
+			return
+		}
+
+		passage, _ := db.GetPassageRepository().GetByID(req.PassageID)
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "预览链接已生成",
+			"data":    previewLinkData(link, passage),
+		})
+
+	case http.MethodDelete:
+		id := 0
+		if _, err := fmt.Sscanf(r.URL.Query().Get("id"), "%d", &id); err != nil || id <= 0 {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": "无效的预览链接ID",
+			})
+			return
+		}
+
+		if err := service.RevokePreviewLink(id); err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "撤销预览链接失败",
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "预览链接已撤销",
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// previewLinkData 预览链接的响应数据，passage 不为 nil 时附带预览地址
+func previewLinkData(link *models.PreviewLink, passage *models.Passage) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":         link.ID,
+		"passage_id": link.PassageID,
+		"token":      link.Token,
+		"created_by": link.CreatedBy,
+		"expires_at": link.ExpiresAt.Format("2006-01-02 15:04:05"),
+		"revoked":    link.Revoked,
+		"view_count": link.ViewCount,
+		"created_at": link.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if passage != nil {
+		data["url"] = service.PreviewURL(passage, link.Token)
+	}
+	return data
+}
@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"myblog-gogogo/service"
+)
+
+// AdminPassageRebuildHandler 文章重新渲染API处理器
+// GET 查询进度，POST 在后台从 markdown 原文重新渲染全部文章
+func AdminPassageRebuildHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success":          true,
+			"data":             service.GetRebuildProgress(),
+			"renderer_version": service.RendererVersion,
+		})
+	case http.MethodPost:
+		progress, err := service.StartRebuild()
+		if errors.Is(err, service.ErrRebuildRunning) {
+			writeAdminJSON(w, http.StatusConflict, map[string]interface{}{
+				"success": false,
+				"message": err.Error(),
+				"data":    progress,
+			})
+			return
+		}
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "启动重新渲染失败: " + err.Error(),
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusAccepted, map[string]interface{}{
+			"success": true,
+			"message": "已开始重新渲染全部文章",
+			"data":    progress,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
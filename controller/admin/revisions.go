@@ -0,0 +1,204 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"myblog-gogogo/controller"
+	"myblog-gogogo/service"
+)
+
+// AdminPassageRevisionsHandler 文章修订记录API处理器
+// GET ?passage_id= 列出修订（不含正文），GET ?id= 获取单个修订的完整内容
+func AdminPassageRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	revisionService := service.NewRevisionService()
+
+	// 获取单个修订
+	if idStr := r.URL.Query().Get("id"); idStr != "" {
+		id := 0
+		if _, err := fmt.Sscanf(idStr, "%d", &id); err != nil || id <= 0 {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": "无效的修订ID",
+			})
+			return
+		}
+
+		revision, err := revisionService.GetRevision(id)
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "获取修订记录失败",
+			})
+			return
+		}
+		if revision == nil {
+			writeAdminJSON(w, http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"message": "修订记录不存在",
+			})
+			return
+		}
+
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data":    revision,
+		})
+		return
+	}
+
+	passageID := 0
+	if _, err := fmt.Sscanf(r.URL.Query().Get("passage_id"), "%d", &passageID); err != nil || passageID <= 0 {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "缺少或无效的文章ID参数",
+		})
+		return
+	}
+
+	// 解析分页参数
+	pageNum := 1
+	limitNum := 20
+	fmt.Sscanf(r.URL.Query().Get("page"), "%d", &pageNum)
+	fmt.Sscanf(r.URL.Query().Get("limit"), "%d", &limitNum)
+	if pageNum < 1 {
+		pageNum = 1
+	}
+	if limitNum < 1 || limitNum > 100 {
+		limitNum = 20
+	}
+
+	revisions, total, err := revisionService.ListRevisions(passageID, limitNum, (pageNum-1)*limitNum)
+	if err != nil {
+		writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"message": "获取修订记录失败",
+		})
+		return
+	}
+
+	// 列表中不返回正文，只返回长度
+	data := make([]map[string]interface{}, len(revisions))
+	for i, revision := range revisions {
+		data[i] = map[string]interface{}{
+			"id":             revision.ID,
+			"passage_id":     revision.PassageID,
+			"title":          revision.Title,
+			"author":         revision.Author,
+			"source":         revision.Source,
+			"content_length": len([]rune(revision.Content)),
+			"created_at":     revision.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"pagination": map[string]interface{}{
+			"page":  pageNum,
+			"limit": limitNum,
+			"total": total,
+		},
+	})
+}
+
+// AdminPassageRevisionDiffHandler 修订比较API处理器
+// GET ?from=&to= 返回从 from 到 to 的 unified diff
+func AdminPassageRevisionDiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fromID, toID := 0, 0
+	fmt.Sscanf(r.URL.Query().Get("from"), "%d", &fromID)
+	fmt.Sscanf(r.URL.Query().Get("to"), "%d", &toID)
+	if fromID <= 0 || toID <= 0 {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "缺少或无效的修订ID参数",
+		})
+		return
+	}
+
+	result, err := service.NewRevisionService().Diff(fromID, toID)
+	if err != nil {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": fmt.Sprintf("比较修订失败: %v", err),
+		})
+		return
+	}
+
+	// 支持直接获取纯文本 diff，便于用 patch 等工具处理
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+		w.Write([]byte(result.Diff))
+		return
+	}
+
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"from":    result.From.ID,
+			"to":      result.To.ID,
+			"diff":    result.Diff,
+			"added":   result.Added,
+			"removed": result.Removed,
+		},
+	})
+}
+
+// AdminPassageRevisionRestoreHandler 修订恢复API处理器
+// POST {"revision_id": 1} 将文章恢复到指定修订并重写 markdown 文件
+func AdminPassageRevisionRestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		RevisionID int `json:"revision_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RevisionID <= 0 {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "缺少或无效的修订ID",
+		})
+		return
+	}
+
+	author, _ := controller.GetUsername(r.Context())
+	policy := service.HTMLPolicyForRole(controller.GetRole(r.Context()))
+	passage, err := service.NewRevisionService().Restore(req.RevisionID, author, policy)
+	if err != nil {
+		writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"message": fmt.Sprintf("恢复修订失败: %v", err),
+		})
+		return
+	}
+
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "文章已恢复到指定修订",
+		"data": map[string]interface{}{
+			"id":         passage.ID,
+			"title":      passage.Title,
+			"updated_at": passage.UpdatedAt.Format("2006-01-02 15:04:05"),
+		},
+	})
+}
+
+// writeAdminJSON 写入 JSON 响应
+func writeAdminJSON(w http.ResponseWriter, status int, payload map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"net/http"
+
+	"myblog-gogogo/service"
+)
+
+// AdminPassageSanitizeReportHandler 列出原始 HTML 被过滤策略改变的文章
+// 每篇文章按作者角色对应的策略重新渲染，结果中包含策略名称与被移除或改写的内容
+func AdminPassageSanitizeReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	items, err := service.SanitizeReport()
+	if err != nil {
+		writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"message": "生成过滤报告失败: " + err.Error(),
+		})
+		return
+	}
+
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    items,
+		"total":   len(items),
+	})
+}
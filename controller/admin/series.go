@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/service"
+)
+
+// AdminSeriesHandler 系列管理API处理器
+// GET 列出所有系列及文章数，POST 创建系列，PUT ?id= 更新名称、别名与简介，DELETE ?id= 删除系列（文章保留，只移出系列）
+func AdminSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := service.ListSeries(false)
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "获取系列列表失败",
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data":    list,
+		})
+
+	case http.MethodPost:
+		var series models.Series
+		if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": "Invalid request body",
+			})
+			return
+		}
+		series.ID = 0
+
+		if existing, err := db.GetSeriesRepository().GetByName(series.Name); err == nil && existing != nil {
+			writeAdminJSON(w, http.StatusConflict, map[string]interface{}{
+				"success": false,
+				"message": "系列名称已存在",
+			})
+			return
+		}
+		if err := service.CreateSeries(&series); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("创建系列失败: %v", err),
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "系列创建成功",
+			"data":    series,
+		})
+
+	case http.MethodPut:
+		id, ok := seriesIDParam(w, r)
+		if !ok {
+			return
+		}
+		repo := db.GetSeriesRepository()
+		series, err := repo.GetByID(id)
+		if err != nil || series == nil {
+			writeAdminJSON(w, http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"message": "系列不存在",
+			})
+			return
+		}
+
+		var req models.Series
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": "Invalid request body",
+			})
+			return
+		}
+		if existing, err := repo.GetByName(req.Name); err == nil && existing != nil && existing.ID != id {
+			writeAdminJSON(w, http.StatusConflict, map[string]interface{}{
+				"success": false,
+				"message": "系列名称已存在",
+			})
+			return
+		}
+
+		series.Name = req.Name
+		series.Slug = req.Slug
+		series.Description = req.Description
+		if err := service.UpdateSeries(series); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("更新系列失败: %v", err),
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "系列更新成功",
+			"data":    series,
+		})
+
+	case http.MethodDelete:
+		id, ok := seriesIDParam(w, r)
+		if !ok {
+			return
+		}
+		if err := db.GetSeriesRepository().Delete(id); err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "删除系列失败",
+			})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "系列删除成功",
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// seriesIDParam 解析系列ID参数，无效时写入错误响应
+func seriesIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id := 0
+	if _, err := fmt.Sscanf(r.URL.Query().Get("id"), "%d", &id); err != nil || id <= 0 {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "缺少或无效的系列ID参数",
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"myblog-gogogo/db"
 	"myblog-gogogo/db/models"
+	"myblog-gogogo/service"
 	"myblog-gogogo/service/kafka"
 )
 
@@ -73,11 +75,12 @@ func CommentHandler(w http.ResponseWriter, r *http.Request) {
 		data := make([]map[string]interface{}, len(comments))
 		for i, c := range comments {
 			data[i] = map[string]interface{}{
-				"id":         c.ID,
-				"username":   c.Username,
-				"content":    c.Content,
-				"passage_id": c.PassageID,
-				"created_at": c.CreatedAt.Format("2006-01-02 15:04:05"),
+				"id":           c.ID,
+				"username":     c.Username,
+				"content":      c.Content,
+				"content_html": service.CommentHTML(c.Content),
+				"passage_id":   c.PassageID,
+				"created_at":   c.CreatedAt.Format("2006-01-02 15:04:05"),
 			}
 		}
 
@@ -120,7 +123,9 @@ func CommentHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// 创建评论
+		// 创建评论，发表时间由服务端决定
+		comment.ID = 0
+		comment.CreatedAt = time.Time{}
 		repo := db.GetCommentRepository()
 		if err := repo.Create(&comment); err != nil {
 			response := map[string]interface{}{
@@ -146,11 +151,12 @@ func CommentHandler(w http.ResponseWriter, r *http.Request) {
 			"success": true,
 			"message": "评论创建成功",
 			"data": map[string]interface{}{
-				"id":         comment.ID,
-				"username":   comment.Username,
-				"content":    comment.Content,
-				"passage_id": comment.PassageID,
-				"created_at": comment.CreatedAt.Format("2006-01-02 15:04:05"),
+				"id":           comment.ID,
+				"username":     comment.Username,
+				"content":      comment.Content,
+				"content_html": service.CommentHTML(comment.Content),
+				"passage_id":   comment.PassageID,
+				"created_at":   comment.CreatedAt.Format("2006-01-02 15:04:05"),
 			},
 		}
 
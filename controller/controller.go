@@ -14,6 +14,8 @@ import (
 
 	"myblog-gogogo/auth"
 	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/seo"
 	"myblog-gogogo/service"
 	"myblog-gogogo/service/settings"
 )
@@ -88,6 +90,14 @@ func renderTemplate(w http.ResponseWriter, tmpl string, data interface{}) {
 	}
 }
 
+// renderMeta 生成页面 <head> 中的 Open Graph、Twitter Card 与 JSON-LD 元数据
+func renderMeta(r *http.Request, templateSettings *settings.TemplateSettings, page seo.Page) template.HTML {
+	if page.Path == "" {
+		page.Path = r.URL.Path
+	}
+	return seo.Render(service.SiteMeta(templateSettings, SiteBaseURL(r)), page)
+}
+
 // IndexHandler 首页处理器
 func IndexHandler(w http.ResponseWriter, r *http.Request) {
 	// 获取外观设置
@@ -124,6 +134,7 @@ func IndexHandler(w http.ResponseWriter, r *http.Request) {
 		"greting":                  templateSettings.Greting,
 		"year":                     templateSettings.Year,
 		"foodes":                   templateSettings.Foodes,
+		"Meta":                     renderMeta(r, templateSettings, seo.Page{Path: "/"}),
 		"Settings":                 appearanceSettings,
 		"SwitchNotice":             templateSettings.SwitchNotice,		"SwitchNoticeText":         templateSettings.SwitchNoticeText,
 		"ExternalLinkWarning":      templateSettings.ExternalLinkWarning,
@@ -186,7 +197,7 @@ func PassageHandler(w http.ResponseWriter, r *http.Request) {
 		// 解析 markdown 文件
 		// 使用当前工作目录
 		markdownPath := filepath.Join("markdown", passage.FilePath+".md")
-		doc, err := service.ParseMarkdownFile(markdownPath)
+		doc, err := service.ParseMarkdownFileAs(markdownPath, passage.HTMLPolicy)
 		if err != nil {
 			RenderStatusPage(w, http.StatusInternalServerError)
 			return
@@ -204,14 +215,24 @@ func PassageHandler(w http.ResponseWriter, r *http.Request) {
 			articleDate = doc.CreatedAt.Format("2006-01-02")
 		}
 
+		// 受密码保护且未解锁的文章不输出正文，页面显示密码输入框
+		content, readTime := template.HTML(doc.Content), service.CalculateReadTime(doc.Content)
+		isProtected := passageLocked(r, passage)
+		if isProtected {
+			content, readTime = "", 0
+		}
+		meta := service.PassagePageMeta(passage, doc.Title, doc.Content, isProtected)
+
 		// 渲染模板
 		data := map[string]interface{}{
 			"title":                    doc.Title,
-			"Content":                  template.HTML(doc.Content),
+			"Meta":                     renderMeta(r, templateSettings, meta),
+			"Content":                  content,
+			"IsProtected":              isProtected,
 			"Date":                     articleDate,
 			"Path":                     passage.FilePath,
 			"PassageID":                passage.ID,
-			"ReadTime":                 service.CalculateReadTime(doc.Content),
+			"ReadTime":                 readTime,
 			"Settings":                 appearanceSettings,
 			"SwitchNotice":             templateSettings.SwitchNotice,
 			"SwitchNoticeText":         templateSettings.SwitchNoticeText,
@@ -242,50 +263,91 @@ func PassageHandler(w http.ResponseWriter, r *http.Request) {
 
 	// 检查是否是具体的文章路径
 	if strings.HasPrefix(r.URL.Path, "/passage/") && r.URL.Path != "/passage/" {
-		// 获取 markdown 文件路径
-		markdownPath, err := service.GetMarkdownPath(r.URL.Path)
+		// 按别名、文件路径与旧链接查找文章，旧地址 301 跳转到当前永久链接
+		lookup, err := service.ResolvePassageURL(r.URL.Path)
 		if err != nil {
+			log.Printf("Failed to resolve passage url %s: %v", r.URL.Path, err)
+			RenderStatusPage(w, http.StatusInternalServerError)
+			return
+		}
+		if lookup != nil && lookup.Redirect != "" {
+			target := lookup.Redirect
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+
+		// 获取 markdown 文件路径，原始 HTML 按文章保存的策略过滤，未入库的文件使用 editor 策略
+		var markdownPath, policy string
+		if lookup != nil {
+			markdownPath = service.PassageMarkdownPath(lookup.Passage.FilePath)
+			policy = lookup.Passage.HTMLPolicy
+		} else if markdownPath, err = service.GetMarkdownPath(r.URL.Path); err != nil {
 			RenderStatusPage(w, http.StatusNotFound)
 			return
 		}
 
 		// 解析 markdown 文件
-		doc, err := service.ParseMarkdownFile(markdownPath)
+		doc, err := service.ParseMarkdownFileAs(markdownPath, policy)
 		if err != nil {
 			RenderStatusPage(w, http.StatusInternalServerError)
 			return
 		}
 
-		// 从 URL 路径中提取日期（用于匹配附件目录）
-		// URL 路径格式: /passage/:year/:month/:day/:name
-		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		// 从文件路径中提取日期（用于匹配附件目录）
+		// 文件路径格式: :year/:month/:day/:name，未入库的文件使用 URL 路径
+		filePath := strings.TrimPrefix(r.URL.Path, "/passage/")
+		if lookup != nil {
+			filePath = lookup.Passage.FilePath
+		}
+		pathParts := strings.Split(strings.Trim(filePath, "/"), "/")
 		var articleDate string
-		if len(pathParts) >= 4 && pathParts[0] == "passage" {
-			year := pathParts[1]
-			month := pathParts[2]
-			day := pathParts[3]
+		if len(pathParts) >= 4 {
+			year := pathParts[0]
+			month := pathParts[1]
+			day := pathParts[2]
 			articleDate = fmt.Sprintf("%s-%s-%s", year, month, day)
 		} else {
-			// 如果无法从 URL 路径提取，使用文件修改时间作为 fallback
+			// 如果无法从路径提取，使用文件修改时间作为 fallback
 			articleDate = doc.CreatedAt.Format("2006-01-02")
 		}
 
-		// 根据标题从数据库获取文章ID
-		repo := db.GetPassageRepository()
-		passages, err := repo.GetAll(1000, 0)
-		if err != nil {
-			RenderStatusPage(w, http.StatusInternalServerError)
-			return
-		}
-
 		var passageID int
 		var passageStatus string
-		for _, p := range passages {
-			if p.Title == doc.Title {
-				passageID = p.ID
-				passageStatus = p.Status
-				break
+		var passage *models.Passage
+		if lookup != nil {
+			passage = lookup.Passage
+			passageID = lookup.Passage.ID
+			passageStatus = lookup.Passage.Status
+		} else {
+			// 未通过路径找到文章时，根据标题从数据库获取文章ID
+			repo := db.GetPassageRepository()
+			passages, err := repo.GetAll(1000, 0)
+			if err != nil {
+				RenderStatusPage(w, http.StatusInternalServerError)
+				return
 			}
+
+			for i, p := range passages {
+				if p.Title == doc.Title {
+					passage = &passages[i]
+					passageID = p.ID
+					passageStatus = p.Status
+					break
+				}
+			}
+		}
+
+		// 通过预览链接阅读时记录阅读日志，页面不显示未发布提示，也不缓存、不收录
+		previewLink, _ := r.Context().Value("passage_preview_link").(*models.PreviewLink)
+		if previewLink != nil {
+			ip := service.GetClientIP(r.RemoteAddr, r.Header.Get("X-Forwarded-For"), r.Header.Get("X-Real-IP"))
+			go service.RecordPreviewView(previewLink, ip, r.UserAgent())
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+			w.Header().Set("Referrer-Policy", "no-referrer")
 		}
 
 		// 检查文章状态，如果不是 published 状态，从上下文中读取未发布信息
@@ -294,7 +356,7 @@ func PassageHandler(w http.ResponseWriter, r *http.Request) {
 		var isScheduled bool
 		var publishedAt string
 		
-		if passageStatus != "published" {
+		if passageStatus != "published" && previewLink == nil {
 			// 从上下文中读取未发布信息
 			isUnpublished = true
 			passageStatusInfo = passageStatus
@@ -307,14 +369,27 @@ func PassageHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// 受密码保护且未解锁的文章不输出正文，页面显示密码输入框
+		content, readTime := template.HTML(doc.Content), service.CalculateReadTime(doc.Content)
+		isProtected := previewLink == nil && passageLocked(r, passage)
+		if isProtected {
+			content, readTime = "", 0
+		}
+		meta := service.PassagePageMeta(passage, doc.Title, doc.Content, isProtected)
+		if previewLink != nil {
+			meta.NoIndex = true
+		}
+
 		// 渲染模板（服务器端渲染）
 		data := map[string]interface{}{
 			"title":                    doc.Title,
-			"Content":                  template.HTML(doc.Content),
+			"Meta":                     renderMeta(r, templateSettings, meta),
+			"Content":                  content,
+			"IsProtected":              isProtected,
 			"Date":                     articleDate,
-			"Path":                     strings.TrimPrefix(r.URL.Path, "/passage/"),
+			"Path":                     filePath,
 			"PassageID":                passageID,
-			"ReadTime":                 service.CalculateReadTime(doc.Content),
+			"ReadTime":                 readTime,
 			"Settings":                 appearanceSettings,
 			"SwitchNotice":             templateSettings.SwitchNotice,
 			"SwitchNoticeText":         templateSettings.SwitchNoticeText,
@@ -340,6 +415,7 @@ func PassageHandler(w http.ResponseWriter, r *http.Request) {
 			"SponsorButtonText":        templateSettings.SponsorButtonText,
 			// 未发布文章信息
 			"IsUnpublished":            isUnpublished,
+			"IsPreview":                previewLink != nil,
 			"PassageStatus":            passageStatusInfo,
 			"IsScheduled":              isScheduled,
 			"PublishedAt":              publishedAt,
@@ -351,6 +427,7 @@ func PassageHandler(w http.ResponseWriter, r *http.Request) {
 	// 默认显示文章列表页面
 	data := map[string]interface{}{
 		"title":                    "文章列表",
+		"Meta":                     renderMeta(r, templateSettings, seo.Page{Title: "文章列表"}),
 		"Settings":                 appearanceSettings,
 		"SwitchNotice":             templateSettings.SwitchNotice,
 		"SwitchNoticeText":         templateSettings.SwitchNoticeText,
@@ -375,6 +452,11 @@ func PassageHandler(w http.ResponseWriter, r *http.Request) {
 
 // CollectHandler 归档页面处理器
 func CollectHandler(w http.ResponseWriter, r *http.Request) {
+	renderCollect(w, r, seo.Page{Title: "我的归档"})
+}
+
+// renderCollect 渲染归档页面，分类、标签与系列页面也使用归档页面，由页面脚本按路径加载内容
+func renderCollect(w http.ResponseWriter, r *http.Request, page seo.Page) {
 	// 获取外观设置
 	appearanceSettings := getAppearanceSettings()
 
@@ -395,7 +477,8 @@ func CollectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := map[string]interface{}{
-		"title":                    "我的归档",
+		"title":                    page.Title,
+		"Meta":                     renderMeta(r, templateSettings, page),
 		"Settings":                 appearanceSettings,
 		"SwitchNotice":             templateSettings.SwitchNotice,
 		"SwitchNoticeText":         templateSettings.SwitchNoticeText,
@@ -418,6 +501,20 @@ func CollectHandler(w http.ResponseWriter, r *http.Request) {
 	renderTemplate(w, "collect.html", data)
 }
 
+// TaxonomyHandler 分类和标签页面处理器
+// /category/{name} 与 /tag/{name} 显示按分类或标签筛选的归档页，/category/{name}/feed.xml 等为对应的订阅源
+func TaxonomyHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3:
+		FeedHandler(w, r)
+	case len(parts) == 2 && parts[1] != "":
+		CollectHandler(w, r)
+	default:
+		RenderStatusPage(w, http.StatusNotFound)
+	}
+}
+
 // AnalyzeHandler 分析页面处理器
 func AnalyzeHandler(w http.ResponseWriter, r *http.Request) {
 	// 获取模板设置
@@ -470,6 +567,7 @@ func AboutHandler(w http.ResponseWriter, r *http.Request) {
 
 	data := map[string]interface{}{
 		"title":                    "关于我",
+		"Meta":                     renderMeta(r, templateSettings, seo.Page{Title: "关于我"}),
 		"Settings":                 appearanceSettings,
 		"SwitchNotice":             templateSettings.SwitchNotice,
 		"SwitchNoticeText":         templateSettings.SwitchNoticeText,
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"myblog-gogogo/pkg/feed"
+	"myblog-gogogo/service"
+	"myblog-gogogo/service/settings"
+)
+
+// feedFormat 订阅源输出格式
+type feedFormat struct {
+	contentType string
+	encode      func(f *feed.Feed) ([]byte, error)
+}
+
+// feedFormats 按文件名区分的订阅源格式
+var feedFormats = map[string]feedFormat{
+	"feed.xml":  {"application/rss+xml; charset=utf-8", (*feed.Feed).RSS},
+	"atom.xml":  {"application/atom+xml; charset=utf-8", (*feed.Feed).Atom},
+	"feed.json": {"application/feed+json; charset=utf-8", (*feed.Feed).JSON},
+}
+
+// FeedHandler 订阅源处理器
+// 全站: /feed.xml /atom.xml /feed.json
+// 分类: /category/{name}/feed.xml 等，标签: /tag/{name}/feed.xml 等
+func FeedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var scope service.FeedScope
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 1:
+	case len(parts) == 3 && (parts[0] == "category" || parts[0] == "tag"):
+		name, err := url.PathUnescape(parts[1])
+		if err != nil || strings.TrimSpace(name) == "" {
+			RenderStatusPage(w, http.StatusNotFound)
+			return
+		}
+		if parts[0] == "category" {
+			scope.Category = name
+		} else {
+			scope.Tag = name
+		}
+	default:
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+
+	format, ok := feedFormats[parts[len(parts)-1]]
+	if !ok {
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+
+	baseURL := SiteBaseURL(r)
+	f, err := service.NewFeedService().Build(baseURL, scope)
+	if err != nil {
+		log.Printf("Failed to build feed: %v", err)
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+	if f == nil {
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+	f.FeedURL = baseURL + "/" + path
+
+	body, err := format.encode(f)
+	if err != nil {
+		log.Printf("Failed to encode feed: %v", err)
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+
+	serveGenerated(w, r, format.contentType, f.LastUpdated(), body)
+}
+
+// serveGenerated 输出动态生成的内容，并支持条件请求
+// ETag 基于内容生成，设置变化（如全文/摘要切换）后也会更新；
+// http.ServeContent 负责处理 If-None-Match 与 If-Modified-Since
+func serveGenerated(w http.ResponseWriter, r *http.Request, contentType string, modTime time.Time, body []byte) {
+	sum := sha256.Sum256(body)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(body))
+}
+
+// SiteBaseURL 返回不带结尾斜杠的站点根地址
+// 优先使用模板设置中的站点地址，未设置时根据请求推断
+func SiteBaseURL(r *http.Request) string {
+	if template, err := settings.GetTemplate(); err == nil {
+		if siteURL := strings.TrimRight(strings.TrimSpace(template.SiteURL), "/"); siteURL != "" {
+			return siteURL
+		}
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "https" || proto == "http" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
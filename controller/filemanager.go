@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"myblog-gogogo/auth"
+	"myblog-gogogo/pkg/imagevariant"
 )
 
 // FileInfo 文件信息结构
@@ -322,6 +323,9 @@ func handleDeleteFile(w http.ResponseWriter, r *http.Request) {
 		deleteErr = os.RemoveAll(safePath)
 	} else {
 		deleteErr = os.Remove(safePath)
+		if deleteErr == nil {
+			imagevariant.Remove(safePath)
+		}
 	}
 
 	if deleteErr != nil {
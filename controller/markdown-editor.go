@@ -2,9 +2,8 @@ package controller
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"myblog-gogogo/db"
@@ -55,10 +54,8 @@ func MarkdownEditorSaveHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 检查用户权限（需要管理员权限）
-	role, ok := r.Context().Value(RoleKey).(string)
-	if !ok || role != "admin" {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+	if GetRole(r.Context()) != "admin" {
+		writeEditorJSON(w, http.StatusOK, map[string]interface{}{
 			"success": false,
 			"message": "需要管理员权限才能保存文章",
 		})
@@ -66,126 +63,269 @@ func MarkdownEditorSaveHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 解析请求体
-	var req struct {
-		Title    string `json:"title"`
-		Content  string `json:"content"`
-		Category string `json:"category"`
-		Tags     string `json:"tags"`
-		Summary  string `json:"summary"`
-	}
-
+	var req service.EditorPassage
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+		writeEditorJSON(w, http.StatusOK, map[string]interface{}{
 			"success": false,
 			"message": "无效的请求数据",
 		})
 		return
 	}
 
-	// 验证必填字段
-	if req.Title == "" {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+	// 写入 markdown 文件并创建文章
+	username, _ := GetUsername(r.Context())
+	passage, err := service.CreateEditorPassage(req, username, service.HTMLPolicyForRole(GetRole(r.Context())))
+	if err != nil {
+		writeEditorJSON(w, http.StatusOK, map[string]interface{}{
 			"success": false,
-			"message": "文章标题不能为空",
+			"message": err.Error(),
 		})
 		return
 	}
 
-	if req.Content == "" {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+	writeEditorJSON(w, http.StatusOK, editorPublishResponse(passage, "文章保存成功"))
+}
+
+// MarkdownEditorPreviewHandler 渲染编辑器预览，结果与发布后的文章一致，不保存任何内容
+// 请求体为 {"content": "...", "passage_id": 0}，passage_id 为草稿对应的已有文章
+// 与保存相同只允许管理员使用，其他用户的编辑器使用浏览器端预览
+func MarkdownEditorPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if userID, ok := GetUserID(r.Context()); !ok || userID <= 0 {
+		writeEditorJSON(w, http.StatusUnauthorized, map[string]interface{}{
+			"success": false,
+			"message": "请先登录",
+		})
+		return
+	}
+	// 管理员可以编辑所有文章，通过检查后才会读取 passage_id 对应的文章
+	if GetRole(r.Context()) != "admin" {
+		writeEditorJSON(w, http.StatusForbidden, map[string]interface{}{
 			"success": false,
-			"message": "文章内容不能为空",
+			"message": "需要管理员权限才能预览文章",
 		})
 		return
 	}
 
-	// 转换 Markdown 为 HTML
-	htmlContent, err := service.ConvertToHTML([]byte(req.Content))
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+	var req struct {
+		Content   string `json:"content"`
+		PassageID int    `json:"passage_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeEditorJSON(w, http.StatusBadRequest, map[string]interface{}{
 			"success": false,
-			"message": "Markdown转换失败",
+			"message": "无效的请求数据",
 		})
 		return
 	}
 
-	// 构建文件路径（按日期组织）
-	now := time.Now()
-	dateDir := now.Format("2006/01/02")
-	filePath := strings.Join([]string{"markdown", dateDir, req.Title + ".md"}, "/")
+	var passage *models.Passage
+	if req.PassageID > 0 {
+		p, err := db.GetPassageRepository().GetByID(req.PassageID)
+		if err != nil || p == nil {
+			writeEditorJSON(w, http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"message": "文章不存在",
+			})
+			return
+		}
+		passage = p
+	}
 
-	// 保存 Markdown 文件到磁盘
-	if err := service.UpdateMarkdownFile(filePath, req.Title, req.Content); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+	username, _ := GetUsername(r.Context())
+	preview, err := service.PreviewMarkdown(req.Content, passage, username, service.HTMLPolicyForRole(GetRole(r.Context())))
+	if err != nil {
+		writeEditorJSON(w, http.StatusBadRequest, map[string]interface{}{
 			"success": false,
-			"message": "保存Markdown文件失败: " + err.Error(),
+			"message": err.Error(),
 		})
 		return
 	}
+	writeEditorJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    preview,
+	})
+}
 
-	// 设置默认分类
-	if req.Category == "" {
-		req.Category = "未分类"
+// MarkdownEditorDraftHandler 编辑器草稿API处理器，草稿按当前用户与文章保存
+// GET ?passage_id= 获取草稿，不带参数时列出所有草稿；PUT/POST 自动保存；DELETE ?passage_id= 丢弃草稿
+// passage_id 为 0 或省略时表示尚未发布的新文章
+func MarkdownEditorDraftHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok || userID <= 0 {
+		writeEditorJSON(w, http.StatusUnauthorized, map[string]interface{}{
+			"success": false,
+			"message": "请先登录",
+		})
+		return
 	}
+	drafts := service.NewDraftService()
 
-	// 设置默认摘要
-	if req.Summary == "" {
-		req.Summary = "暂无摘要"
-	}
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("passage_id") == "" {
+			list, err := drafts.List(userID)
+			if err != nil {
+				writeEditorJSON(w, http.StatusInternalServerError, map[string]interface{}{
+					"success": false,
+					"message": "获取草稿列表失败",
+				})
+				return
+			}
+			writeEditorJSON(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"data":    list,
+			})
+			return
+		}
+
+		passageID, ok := draftPassageIDParam(w, r)
+		if !ok {
+			return
+		}
+		draft, err := drafts.Get(userID, passageID)
+		if err != nil {
+			writeEditorJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "获取草稿失败",
+			})
+			return
+		}
+		writeEditorJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data":    draft,
+		})
+
+	case http.MethodPut, http.MethodPost:
+		var draft models.Draft
+		if err := json.NewDecoder(r.Body).Decode(&draft); err != nil {
+			writeEditorJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": "无效的请求数据",
+			})
+			return
+		}
+		draft.ID = 0
+		draft.UserID = userID
+		draft.CreatedAt = time.Time{}
 
-	// 获取用户信息
-	username, _ := r.Context().Value(UsernameKey).(string)
+		if err := drafts.Save(&draft); err != nil {
+			writeEditorJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"message": "保存草稿失败: " + err.Error(),
+			})
+			return
+		}
+		writeEditorJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "草稿已保存",
+			"data": map[string]interface{}{
+				"id":         draft.ID,
+				"passage_id": draft.PassageID,
+				"updated_at": draft.UpdatedAt.Format("2006-01-02 15:04:05"),
+			},
+		})
+
+	case http.MethodDelete:
+		passageID, ok := draftPassageIDParam(w, r)
+		if !ok {
+			return
+		}
+		if err := drafts.Discard(userID, passageID); err != nil {
+			writeEditorJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"success": false,
+				"message": "删除草稿失败",
+			})
+			return
+		}
+		writeEditorJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "草稿已删除",
+		})
 
-	// 创建文章记录
-	passage := &models.Passage{
-		Title:           req.Title,
-		Content:         htmlContent,
-		OriginalContent: req.Content,
-		Summary:         req.Summary,
-		Author:          username,
-		Category:        req.Category,
-		Status:          "published",
-		FilePath:        filePath,
-		ShowTitle:       true,
-		CreatedAt:       now,
-		UpdatedAt:       now,
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
+}
 
-	// 保存到数据库
-	repo := db.GetPassageRepository()
-	if err := repo.Create(passage); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+// MarkdownEditorPublishDraftHandler 发布草稿 POST ?passage_id=
+// 新文章草稿写入 markdown 文件并创建文章，已有文章的草稿覆盖文章内容，发布后删除草稿
+func MarkdownEditorPublishDraftHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := GetUserID(r.Context())
+	if !ok || userID <= 0 || GetRole(r.Context()) != "admin" {
+		writeEditorJSON(w, http.StatusForbidden, map[string]interface{}{
 			"success": false,
-			"message": "保存到数据库失败: " + err.Error(),
+			"message": "需要管理员权限才能发布文章",
 		})
 		return
 	}
 
-	// 处理标签关联（使用 passage_tags 关联表）
-	if req.Tags != "" {
-		syncService := service.NewSyncService(repo)
-		if err := syncService.UpdatePassageTags(passage.ID, req.Tags); err != nil {
-			// 记录错误但不影响主流程
-			log.Printf("Warning: 创建标签关联失败: %v", err)
-		}
+	passageID, ok := draftPassageIDParam(w, r)
+	if !ok {
+		return
 	}
 
-	// 返回成功响应
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	username, _ := GetUsername(r.Context())
+	passage, err := service.NewDraftService().Publish(userID, passageID, username)
+	if err != nil {
+		writeEditorJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "发布草稿失败: " + err.Error(),
+		})
+		return
+	}
+
+	writeEditorJSON(w, http.StatusOK, editorPublishResponse(passage, "草稿发布成功"))
+}
+
+// editorPublishResponse 文章保存或发布成功的响应，嵌入的对象不存在时附带提示
+func editorPublishResponse(passage *models.Passage, message string) map[string]interface{} {
+	response := map[string]interface{}{
 		"success": true,
-		"message": "文章保存成功",
+		"message": message,
 		"data": map[string]interface{}{
 			"id":         passage.ID,
 			"title":      passage.Title,
 			"file_path":  passage.FilePath,
+			"url":        service.PassagePath(passage),
 			"created_at": passage.CreatedAt.Format("2006-01-02 15:04:05"),
 		},
-	})
-}
\ No newline at end of file
+	}
+	if errs := service.EmbedErrors([]byte(passage.OriginalContent)); len(errs) > 0 {
+		response["warnings"] = errs
+	}
+	return response
+}
+
+// draftPassageIDParam 解析草稿对应的文章ID参数，省略时为新文章草稿，无效时写入错误响应
+func draftPassageIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.URL.Query().Get("passage_id")
+	if idStr == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 0 {
+		writeEditorJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "无效的文章ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// writeEditorJSON 写入编辑器API的JSON响应
+func writeEditorJSON(w http.ResponseWriter, status int, payload map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
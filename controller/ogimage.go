@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"myblog-gogogo/service"
+)
+
+// OGImageHandler /og/{id}.png 处理器，输出文章的分享卡片
+// 卡片在第一次请求时生成并缓存，文章标题、标签或外观设置变化后重新生成
+func OGImageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, service.OGImagePrefix)
+	id, err := strconv.Atoi(strings.TrimSuffix(name, ".png"))
+	if err != nil || !strings.HasSuffix(name, ".png") || id < 1 {
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+
+	path, err := service.GetOGImage(id)
+	if err != nil {
+		log.Printf("Failed to generate og image for passage %d: %v", id, err)
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+	if path == "" {
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Failed to read og image %s: %v", path, err)
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+	serveGenerated(w, r, "image/png", info.ModTime(), body)
+}
@@ -12,8 +12,10 @@ import (
 	"myblog-gogogo/db"
 	"myblog-gogogo/db/models"
 	"myblog-gogogo/pkg/dto"
+	"myblog-gogogo/pkg/toc"
 	"myblog-gogogo/service"
 	"myblog-gogogo/service/kafka"
+	"myblog-gogogo/service/settings"
 )
 
 // PassageAPIHandler 文章列表API处理器
@@ -91,6 +93,7 @@ func PassageAPIHandler(w http.ResponseWriter, r *http.Request) {
 			passageTagsMap = make(map[int][]int)
 		}
 
+		isAdmin := GetRole(r.Context()) == "admin"
 		for i, p := range passages {
 			// 从映射中获取标签名称
 			tagNames := []string{}
@@ -102,10 +105,18 @@ func PassageAPIHandler(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 
+			// 受密码保护文章的摘要只对管理员可见
+			summary := p.Summary
+			if service.IsProtectedPassage(&p) && !isAdmin {
+				summary = ""
+			}
+
 			article := map[string]interface{}{
 				"id":         p.ID,
 				"title":      p.Title,
-				"summary":    p.Summary,
+				"slug":       p.Slug,
+				"url":        service.PassagePath(&p),
+				"summary":    summary,
 				"tags":       tagNames,
 				"category":   p.Category,
 				"created_at": p.CreatedAt.Format("2006-01-02"),
@@ -137,11 +148,16 @@ func PassageAPIHandler(w http.ResponseWriter, r *http.Request) {
 
 	case http.MethodPost:
 		// 创建新文章
-		var passage models.Passage
-		if err := json.NewDecoder(r.Body).Decode(&passage); err != nil {
+		var req struct {
+			models.Passage
+			ShowTitle *bool `json:"show_title"` // 是否显示标题，未提供时默认显示
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			apperrors.SendBadRequest(w, "INVALID_REQUEST_BODY", "请求格式错误")
 			return
 		}
+		passage := req.Passage
+		passage.ShowTitle = req.ShowTitle == nil || *req.ShowTitle
 
 		// 创建文章
 		repo := db.GetPassageRepository()
@@ -177,6 +193,10 @@ func PassageAPIHandler(w http.ResponseWriter, r *http.Request) {
 
 // PassageDetailHandler 文章详情API处理器
 func PassageDetailHandler(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/unlock") {
+		PassageUnlockHandler(w, r)
+		return
+	}
 	if r.Method != http.MethodGet {
 		apperrors.SendError(w, apperrors.ErrMethodNotAllowed)
 		return
@@ -200,8 +220,10 @@ func PassageDetailHandler(w http.ResponseWriter, r *http.Request) {
 	// 使用 PassageService 检查访问权限
 	passageSvc := service.NewPassageService()
 	accessResp, err := passageSvc.CheckAccess(&dto.PassageAccessRequest{
-		PassageID: id,
-		UserRole:  role,
+		PassageID:    id,
+		UserRole:     role,
+		UnlockToken:  passageUnlockToken(r, id),
+		PreviewToken: r.URL.Query().Get("preview"),
 	})
 	if err != nil {
 		apperrors.SendError(w, err)
@@ -293,16 +315,50 @@ func PassageDetailHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// 链接到本文的文章
+	backlinks := []map[string]interface{}{}
+	if sources, err := db.GetPassageLinkRepository().GetBacklinks(id); err == nil {
+		for i := range sources {
+			backlinks = append(backlinks, map[string]interface{}{
+				"id":    sources[i].ID,
+				"title": sources[i].Title,
+				"url":   service.PassagePath(&sources[i]),
+			})
+		}
+	}
+
+	// 所属系列的目录与上一篇、下一篇，不属于任何系列时为 null
+	seriesNav, err := service.GetSeriesNav(id)
+	if err != nil {
+		fmt.Printf("Warning: 获取文章系列失败: %v\n", err)
+	}
+
+	// 预先计算好的相关文章，数量由模板设置决定
+	relatedCount := 5
+	if templateSettings, err := settings.GetTemplate(); err == nil {
+		relatedCount = templateSettings.RelatedPassageCount
+	}
+	related, err := service.GetRelatedPassages(id, relatedCount)
+	if err != nil {
+		fmt.Printf("Warning: 获取相关文章失败: %v\n", err)
+	}
+
 	response := map[string]interface{}{
 		"success": true,
 		"data": map[string]interface{}{
 			"id":         accessResp.Passage.ID,
 			"title":      accessResp.Passage.Title,
+			"slug":       passage.Slug,
+			"url":        service.PassagePath(passage),
 			"content":    accessResp.Passage.Content,
 			"summary":    passage.Summary,
 			"tags":       tagNames,
 			"category":   passage.Category,
 			"show_title": accessResp.Passage.ShowTitle,
+			"toc":        toc.Unmarshal(passage.TOC),
+			"backlinks":  backlinks,
+			"series":     seriesNav,
+			"related":    related,
 			"created_at": accessResp.Passage.CreatedAt.Format("2006-01-02"),
 			"updated_at": accessResp.Passage.UpdatedAt.Format("2006-01-02"),
 		},
@@ -460,3 +516,65 @@ func filterPublishedPassages(passages []models.Passage) []models.Passage {
 	}
 	return published
 }
+
+// PassageUnlockHandler 受密码保护文章的解锁接口，密码正确时发放只对该文章有效的短期 cookie
+func PassageUnlockHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apperrors.SendError(w, apperrors.ErrMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/passages/")
+	id, err := strconv.Atoi(strings.TrimSuffix(path, "/unlock"))
+	if err != nil {
+		apperrors.SendBadRequest(w, "INVALID_PASSAGE_ID", "无效的文章ID")
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.SendBadRequest(w, "INVALID_REQUEST_BODY", "请求格式错误")
+		return
+	}
+
+	token, err := service.UnlockPassage(id, req.Password)
+	if err != nil {
+		apperrors.SendError(w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     service.PassageUnlockCookie(id),
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(service.PassageUnlockTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "解锁成功",
+	})
+}
+
+// passageLocked 判断当前读者是否还需要输入密码才能阅读文章，管理员不需要
+func passageLocked(r *http.Request, passage *models.Passage) bool {
+	if !service.IsProtectedPassage(passage) || GetRole(r.Context()) == "admin" {
+		return false
+	}
+	return !service.IsPassageUnlocked(passage.ID, passageUnlockToken(r, passage.ID))
+}
+
+// passageUnlockToken 从 cookie 中读取文章的解锁凭证
+func passageUnlockToken(r *http.Request, passageID int) string {
+	cookie, err := r.Cookie(service.PassageUnlockCookie(passageID))
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
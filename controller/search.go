@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "myblog-gogogo/pkg/errors"
+	"myblog-gogogo/pkg/search"
+	"myblog-gogogo/service"
+)
+
+// SearchAPIHandler 文章全文检索API处理器
+// GET ?q=关键词&tag=a,b&category=&from=2024-01-01&to=2024-12-31&page=1&limit=10
+// 管理员可以检索到未发布和私密文章，其他用户只能检索公开的已发布文章
+func SearchAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apperrors.SendError(w, apperrors.ErrMethodNotAllowed)
+		return
+	}
+
+	searchService := service.GetSearchService()
+	if searchService == nil {
+		apperrors.SendError(w, apperrors.ErrServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	q := search.Query{
+		Text:     strings.TrimSpace(query.Get("q")),
+		Category: strings.TrimSpace(query.Get("category")),
+	}
+	if q.Category == "all" {
+		q.Category = ""
+	}
+	for _, tag := range strings.Split(query.Get("tag"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			q.Tags = append(q.Tags, tag)
+		}
+	}
+
+	// 日期范围按天计算，截止日期当天的文章也包含在内
+	if from := query.Get("from"); from != "" {
+		since, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			apperrors.SendBadRequest(w, "INVALID_DATE", "起始日期格式错误，应为 YYYY-MM-DD")
+			return
+		}
+		q.Since = since
+	}
+	if to := query.Get("to"); to != "" {
+		until, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			apperrors.SendBadRequest(w, "INVALID_DATE", "截止日期格式错误，应为 YYYY-MM-DD")
+			return
+		}
+		q.Until = until.AddDate(0, 0, 1)
+	}
+
+	if q.Text == "" && q.Category == "" && len(q.Tags) == 0 && q.Since.IsZero() && q.Until.IsZero() {
+		apperrors.SendBadRequest(w, "MISSING_QUERY", "请输入搜索关键词或筛选条件")
+		return
+	}
+	if len([]rune(q.Text)) > 100 {
+		apperrors.SendBadRequest(w, "QUERY_TOO_LONG", "搜索关键词过长")
+		return
+	}
+
+	// 解析分页参数
+	pageNum, err := strconv.Atoi(query.Get("page"))
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+	limitNum, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limitNum < 1 || limitNum > 50 {
+		limitNum = 10
+	}
+	q.Limit = limitNum
+	q.Offset = (pageNum - 1) * limitNum
+
+	q.IncludeHidden = GetRole(r.Context()) == "admin"
+
+	hits, total := searchService.Search(q)
+
+	data := make([]map[string]interface{}, len(hits))
+	for i, hit := range hits {
+		doc := hit.Document
+		tags := doc.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		item := map[string]interface{}{
+			"id":              doc.ID,
+			"title":           doc.Title,
+			"title_highlight": hit.TitleSnippet,
+			"summary":         doc.Summary,
+			"snippet":         hit.Snippet,
+			"tags":            tags,
+			"category":        doc.Category,
+			"created_at":      doc.CreatedAt.Format("2006-01-02"),
+			"score":           hit.Score,
+		}
+		if q.IncludeHidden {
+			item["status"] = doc.Status
+			item["visibility"] = doc.Visibility
+		}
+		data[i] = item
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"pagination": map[string]interface{}{
+			"page":  pageNum,
+			"limit": limitNum,
+			"total": total,
+		},
+	})
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	apperrors "myblog-gogogo/pkg/errors"
+	"myblog-gogogo/pkg/seo"
+	"myblog-gogogo/service"
+)
+
+// SeriesAPIHandler 系列列表API处理器，只包含有公开文章的系列
+func SeriesAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apperrors.SendError(w, apperrors.ErrMethodNotAllowed)
+		return
+	}
+
+	list, err := service.ListSeries(true)
+	if err != nil {
+		apperrors.SendError(w, apperrors.Wrap(err, "DB_ERROR", "获取系列失败"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    list,
+	})
+}
+
+// SeriesDetailAPIHandler 系列详情API处理器，/series/{slug} 返回系列信息与按顺序排列的公开文章
+func SeriesDetailAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apperrors.SendError(w, apperrors.ErrMethodNotAllowed)
+		return
+	}
+
+	slug := strings.Trim(strings.TrimPrefix(r.URL.Path, "/series/"), "/")
+	if slug == "" {
+		SeriesAPIHandler(w, r)
+		return
+	}
+
+	detail, err := service.GetSeriesDetail(slug)
+	if err != nil {
+		apperrors.SendError(w, apperrors.Wrap(err, "DB_ERROR", "获取系列失败"))
+		return
+	}
+	if detail == nil {
+		apperrors.SendNotFound(w, "SERIES_NOT_FOUND", "系列不存在")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    detail,
+	})
+}
+
+// SeriesHandler 系列页面处理器
+// /series/ 列出所有系列，/series/{slug} 按顺序列出系列中的文章
+func SeriesHandler(w http.ResponseWriter, r *http.Request) {
+	slug := strings.Trim(strings.TrimPrefix(r.URL.Path, service.SeriesPathPrefix), "/")
+	if slug == "" {
+		renderCollect(w, r, seo.Page{Title: "系列"})
+		return
+	}
+	if strings.Contains(slug, "/") {
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+
+	detail, err := service.GetSeriesDetail(slug)
+	if err != nil {
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+	if detail == nil {
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+	renderCollect(w, r, seo.Page{
+		Title:       detail.Name,
+		Description: seo.Description(detail.Description, seo.DescriptionLength),
+		Breadcrumbs: []seo.Crumb{{Name: "首页", URL: "/"}, {Name: "系列", URL: service.SeriesPathPrefix}, {Name: detail.Name}},
+	})
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/sitemap"
+	"myblog-gogogo/service"
+	"myblog-gogogo/service/settings"
+)
+
+// SitemapHandler /sitemap.xml 处理器
+// URL 数不超过单个 sitemap 上限时直接输出 urlset，否则输出指向 /sitemaps/{n}.xml 的 sitemap index
+func SitemapHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	baseURL := SiteBaseURL(r)
+	urls, err := service.NewSitemapService().URLs(baseURL)
+	if err != nil {
+		log.Printf("Failed to build sitemap: %v", err)
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+
+	pages := sitemap.Split(urls)
+	var body []byte
+	if len(pages) == 1 {
+		body, err = sitemap.URLSet(urls)
+	} else {
+		index := make([]sitemap.URL, len(pages))
+		for i, page := range pages {
+			index[i] = sitemap.URL{
+				Loc:     fmt.Sprintf("%s/sitemaps/%d.xml", baseURL, i+1),
+				LastMod: sitemap.LastMod(page),
+			}
+		}
+		body, err = sitemap.Index(index)
+	}
+	if err != nil {
+		log.Printf("Failed to encode sitemap: %v", err)
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+
+	serveGenerated(w, r, "application/xml; charset=utf-8", sitemap.LastMod(urls), body)
+}
+
+// SitemapPageHandler /sitemaps/{n}.xml 处理器，输出分页后的第 n 个 sitemap
+func SitemapPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/sitemaps/")
+	n, err := strconv.Atoi(strings.TrimSuffix(name, ".xml"))
+	if err != nil || !strings.HasSuffix(name, ".xml") || n < 1 {
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+
+	urls, err := service.NewSitemapService().URLs(SiteBaseURL(r))
+	if err != nil {
+		log.Printf("Failed to build sitemap: %v", err)
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+
+	pages := sitemap.Split(urls)
+	if n > len(pages) {
+		RenderStatusPage(w, http.StatusNotFound)
+		return
+	}
+
+	body, err := sitemap.URLSet(pages[n-1])
+	if err != nil {
+		log.Printf("Failed to encode sitemap: %v", err)
+		RenderStatusPage(w, http.StatusInternalServerError)
+		return
+	}
+
+	serveGenerated(w, r, "application/xml; charset=utf-8", sitemap.LastMod(pages[n-1]), body)
+}
+
+// RobotsHandler /robots.txt 处理器，内容可在模板设置中修改
+// 内容中没有 Sitemap 行时自动追加站点的 sitemap 地址
+func RobotsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	content := models.DefaultRobotsTxt
+	if template, err := settings.GetTemplate(); err == nil {
+		content = template.RobotsTxt
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	hasSitemap := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "sitemap:") {
+			hasSitemap = true
+			break
+		}
+	}
+	if !hasSitemap {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += "\nSitemap: " + SiteBaseURL(r) + "/sitemap.xml\n"
+	}
+
+	serveGenerated(w, r, "text/plain; charset=utf-8", time.Time{}, []byte(content))
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"fmt"
+	"os"
+)
+
+// GetDriverName 获取当前数据库驱动名称
+func GetDriverName() string {
+	return dbDriver
+}
+
+// Snapshot 将数据库的一致性快照写入 path，目前只支持 SQLite（VACUUM INTO）
+// 快照在单个读事务中完成，不阻塞其他连接的读写
+func Snapshot(path string) error {
+	if dbInstance == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+	if dbDriver != "sqlite3" {
+		return fmt.Errorf("snapshot is not supported for driver %s", dbDriver)
+	}
+	// VACUUM INTO 要求目标文件不存在
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if _, err := dbInstance.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
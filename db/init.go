@@ -19,10 +19,15 @@ import (
 	"myblog-gogogo/db/drivers"
 	"myblog-gogogo/db/models"
 	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/frontmatter"
+	"myblog-gogogo/pkg/mathml"
+	"myblog-gogogo/pkg/slug"
+	"myblog-gogogo/pkg/toc"
 )
 
 var (
 	dbInstance          *sql.DB
+	dbDriver            string
 	passageRepo         repositories.PassageRepository
 	userRepo            repositories.UserRepository
 	statsRepo           repositories.StatsRepository
@@ -57,6 +62,7 @@ func InitDB(driver, dsn string, maxOpenConns, maxIdleConns, connMaxLifetime, con
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
+	dbDriver = driver
 
 	// 创建表结构
 	if err := createTables(); err != nil {
@@ -64,7 +70,8 @@ func InitDB(driver, dsn string, maxOpenConns, maxIdleConns, connMaxLifetime, con
 	}
 	
 	// 初始化仓库
-	passageRepo = repositories.NewSQLitePassageRepository(dbInstance)
+	sqlitePassageRepo := repositories.NewSQLitePassageRepository(dbInstance)
+	passageRepo = sqlitePassageRepo
 	userRepo = repositories.NewSQLiteUserRepository(dbInstance)
 	statsRepo = repositories.NewSQLiteStatsRepository(dbInstance)
 	visitorRepo = repositories.NewSQLiteVisitorRepository(dbInstance)
@@ -79,6 +86,13 @@ func InitDB(driver, dsn string, maxOpenConns, maxIdleConns, connMaxLifetime, con
 	if err := seedData(); err != nil {
 		log.Printf("Warning: failed to seed data: %v", err)
 	}
+
+	// 为旧版本遗留的文章生成别名
+	if count, err := sqlitePassageRepo.BackfillSlugs(); err != nil {
+		log.Printf("Warning: failed to backfill passage slugs: %v", err)
+	} else if count > 0 {
+		log.Printf("Generated slugs for %d passages", count)
+	}
 	
 	log.Println("Database initialized successfully")
 	return nil
@@ -366,6 +380,146 @@ func createTables() error {
 	CREATE INDEX IF NOT EXISTS idx_music_tracks_created_at ON music_tracks(created_at);
 	`
 
+	// 创建文章修订记录表
+	passageRevisionTable := `
+	CREATE TABLE IF NOT EXISTS passage_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		passage_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		content TEXT NOT NULL,
+		summary TEXT DEFAULT '',
+		category TEXT DEFAULT '',
+		author TEXT DEFAULT '',
+		source TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (passage_id) REFERENCES passages(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_passage_revisions_passage_created ON passage_revisions(passage_id, created_at DESC);
+	`
+
+	// 创建文章旧链接表
+	passageRedirectTable := `
+	CREATE TABLE IF NOT EXISTS passage_redirects (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		old_path TEXT NOT NULL UNIQUE,
+		passage_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (passage_id) REFERENCES passages(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_passage_redirects_passage_id ON passage_redirects(passage_id);
+	`
+
+	// 创建文章双链表
+	passageLinkTable := `
+	CREATE TABLE IF NOT EXISTS passage_links (
+		source_id INTEGER NOT NULL,
+		target TEXT NOT NULL,
+		target_id INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (source_id, target),
+		FOREIGN KEY (source_id) REFERENCES passages(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_passage_links_target_id ON passage_links(target_id);
+	CREATE INDEX IF NOT EXISTS idx_passage_links_target ON passage_links(target);
+	`
+
+	// 创建系列表与系列成员表
+	seriesTable := `
+	CREATE TABLE IF NOT EXISTS series (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		slug TEXT NOT NULL UNIQUE,
+		description TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS series_passages (
+		series_id INTEGER NOT NULL,
+		passage_id INTEGER NOT NULL PRIMARY KEY,
+		position INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (series_id) REFERENCES series(id) ON DELETE CASCADE,
+		FOREIGN KEY (passage_id) REFERENCES passages(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_series_passages_series_position ON series_passages(series_id, position);
+	`
+
+	// 创建相关文章表，由后台任务整体重新计算
+	passageRelatedTable := `
+	CREATE TABLE IF NOT EXISTS passage_related (
+		passage_id INTEGER NOT NULL,
+		related_id INTEGER NOT NULL,
+		score REAL NOT NULL DEFAULT 0,
+		PRIMARY KEY (passage_id, related_id),
+		FOREIGN KEY (passage_id) REFERENCES passages(id) ON DELETE CASCADE,
+		FOREIGN KEY (related_id) REFERENCES passages(id) ON DELETE CASCADE
+	);
+	`
+
+	// 创建文章访问密码表，visibility 为 protected 的文章凭密码阅读
+	passagePasswordTable := `
+	CREATE TABLE IF NOT EXISTS passage_passwords (
+		passage_id INTEGER PRIMARY KEY,
+		password_hash TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (passage_id) REFERENCES passages(id) ON DELETE CASCADE
+	);
+	`
+
+	// 创建文章分享元数据表，覆盖 Open Graph、Twitter Card 与 JSON-LD 中的标题、描述与图片
+	passageMetaTable := `
+	CREATE TABLE IF NOT EXISTS passage_meta (
+		passage_id INTEGER PRIMARY KEY,
+		title TEXT DEFAULT '',
+		description TEXT DEFAULT '',
+		image TEXT DEFAULT '',
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (passage_id) REFERENCES passages(id) ON DELETE CASCADE
+	);
+	`
+
+	// 创建文章预览链接表，撤销的链接保留记录，阅读日志记录在 preview_link_views 表中
+	previewLinkTable := `
+	CREATE TABLE IF NOT EXISTS preview_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		passage_id INTEGER NOT NULL,
+		token TEXT NOT NULL UNIQUE,
+		created_by TEXT DEFAULT '',
+		expires_at DATETIME NOT NULL,
+		revoked INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (passage_id) REFERENCES passages(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_preview_links_passage ON preview_links(passage_id);
+	CREATE TABLE IF NOT EXISTS preview_link_views (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		link_id INTEGER NOT NULL,
+		ip TEXT DEFAULT '',
+		user_agent TEXT DEFAULT '',
+		viewed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (link_id) REFERENCES preview_links(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_preview_link_views_link ON preview_link_views(link_id);
+	`
+
+	// 创建编辑器草稿表，passage_id 为 0 表示尚未发布的新文章，因此不设置文章外键
+	draftTable := `
+	CREATE TABLE IF NOT EXISTS drafts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		passage_id INTEGER NOT NULL DEFAULT 0,
+		title TEXT DEFAULT '',
+		content TEXT DEFAULT '',
+		category TEXT DEFAULT '',
+		tags TEXT DEFAULT '',
+		summary TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, passage_id),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+	`
+
 	// 执行创建表语句
 	if _, err := dbInstance.Exec(passageTable); err != nil {
 		return fmt.Errorf("failed to create passages table: %w", err)
@@ -446,12 +600,53 @@ func createTables() error {
 		return fmt.Errorf("failed to create music tracks table: %w", err)
 	}
 
+	if _, err := dbInstance.Exec(passageRevisionTable); err != nil {
+		return fmt.Errorf("failed to create passage revisions table: %w", err)
+	}
+
+	if _, err := dbInstance.Exec(passageRedirectTable); err != nil {
+		return fmt.Errorf("failed to create passage redirects table: %w", err)
+	}
+
+	if _, err := dbInstance.Exec(passageLinkTable); err != nil {
+		return fmt.Errorf("failed to create passage links table: %w", err)
+	}
+
+	if _, err := dbInstance.Exec(seriesTable); err != nil {
+		return fmt.Errorf("failed to create series tables: %w", err)
+	}
+
+	if _, err := dbInstance.Exec(passageRelatedTable); err != nil {
+		return fmt.Errorf("failed to create passage_related table: %w", err)
+	}
+
+	if _, err := dbInstance.Exec(passagePasswordTable); err != nil {
+		return fmt.Errorf("failed to create passage_passwords table: %w", err)
+	}
+
+	if _, err := dbInstance.Exec(draftTable); err != nil {
+		return fmt.Errorf("failed to create drafts table: %w", err)
+	}
+
+	if _, err := dbInstance.Exec(previewLinkTable); err != nil {
+		return fmt.Errorf("failed to create preview_links table: %w", err)
+	}
+
+	if _, err := dbInstance.Exec(passageMetaTable); err != nil {
+		return fmt.Errorf("failed to create passage_meta table: %w", err)
+	}
+
 	// 迁移表：添加新字段（如果不存在）
 	migrations := []string{
 		"ALTER TABLE tags ADD COLUMN usage_count INTEGER DEFAULT 0",
 		"ALTER TABLE attachments ADD COLUMN visibility TEXT DEFAULT 'public'",
 		"ALTER TABLE attachments ADD COLUMN show_in_passage INTEGER DEFAULT 1",
 		"ALTER TABLE music_tracks ADD COLUMN cover_image TEXT DEFAULT ''",
+		"ALTER TABLE passages ADD COLUMN slug TEXT DEFAULT ''",
+		"ALTER TABLE passages ADD COLUMN toc TEXT DEFAULT ''",
+		"ALTER TABLE passages ADD COLUMN toc_depth INTEGER DEFAULT 0",
+		"ALTER TABLE passages ADD COLUMN renderer_version INTEGER DEFAULT 0",
+		"ALTER TABLE passages ADD COLUMN html_policy TEXT DEFAULT ''",
 	}
 
 	for _, migration := range migrations {
@@ -468,6 +663,8 @@ func createTables() error {
 		// passages 表复合索引
 		"CREATE INDEX IF NOT EXISTS idx_passages_status_created ON passages(status, created_at DESC)",
 		"CREATE INDEX IF NOT EXISTS idx_passages_category_status ON passages(category, status)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_passages_slug ON passages(slug) WHERE slug != ''",
+		"CREATE INDEX IF NOT EXISTS idx_passages_file_path ON passages(file_path)",
 
 		// article_views 表复合索引
 		"CREATE INDEX IF NOT EXISTS idx_article_views_passage_date ON article_views(passage_id, view_date)",
@@ -895,6 +1092,62 @@ func seedDefaultSettings() error {
 			Description: "全局头像路径",
 			Category:    "template",
 		},
+		{
+			Key:         "site_url",
+			Value:       "",
+			Type:        "string",
+			Description: "站点根地址（如 https://example.com），用于生成订阅源等处的绝对链接",
+			Category:    "template",
+		},
+		{
+			Key:         "feed_full_content",
+			Value:       "false",
+			Type:        "boolean",
+			Description: "订阅源是否输出全文",
+			Category:    "template",
+		},
+		{
+			Key:         "feed_item_count",
+			Value:       "20",
+			Type:        "number",
+			Description: "订阅源条目数",
+			Category:    "template",
+		},
+		{
+			Key:         "related_passage_count",
+			Value:       "5",
+			Type:        "number",
+			Description: "文章末尾显示的相关文章数，0 表示不显示",
+			Category:    "template",
+		},
+		{
+			Key:         "site_description",
+			Value:       "",
+			Type:        "string",
+			Description: "分享卡片与搜索引擎使用的站点描述，为空时使用欢迎语",
+			Category:    "template",
+		},
+		{
+			Key:         "site_image",
+			Value:       "",
+			Type:        "string",
+			Description: "默认分享图片，为空时使用全局头像",
+			Category:    "template",
+		},
+		{
+			Key:         "twitter_site",
+			Value:       "",
+			Type:        "string",
+			Description: "站点的 Twitter 账号（如 @example），用于 Twitter Card",
+			Category:    "template",
+		},
+		{
+			Key:         "robots_txt",
+			Value:       models.DefaultRobotsTxt,
+			Type:        "string",
+			Description: "robots.txt 内容",
+			Category:    "template",
+		},
 		// 默认音乐设置
 		{
 			Key:         "music_enabled",
@@ -938,6 +1191,21 @@ func seedDefaultSettings() error {
 			Description: "音乐播放器显示位置 (top-left, top-right, bottom-left, bottom-right)",
 			Category:    "template",
 		},
+		// 文章原始 HTML 过滤设置
+		{
+			Key:         "html_script_whitelist",
+			Value:       "[]",
+			Type:        "json",
+			Description: "管理员文章中允许的外部脚本地址前缀，内联脚本始终移除",
+			Category:    "security",
+		},
+		{
+			Key:         "html_iframe_whitelist",
+			Value:       "[\"https://player.bilibili.com/\",\"https://www.youtube.com/embed/\",\"https://www.youtube-nocookie.com/embed/\"]",
+			Type:        "json",
+			Description: "编辑与导入文章中允许嵌入的 iframe 地址前缀",
+			Category:    "security",
+		},
 	}
 
 	insertedCount := 0
@@ -1237,14 +1505,27 @@ func importSingleMarkdownFile(filePath string) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// 提取标题
-	title := extractTitle(string(content))
+	// 解析头部元数据，正文不包含头部
+	fm, body, err := frontmatter.Parse(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse front matter: %w", err)
+	}
+
+	// 提取标题，头部中的标题优先
+	title := extractTitle(string(body))
+	if fm != nil && fm.Title != "" {
+		title = fm.Title
+	}
 
 	// 保存原始Markdown内容
-	originalContent := string(content)
+	originalContent := string(body)
 
-	// 转换 markdown 为 HTML
-	htmlContent, err := convertMarkdownToHTML(content)
+	// 转换 markdown 为 HTML，同时提取目录
+	tocDepth := 0
+	if fm != nil && fm.TOCDepth != nil {
+		tocDepth = *fm.TOCDepth
+	}
+	htmlContent, entries, err := convertMarkdownToHTML(body, tocDepth)
 	if err != nil {
 		return fmt.Errorf("failed to convert markdown: %w", err)
 	}
@@ -1320,24 +1601,126 @@ func importSingleMarkdownFile(filePath string) error {
 		Title:           title,
 		Content:         htmlContent,
 		OriginalContent: originalContent,
+		TOC:             toc.Marshal(entries),
 		Summary:         summary,
 		Author:          "管理员",
 		Status:          "published",
 		FilePath:        relativePath,
 		Visibility:      "public", // 默认为公开
 		IsScheduled:     false,   // 默认不定时发布
+		ShowTitle:       true,
 		CreatedAt:       createdAt,
 		UpdatedAt:       time.Now(),
 	}
+	fm.ApplyTo(passage)
 
 	if err := passageRepo.Create(passage); err != nil {
 		return fmt.Errorf("failed to create passage: %w", err)
 	}
 
+	// 关联头部元数据中的标签
+	if err := linkImportedTags(passage.ID, fm.TagString()); err != nil {
+		log.Printf("Warning: failed to link tags for %s: %v", filePath, err)
+	}
+
+	// 加入头部元数据中的系列
+	if err := linkImportedSeries(passage.ID, fm); err != nil {
+		log.Printf("Warning: failed to link series for %s: %v", filePath, err)
+	}
+
 	log.Printf("Imported: %s (date: %s)", filePath, createdAt.Format("2006-01-02"))
 	return nil
 }
 
+// linkImportedTags 为导入的文章关联标签，不存在的标签会自动创建
+func linkImportedTags(passageID int, tagsStr string) error {
+	if tagsStr == "" {
+		return nil
+	}
+
+	tagRepo := GetTagRepository()
+	tags, err := tagRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	tagIDs := make(map[string]int, len(tags))
+	for _, tag := range tags {
+		tagIDs[tag.Name] = tag.ID
+	}
+
+	for _, name := range strings.Split(tagsStr, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		tagID, ok := tagIDs[name]
+		if !ok {
+			newTag := &models.Tag{Name: name, IsEnabled: true}
+			if err := tagRepo.Create(newTag); err != nil {
+				return fmt.Errorf("failed to create tag %s: %w", name, err)
+			}
+			tagID = newTag.ID
+			tagIDs[name] = tagID
+		}
+
+		if err := passageTagRepo.Create(&models.PassageTag{PassageID: passageID, TagID: tagID}); err != nil {
+			return fmt.Errorf("failed to link tag %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// linkImportedSeries 将导入的文章加入头部元数据中的系列，不存在的系列会自动创建
+func linkImportedSeries(passageID int, fm *frontmatter.FrontMatter) error {
+	if fm == nil || strings.TrimSpace(fm.Series) == "" {
+		return nil
+	}
+	name := strings.TrimSpace(fm.Series)
+
+	seriesRepo := GetSeriesRepository()
+	series, err := seriesRepo.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if series == nil {
+		base, lossy := slug.Make(name)
+		if base == "" || lossy {
+			base = slug.Normalize(name)
+		}
+		if base == "" {
+			base = "series"
+		}
+		candidate := base
+		for n := 2; ; n++ {
+			existing, err := seriesRepo.GetBySlug(candidate)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				break
+			}
+			candidate = slug.WithSuffix(base, n)
+		}
+		series = &models.Series{Name: name, Slug: candidate}
+		if err := seriesRepo.Create(series); err != nil {
+			return fmt.Errorf("failed to create series %s: %w", name, err)
+		}
+	}
+
+	position := 0
+	if fm.SeriesPart != nil {
+		position = *fm.SeriesPart
+	} else {
+		max, err := seriesRepo.MaxPosition(series.ID)
+		if err != nil {
+			return err
+		}
+		position = max + 1
+	}
+	return seriesRepo.SetMembership(&models.SeriesPassage{SeriesID: series.ID, PassageID: passageID, Position: position})
+}
+
 // sanitizeFilename 清理文件名，移除或替换不安全的字符
 func sanitizeFilename(name string) string {
 	// 定义不允许的字符
@@ -1387,23 +1770,24 @@ func extractSummary(htmlContent string) string {
 	return summary
 }
 
-// convertMarkdownToHTML 将 markdown 转换为 HTML
-func convertMarkdownToHTML(markdownContent []byte) (string, error) {
+// convertMarkdownToHTML 将 markdown 转换为 HTML，同时提取目录
+func convertMarkdownToHTML(markdownContent []byte, tocDepth int) (string, []toc.Entry, error) {
 	var buf bytes.Buffer
 	
 	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithExtensions(extension.GFM, toc.NewExtension(), mathml.NewExtension()),
 		goldmark.WithRendererOptions(
 			html.WithHardWraps(),
 			html.WithXHTML(),
 		),
 	)
 	
-	if err := md.Convert(markdownContent, &buf); err != nil {
-		return "", err
+	entries, err := toc.Convert(md, markdownContent, &buf, tocDepth)
+	if err != nil {
+		return "", nil, err
 	}
 	
-	return buf.String(), nil
+	return buf.String(), entries, nil
 }
 
 // GetDB 获取数据库实例
@@ -1479,6 +1863,51 @@ func GetArticleViewRepository() repositories.ArticleViewRepository {
 	return repositories.NewSQLiteArticleViewRepository(dbInstance)
 }
 
+// GetPassageRedirectRepository 获取文章旧链接仓库
+func GetPassageRedirectRepository() repositories.PassageRedirectRepository {
+	return repositories.NewSQLitePassageRedirectRepository(dbInstance)
+}
+
+// GetPassageLinkRepository 获取文章双链仓库
+func GetPassageLinkRepository() repositories.PassageLinkRepository {
+	return repositories.NewSQLitePassageLinkRepository(dbInstance)
+}
+
+// GetSeriesRepository 获取系列仓库
+func GetSeriesRepository() repositories.SeriesRepository {
+	return repositories.NewSQLiteSeriesRepository(dbInstance)
+}
+
+// GetPassageRelatedRepository 获取相关文章仓库
+func GetPassageRelatedRepository() repositories.PassageRelatedRepository {
+	return repositories.NewSQLitePassageRelatedRepository(dbInstance)
+}
+
+// GetPassagePasswordRepository 获取文章访问密码仓库
+func GetPassagePasswordRepository() repositories.PassagePasswordRepository {
+	return repositories.NewSQLitePassagePasswordRepository(dbInstance)
+}
+
+// GetDraftRepository 获取编辑器草稿仓库
+func GetDraftRepository() repositories.DraftRepository {
+	return repositories.NewSQLiteDraftRepository(dbInstance)
+}
+
+// GetPreviewLinkRepository 获取文章预览链接仓库
+func GetPreviewLinkRepository() repositories.PreviewLinkRepository {
+	return repositories.NewSQLitePreviewLinkRepository(dbInstance)
+}
+
+// GetPassageMetaRepository 获取文章分享元数据仓库
+func GetPassageMetaRepository() repositories.PassageMetaRepository {
+	return repositories.NewSQLitePassageMetaRepository(dbInstance)
+}
+
+// GetPassageRevisionRepository 获取文章修订记录仓库
+func GetPassageRevisionRepository() repositories.PassageRevisionRepository {
+	return repositories.NewSQLitePassageRevisionRepository(dbInstance)
+}
+
 // GetPassageTagRepository 获取文章-标签关联仓库
 func GetPassageTagRepository() repositories.PassageTagRepository {
 	return passageTagRepo
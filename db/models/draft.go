@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Draft 编辑器草稿，每个用户对每篇文章最多保留一份，发布前不会创建文章记录与 markdown 文件
+type Draft struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	PassageID int       `json:"passage_id"` // 草稿对应的已有文章，0 表示尚未发布的新文章
+	Title     string    `json:"title"`
+	Content   string    `json:"content"` // 原始Markdown内容，可以带有头部元数据
+	Category  string    `json:"category"`
+	Tags      string    `json:"tags"`
+	Summary   string    `json:"summary"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
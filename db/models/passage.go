@@ -6,6 +6,7 @@ import "time"
 type Passage struct {
 	ID              int       `json:"id"`
 	Title           string    `json:"title"`
+	Slug            string    `json:"slug"`            // URL 别名，可由头部元数据设置
 	Content         string    `json:"content"`         // HTML格式内容
 	OriginalContent string    `json:"original_content"` // 原始Markdown内容
 	Summary         string    `json:"summary"`
@@ -17,6 +18,10 @@ type Passage struct {
 	Visibility      string    `json:"visibility"` // public, private - 文章可见性
 	IsScheduled     bool      `json:"is_scheduled"` // 是否定时发布
 	PublishedAt     time.Time `json:"published_at"` // 定时发布时间
+	TOC             string    `json:"-"`            // 文章目录（JSON），渲染时生成
+	TOCDepth        int       `json:"toc_depth"`    // 目录包含的标题层数，0 表示使用默认值
+	RendererVersion int       `json:"-"`            // 生成 Content 时的渲染器版本，低于当前版本时需要重新渲染
+	HTMLPolicy      string    `json:"-"`            // 原始 HTML 的过滤策略，由保存文章的用户角色决定，空值按 editor 处理
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 }
\ No newline at end of file
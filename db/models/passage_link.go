@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// PassageLink 文章之间的双链记录，由正文中的 [[标题]] 或 [[别名|显示文本]] 生成
+type PassageLink struct {
+	SourceID  int       `json:"source_id"`
+	Target    string    `json:"target"`    // 链接目标文本（小写），用于目标文章创建或改名后重新匹配
+	TargetID  int       `json:"target_id"` // 解析到的文章 ID，0 表示目标不存在
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PassageMeta 文章的分享与搜索引擎元数据，字段为空时使用文章标题、摘要与正文第一张图片
+type PassageMeta struct {
+	PassageID   int       `json:"passage_id"`
+	Title       string    `json:"meta_title"`
+	Description string    `json:"meta_description"`
+	Image       string    `json:"meta_image"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
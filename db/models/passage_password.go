@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// PassagePassword 受密码保护文章的访问密码，只保存 Argon2id 哈希
+type PassagePassword struct {
+	PassageID    int       `json:"passage_id"`
+	PasswordHash string    `json:"-"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// PassageRedirect 文章旧链接记录，标题、日期或别名变化后旧地址 301 跳转到新地址
+type PassageRedirect struct {
+	ID        int       `json:"id"`
+	OldPath   string    `json:"old_path"` // 未转义的旧路径，如 /passage/2024/05/01/old-slug
+	PassageID int       `json:"passage_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
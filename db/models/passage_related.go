@@ -0,0 +1,8 @@
+package models
+
+// PassageRelated 文章的一篇相关文章，得分综合标签重合度、分类与正文相似度
+type PassageRelated struct {
+	PassageID int     `json:"passage_id"`
+	RelatedID int     `json:"related_id"`
+	Score     float64 `json:"score"`
+}
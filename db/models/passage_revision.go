@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// 修订来源
+const (
+	RevisionSourceInitial   = "initial"    // 启用修订记录前的原始内容
+	RevisionSourceEditor    = "editor"     // 后台编辑器保存
+	RevisionSourceUpload    = "upload"     // 上传 markdown 文件
+	RevisionSourceFileWatch = "file_watch" // 文件监控同步
+	RevisionSourceSync      = "sync"       // 手动全量同步
+	RevisionSourceRestore   = "restore"    // 从历史修订恢复
+	RevisionSourceImport    = "import"     // 从其他博客系统导入
+)
+
+// PassageRevision 文章修订记录模型
+type PassageRevision struct {
+	ID        int       `json:"id"`
+	PassageID int       `json:"passage_id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"` // 原始Markdown内容
+	Summary   string    `json:"summary"`
+	Category  string    `json:"category"`
+	Author    string    `json:"author"` // 本次修改的操作者
+	Source    string    `json:"source"` // 修改来源
+	CreatedAt time.Time `json:"created_at"`
+}
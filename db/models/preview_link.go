@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// PreviewLink 文章的秘密预览链接，持有链接的匿名读者可以阅读未发布的文章
+type PreviewLink struct {
+	ID        int       `json:"id"`
+	PassageID int       `json:"passage_id"`
+	Token     string    `json:"token"`
+	CreatedBy string    `json:"created_by"` // 生成链接的管理员
+	ExpiresAt time.Time `json:"expires_at"`
+	Revoked   bool      `json:"revoked"`
+	ViewCount int       `json:"view_count"` // 通过链接阅读的次数，只在列表中统计
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active 链接未撤销且未过期
+func (l *PreviewLink) Active(now time.Time) bool {
+	return !l.Revoked && now.Before(l.ExpiresAt)
+}
+
+// PreviewLinkView 通过预览链接阅读文章的记录
+type PreviewLinkView struct {
+	ID        int       `json:"id"`
+	LinkID    int       `json:"link_id"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	ViewedAt  time.Time `json:"viewed_at"`
+}
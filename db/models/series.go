@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Series 系列，多篇文章按顺序组成的连载或教程
+type Series struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SeriesPassage 系列成员，一篇文章最多属于一个系列
+type SeriesPassage struct {
+	SeriesID  int       `json:"series_id"`
+	PassageID int       `json:"passage_id"`
+	Position  int       `json:"position"` // 在系列中的顺序，从小到大排列
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -12,4 +12,7 @@ type Setting struct {
 	Category    string    `json:"category"` // appearance, system, content
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// DefaultRobotsTxt 默认的 robots.txt 内容，Sitemap 行会在输出时自动补充
+const DefaultRobotsTxt = "User-agent: *\nDisallow: /admin\nDisallow: /api/\nDisallow: /markdown-editor\n"
@@ -14,6 +14,7 @@ type AttachmentRepository interface {
 	GetAll(limit, offset int) ([]*models.Attachment, int, error)
 	GetByPassageID(passageID int, limit, offset int) ([]*models.Attachment, int, error)
 	UpdateVisibility(id int, visibility string, showInPassage bool) error
+	UpdatePassageID(id, passageID int) error
 	Delete(id int) error
 	Count() (int, error)
 	CountByPassageID(passageID int) (int, error)
@@ -158,6 +159,12 @@ func (r *SQLiteAttachmentRepository) UpdateVisibility(id int, visibility string,
 	return err
 }
 
+func (r *SQLiteAttachmentRepository) UpdatePassageID(id, passageID int) error {
+	query := `UPDATE attachments SET passage_id = ? WHERE id = ?`
+	_, err := r.db.Exec(query, passageID, id)
+	return err
+}
+
 func (r *SQLiteAttachmentRepository) Count() (int, error) {
 	var count int
 	err := r.db.QueryRow("SELECT COUNT(*) FROM attachments").Scan(&count)
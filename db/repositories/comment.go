@@ -31,10 +31,12 @@ func (r *SQLiteCommentRepository) Create(comment *models.Comment) error {
 	query := `INSERT INTO comments (username, content, passage_id, created_at)
 	          VALUES (?, ?, ?, ?)`
 
-	now := time.Now()
-	comment.CreatedAt = now
+	// 导入的评论保留原来的发表时间
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
 
-	result, err := r.db.Exec(query, comment.Username, comment.Content, comment.PassageID, now)
+	result, err := r.db.Exec(query, comment.Username, comment.Content, comment.PassageID, comment.CreatedAt)
 	if err != nil {
 		return err
 	}
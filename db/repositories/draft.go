@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// DraftRepository 编辑器草稿仓库接口
+type DraftRepository interface {
+	Save(draft *models.Draft) error
+	Get(userID, passageID int) (*models.Draft, error)
+	GetByUserID(userID int) ([]models.Draft, error)
+	Delete(userID, passageID int) error
+	DeleteByPassageID(passageID int) error
+}
+
+// SQLiteDraftRepository SQLite编辑器草稿仓库实现
+type SQLiteDraftRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteDraftRepository 创建编辑器草稿仓库
+func NewSQLiteDraftRepository(db *sql.DB) *SQLiteDraftRepository {
+	return &SQLiteDraftRepository{db: db}
+}
+
+// Save 保存草稿，同一用户同一文章已有草稿时覆盖内容
+func (r *SQLiteDraftRepository) Save(draft *models.Draft) error {
+	now := time.Now()
+	if draft.CreatedAt.IsZero() {
+		draft.CreatedAt = now
+	}
+	draft.UpdatedAt = now
+
+	query := `INSERT INTO drafts (user_id, passage_id, title, content, category, tags, summary, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	          ON CONFLICT(user_id, passage_id) DO UPDATE SET title = excluded.title, content = excluded.content,
+	          category = excluded.category, tags = excluded.tags, summary = excluded.summary, updated_at = excluded.updated_at`
+
+	if _, err := r.db.Exec(query, draft.UserID, draft.PassageID, draft.Title, draft.Content, draft.Category,
+		draft.Tags, draft.Summary, draft.CreatedAt, draft.UpdatedAt); err != nil {
+		return err
+	}
+
+	// 覆盖已有草稿时 LastInsertId 不可靠，按键重新读取ID与创建时间
+	return r.db.QueryRow(`SELECT id, created_at FROM drafts WHERE user_id = ? AND passage_id = ?`,
+		draft.UserID, draft.PassageID).Scan(&draft.ID, &draft.CreatedAt)
+}
+
+func (r *SQLiteDraftRepository) Get(userID, passageID int) (*models.Draft, error) {
+	query := `SELECT id, user_id, passage_id, title, content, category, tags, summary, created_at, updated_at
+	          FROM drafts WHERE user_id = ? AND passage_id = ?`
+
+	draft := &models.Draft{}
+	err := r.db.QueryRow(query, userID, passageID).Scan(
+		&draft.ID, &draft.UserID, &draft.PassageID, &draft.Title, &draft.Content,
+		&draft.Category, &draft.Tags, &draft.Summary, &draft.CreatedAt, &draft.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// GetByUserID 按最近修改时间获取用户的所有草稿
+func (r *SQLiteDraftRepository) GetByUserID(userID int) ([]models.Draft, error) {
+	query := `SELECT id, user_id, passage_id, title, content, category, tags, summary, created_at, updated_at
+	          FROM drafts WHERE user_id = ? ORDER BY updated_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drafts := []models.Draft{}
+	for rows.Next() {
+		var draft models.Draft
+		if err := rows.Scan(
+			&draft.ID, &draft.UserID, &draft.PassageID, &draft.Title, &draft.Content,
+			&draft.Category, &draft.Tags, &draft.Summary, &draft.CreatedAt, &draft.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, draft)
+	}
+
+	return drafts, rows.Err()
+}
+
+func (r *SQLiteDraftRepository) Delete(userID, passageID int) error {
+	_, err := r.db.Exec(`DELETE FROM drafts WHERE user_id = ? AND passage_id = ?`, userID, passageID)
+	return err
+}
+
+// DeleteByPassageID 删除所有用户对某篇文章的草稿，文章被永久删除时调用
+func (r *SQLiteDraftRepository) DeleteByPassageID(passageID int) error {
+	_, err := r.db.Exec(`DELETE FROM drafts WHERE passage_id = ?`, passageID)
+	return err
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import "sync"
+
+// PassageChangeFunc 文章内容或标签变化时的回调，passageID 为 0 表示可能影响所有文章（如标签改名）
+type PassageChangeFunc func(passageID int)
+
+var (
+	passageChangeMu    sync.RWMutex
+	passageChangeFuncs []PassageChangeFunc
+)
+
+// OnPassageChange 注册文章变化回调，供搜索索引等缓存在数据变化后失效
+// 回调在写操作完成后同步调用，应当尽快返回
+func OnPassageChange(fn PassageChangeFunc) {
+	passageChangeMu.Lock()
+	defer passageChangeMu.Unlock()
+	passageChangeFuncs = append(passageChangeFuncs, fn)
+}
+
+// notifyPassageChange 通知所有回调文章已变化
+func notifyPassageChange(passageIDs ...int) {
+	passageChangeMu.RLock()
+	defer passageChangeMu.RUnlock()
+	for _, fn := range passageChangeFuncs {
+		for _, id := range passageIDs {
+			fn(id)
+		}
+	}
+}
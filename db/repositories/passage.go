@@ -3,32 +3,43 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/slug"
 )
 
 // PassageRepository 文章仓库接口
 type PassageRepository interface {
 	Create(passage *models.Passage) error
 	GetByID(id int) (*models.Passage, error)
+	GetBySlug(slug string) (*models.Passage, error)
+	GetByFilePath(filePath string) (*models.Passage, error)
+	GetByTitle(title string) (*models.Passage, error)
 	GetAll(limit, offset int) ([]models.Passage, error)
 	Update(passage *models.Passage) error
+	UpdateRendered(passage *models.Passage) error
+	GetAllIDs() ([]int, error)
 	Delete(id int) error
 	GetByStatus(status string, limit, offset int) ([]models.Passage, error)
 	GetByCategory(category string, limit, offset int) ([]models.Passage, error)
 	GetAllCategories() ([]string, error)
 	Count() (int, error)
 	CountByStatus(status string) (int, error)
+	GetPublic(category string, tagID int, limit, offset int) ([]models.Passage, error)
+	GetPendingScheduled() ([]models.Passage, error)
+	PublishScheduled(ids []int) ([]int, error)
 }
 
 // SQLitePassageRepository SQLite文章仓库实现
 type SQLitePassageRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	redirects *SQLitePassageRedirectRepository
 }
 
 func NewSQLitePassageRepository(db *sql.DB) *SQLitePassageRepository {
-	return &SQLitePassageRepository{db: db}
+	return &SQLitePassageRepository{db: db, redirects: NewSQLitePassageRedirectRepository(db)}
 }
 
 // getContext 创建带超时的 context
@@ -36,9 +47,51 @@ func (r *SQLitePassageRepository) getContext() (context.Context, context.CancelF
 	return context.WithTimeout(context.Background(), 5*time.Second)
 }
 
+// passageColumns 文章查询字段列表，顺序需与 scanPassage 保持一致
+const passageColumns = `id, title, slug, content, original_content, summary, author, category, status, file_path, visibility, is_scheduled, published_at, show_title, toc, toc_depth, renderer_version, html_policy, created_at, updated_at`
+
+// rowScanner 兼容 *sql.Row 与 *sql.Rows 的扫描接口
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPassage 按 passageColumns 的顺序扫描一行文章数据
+func scanPassage(row rowScanner, passage *models.Passage) error {
+	var isScheduled int
+	var showTitle int
+	err := row.Scan(
+		&passage.ID, &passage.Title, &passage.Slug, &passage.Content, &passage.OriginalContent, &passage.Summary,
+		&passage.Author, &passage.Category, &passage.Status, &passage.FilePath,
+		&passage.Visibility, &isScheduled, &passage.PublishedAt, &showTitle,
+		&passage.TOC, &passage.TOCDepth, &passage.RendererVersion, &passage.HTMLPolicy, &passage.CreatedAt, &passage.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// 转换 is_scheduled 为布尔值
+	passage.IsScheduled = isScheduled == 1
+	// 转换 show_title 为布尔值
+	passage.ShowTitle = showTitle == 1
+	return nil
+}
+
+// scanPassages 扫描多行文章数据
+func scanPassages(rows *sql.Rows) ([]models.Passage, error) {
+	var passages []models.Passage
+	for rows.Next() {
+		var passage models.Passage
+		if err := scanPassage(rows, &passage); err != nil {
+			return nil, err
+		}
+		passages = append(passages, passage)
+	}
+	return passages, rows.Err()
+}
+
 func (r *SQLitePassageRepository) Create(passage *models.Passage) error {
-	query := `INSERT INTO passages (title, content, original_content, summary, author, category, status, file_path, visibility, is_scheduled, published_at, show_title, created_at, updated_at)
-	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO passages (title, slug, content, original_content, summary, author, category, status, file_path, visibility, is_scheduled, published_at, show_title, toc, toc_depth, renderer_version, html_policy, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	now := time.Now()
 
@@ -54,21 +107,31 @@ func (r *SQLitePassageRepository) Create(passage *models.Passage) error {
 		passage.Visibility = "public"
 	}
 
+	// 手动设置的别名在插入前去重，未设置时插入后根据 ID 生成
+	passage.Slug = slug.Normalize(passage.Slug)
+	if passage.Slug != "" {
+		unique, err := r.uniqueSlug(passage.Slug, 0)
+		if err != nil {
+			return err
+		}
+		passage.Slug = unique
+	}
+
 	// 处理 is_scheduled 布尔值
 	isScheduled := 0
 	if passage.IsScheduled {
 		isScheduled = 1
 	}
 
-	// 处理 show_title 布尔值
-	showTitle := 1
+	// 处理 show_title 布尔值，请求未提供 show_title 时由调用方按显示标题处理
+	showTitle := 0
 	if passage.ShowTitle {
 		showTitle = 1
 	}
 
-	result, err := r.db.Exec(query, passage.Title, passage.Content, passage.OriginalContent, passage.Summary,
+	result, err := r.db.Exec(query, passage.Title, passage.Slug, passage.Content, passage.OriginalContent, passage.Summary,
 		passage.Author, passage.Category, passage.Status, passage.FilePath, passage.Visibility,
-		isScheduled, passage.PublishedAt, showTitle, passage.CreatedAt, now)
+		isScheduled, passage.PublishedAt, showTitle, passage.TOC, passage.TOCDepth, passage.RendererVersion, passage.HTMLPolicy, passage.CreatedAt, now)
 	if err != nil {
 		return err
 	}
@@ -79,6 +142,14 @@ func (r *SQLitePassageRepository) Create(passage *models.Passage) error {
 	}
 
 	passage.ID = int(id)
+
+	if passage.Slug == "" {
+		if err := r.generateSlug(passage); err != nil {
+			return err
+		}
+	}
+
+	notifyPassageChange(passage.ID)
 	return nil
 }
 
@@ -86,19 +157,10 @@ func (r *SQLitePassageRepository) GetByID(id int) (*models.Passage, error) {
 	ctx, cancel := r.getContext()
 	defer cancel()
 
-	query := `SELECT id, title, content, original_content, summary, author, category, status, file_path, visibility, is_scheduled, published_at, show_title, created_at, updated_at
-	          FROM passages WHERE id = ?`
+	query := `SELECT ` + passageColumns + ` FROM passages WHERE id = ?`
 
 	passage := &models.Passage{}
-	var isScheduled int
-	var showTitle int
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&passage.ID, &passage.Title, &passage.Content, &passage.OriginalContent, &passage.Summary,
-		&passage.Author, &passage.Category, &passage.Status, &passage.FilePath,
-		&passage.Visibility, &isScheduled, &passage.PublishedAt, &showTitle,
-		&passage.CreatedAt, &passage.UpdatedAt,
-	)
-
+	err := scanPassage(r.db.QueryRowContext(ctx, query, id), passage)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -106,10 +168,37 @@ func (r *SQLitePassageRepository) GetByID(id int) (*models.Passage, error) {
 		return nil, err
 	}
 
-	// 转换 is_scheduled 为布尔值
-	passage.IsScheduled = isScheduled == 1
-	// 转换 show_title 为布尔值
-	passage.ShowTitle = showTitle == 1
+	return passage, nil
+}
+
+// GetBySlug 按别名获取文章，不存在时返回 nil
+func (r *SQLitePassageRepository) GetBySlug(s string) (*models.Passage, error) {
+	return r.getOne(`SELECT `+passageColumns+` FROM passages WHERE slug = ? AND slug != ''`, s)
+}
+
+// GetByFilePath 按文件路径（不含 markdown/ 前缀与 .md 后缀）获取文章，不存在时返回 nil
+func (r *SQLitePassageRepository) GetByFilePath(filePath string) (*models.Passage, error) {
+	return r.getOne(`SELECT `+passageColumns+` FROM passages WHERE file_path = ? ORDER BY id LIMIT 1`, filePath)
+}
+
+// GetByTitle 按标题获取文章（不区分 ASCII 大小写），同名时返回最早创建的一篇，不存在时返回 nil
+func (r *SQLitePassageRepository) GetByTitle(title string) (*models.Passage, error) {
+	return r.getOne(`SELECT `+passageColumns+` FROM passages WHERE title = ? COLLATE NOCASE ORDER BY created_at, id LIMIT 1`, title)
+}
+
+// getOne 查询单篇文章，不存在时返回 nil
+func (r *SQLitePassageRepository) getOne(query string, args ...interface{}) (*models.Passage, error) {
+	ctx, cancel := r.getContext()
+	defer cancel()
+
+	passage := &models.Passage{}
+	err := scanPassage(r.db.QueryRowContext(ctx, query, args...), passage)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	return passage, nil
 }
@@ -118,8 +207,7 @@ func (r *SQLitePassageRepository) GetAll(limit, offset int) ([]models.Passage, e
 	ctx, cancel := r.getContext()
 	defer cancel()
 
-	query := `SELECT id, title, content, original_content, summary, author, category, status, file_path, visibility, is_scheduled, published_at, show_title, created_at, updated_at
-	          FROM passages ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	query := `SELECT ` + passageColumns + ` FROM passages ORDER BY created_at DESC LIMIT ? OFFSET ?`
 
 	rows, err := r.db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
@@ -127,36 +215,38 @@ func (r *SQLitePassageRepository) GetAll(limit, offset int) ([]models.Passage, e
 	}
 	defer rows.Close()
 
-	var passages []models.Passage
-	for rows.Next() {
-		var passage models.Passage
-		var isScheduled int
-		var showTitle int
-		err := rows.Scan(
-			&passage.ID, &passage.Title, &passage.Content, &passage.OriginalContent, &passage.Summary,
-			&passage.Author, &passage.Category, &passage.Status, &passage.FilePath,
-			&passage.Visibility, &isScheduled, &passage.PublishedAt, &showTitle,
-			&passage.CreatedAt, &passage.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		// 转换 is_scheduled 为布尔值
-		passage.IsScheduled = isScheduled == 1
-		// 转换 show_title 为布尔值
-		passage.ShowTitle = showTitle == 1
-		passages = append(passages, passage)
-	}
-
-	return passages, nil
+	return scanPassages(rows)
 }
 
 func (r *SQLitePassageRepository) Update(passage *models.Passage) error {
 	ctx, cancel := r.getContext()
 	defer cancel()
 
-	query := `UPDATE passages SET title = ?, content = ?, original_content = ?, summary = ?, author = ?, category = ?,
-	          status = ?, file_path = ?, visibility = ?, is_scheduled = ?, published_at = ?, show_title = ?, updated_at = ? WHERE id = ?`
+	query := `UPDATE passages SET title = ?, slug = ?, content = ?, original_content = ?, summary = ?, author = ?, category = ?,
+	          status = ?, file_path = ?, visibility = ?, is_scheduled = ?, published_at = ?, show_title = ?, toc = ?, toc_depth = ?,
+	          renderer_version = ?, html_policy = ?, created_at = ?, updated_at = ? WHERE id = ?`
+
+	existing, err := r.GetByID(passage.ID)
+	if err != nil {
+		return err
+	}
+
+	// 未设置别名时沿用原有别名，保证链接稳定
+	passage.Slug = slug.Normalize(passage.Slug)
+	if passage.Slug == "" && existing != nil {
+		passage.Slug = existing.Slug
+	}
+	if passage.Slug == "" {
+		passage.Slug = slug.ForPassage(passage.Title, passage.ID)
+	}
+	if passage.Slug, err = r.uniqueSlug(passage.Slug, passage.ID); err != nil {
+		return err
+	}
+
+	// 未设置创建时间时保留原值，头部元数据中的 date 可修改文章日期
+	if passage.CreatedAt.IsZero() && existing != nil {
+		passage.CreatedAt = existing.CreatedAt
+	}
 
 	passage.UpdatedAt = time.Now()
 
@@ -172,57 +262,91 @@ func (r *SQLitePassageRepository) Update(passage *models.Passage) error {
 		showTitle = 1
 	}
 
-	_, err := r.db.ExecContext(ctx, query, passage.Title, passage.Content, passage.OriginalContent, passage.Summary,
+	_, err = r.db.ExecContext(ctx, query, passage.Title, passage.Slug, passage.Content, passage.OriginalContent, passage.Summary,
 		passage.Author, passage.Category, passage.Status, passage.FilePath, passage.Visibility,
-		isScheduled, passage.PublishedAt, showTitle, passage.UpdatedAt, passage.ID)
+		isScheduled, passage.PublishedAt, showTitle, passage.TOC, passage.TOCDepth, passage.RendererVersion, passage.HTMLPolicy, passage.CreatedAt, passage.UpdatedAt, passage.ID)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		if err := r.recordRedirects(existing, passage); err != nil {
+			return fmt.Errorf("记录旧链接失败: %w", err)
+		}
+	}
 
-	return err
+	notifyPassageChange(passage.ID)
+	return nil
 }
 
-func (r *SQLitePassageRepository) Delete(id int) error {
+// UpdateRendered 只保存重新渲染的 HTML、目录与渲染器版本，不改变更新时间与别名
+func (r *SQLitePassageRepository) UpdateRendered(passage *models.Passage) error {
 	ctx, cancel := r.getContext()
 	defer cancel()
 
-	query := `DELETE FROM passages WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	query := `UPDATE passages SET content = ?, toc = ?, renderer_version = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, passage.Content, passage.TOC, passage.RendererVersion, passage.ID); err != nil {
+		return err
+	}
+
+	notifyPassageChange(passage.ID)
+	return nil
 }
 
-func (r *SQLitePassageRepository) GetByStatus(status string, limit, offset int) ([]models.Passage, error) {
+// GetAllIDs 获取全部文章的 ID
+func (r *SQLitePassageRepository) GetAllIDs() ([]int, error) {
 	ctx, cancel := r.getContext()
 	defer cancel()
 
-	query := `SELECT id, title, content, original_content, summary, author, category, status, file_path, visibility, is_scheduled, published_at, show_title, created_at, updated_at
-	          FROM passages WHERE status = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`
-
-	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM passages ORDER BY id`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var passages []models.Passage
+	var ids []int
 	for rows.Next() {
-		var passage models.Passage
-		var isScheduled int
-		var showTitle int
-		err := rows.Scan(
-			&passage.ID, &passage.Title, &passage.Content, &passage.OriginalContent, &passage.Summary,
-			&passage.Author, &passage.Category, &passage.Status, &passage.FilePath,
-			&passage.Visibility, &isScheduled, &passage.PublishedAt, &showTitle,
-			&passage.CreatedAt, &passage.UpdatedAt,
-		)
-		if err != nil {
+		var id int
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		// 转换 is_scheduled 为布尔值
-		passage.IsScheduled = isScheduled == 1
-		// 转换 show_title 为布尔值
-		passage.ShowTitle = showTitle == 1
-		passages = append(passages, passage)
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *SQLitePassageRepository) Delete(id int) error {
+	ctx, cancel := r.getContext()
+	defer cancel()
+
+	query := `DELETE FROM passages WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+	if err := r.redirects.DeleteByPassageID(id); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM passage_links WHERE source_id = ?`, id); err != nil {
+		return err
 	}
 
-	return passages, nil
+	notifyPassageChange(id)
+	return nil
+}
+
+func (r *SQLitePassageRepository) GetByStatus(status string, limit, offset int) ([]models.Passage, error) {
+	ctx, cancel := r.getContext()
+	defer cancel()
+
+	query := `SELECT ` + passageColumns + ` FROM passages WHERE status = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPassages(rows)
 }
 
 func (r *SQLitePassageRepository) Count() (int, error) {
@@ -247,8 +371,7 @@ func (r *SQLitePassageRepository) GetByCategory(category string, limit, offset i
 	ctx, cancel := r.getContext()
 	defer cancel()
 
-	query := `SELECT id, title, content, original_content, summary, author, category, status, file_path, visibility, is_scheduled, published_at, show_title, created_at, updated_at
-	          FROM passages WHERE category = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	query := `SELECT ` + passageColumns + ` FROM passages WHERE category = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`
 
 	rows, err := r.db.QueryContext(ctx, query, category, limit, offset)
 	if err != nil {
@@ -256,28 +379,7 @@ func (r *SQLitePassageRepository) GetByCategory(category string, limit, offset i
 	}
 	defer rows.Close()
 
-	var passages []models.Passage
-	for rows.Next() {
-		var passage models.Passage
-		var isScheduled int
-		var showTitle int
-		err := rows.Scan(
-			&passage.ID, &passage.Title, &passage.Content, &passage.OriginalContent, &passage.Summary,
-			&passage.Author, &passage.Category, &passage.Status, &passage.FilePath,
-			&passage.Visibility, &isScheduled, &passage.PublishedAt, &showTitle,
-			&passage.CreatedAt, &passage.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		// 转换 is_scheduled 为布尔值
-		passage.IsScheduled = isScheduled == 1
-		// 转换 show_title 为布尔值
-		passage.ShowTitle = showTitle == 1
-		passages = append(passages, passage)
-	}
-
-	return passages, nil
+	return scanPassages(rows)
 }
 
 func (r *SQLitePassageRepository) GetAllCategories() ([]string, error) {
@@ -303,4 +405,192 @@ func (r *SQLitePassageRepository) GetAllCategories() ([]string, error) {
 	}
 
 	return categories, nil
-}
\ No newline at end of file
+}
+
+// GetPublic 获取已发布的公开文章（不含尚未到发布时间的定时文章），按创建时间倒序
+// category 为空或 tagID 为 0 时不按该条件过滤
+func (r *SQLitePassageRepository) GetPublic(category string, tagID int, limit, offset int) ([]models.Passage, error) {
+	ctx, cancel := r.getContext()
+	defer cancel()
+
+	query := `SELECT ` + passageColumns + ` FROM passages WHERE status = 'published' AND visibility = 'public' AND is_scheduled = 0`
+	var args []interface{}
+	if category != "" {
+		query += ` AND category = ?`
+		args = append(args, category)
+	}
+	if tagID > 0 {
+		query += ` AND id IN (SELECT passage_id FROM passage_tags WHERE tag_id = ?)`
+		args = append(args, tagID)
+	}
+	query += ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPassages(rows)
+}
+
+// GetPendingScheduled 获取所有尚未发布的定时文章（不含回收站中的文章），按发布时间升序
+func (r *SQLitePassageRepository) GetPendingScheduled() ([]models.Passage, error) {
+	ctx, cancel := r.getContext()
+	defer cancel()
+
+	query := `SELECT ` + passageColumns + ` FROM passages
+	          WHERE is_scheduled = 1 AND status NOT IN ('published', 'deleted') ORDER BY published_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPassages(rows)
+}
+
+// PublishScheduled 在同一事务中将定时文章改为已发布并清除定时标记
+// 已被其他途径发布或删除的文章会被跳过，返回实际发布的文章ID
+func (r *SQLitePassageRepository) PublishScheduled(ids []int) (published []int, err error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := r.getContext()
+	defer cancel()
+
+	// 开始事务
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `UPDATE passages SET status = 'published', is_scheduled = 0, updated_at = ?
+	          WHERE id = ? AND is_scheduled = 1 AND status NOT IN ('published', 'deleted')`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("准备语句失败: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, id := range ids {
+		result, execErr := stmt.ExecContext(ctx, now, id)
+		if execErr != nil {
+			err = fmt.Errorf("发布文章失败 (id=%d): %w", id, execErr)
+			return nil, err
+		}
+		affected, _ := result.RowsAffected()
+		if affected > 0 {
+			published = append(published, id)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	notifyPassageChange(published...)
+	return published, nil
+}
+
+// generateSlug 为没有别名的文章生成别名并保存
+func (r *SQLitePassageRepository) generateSlug(passage *models.Passage) error {
+	unique, err := r.uniqueSlug(slug.ForPassage(passage.Title, passage.ID), passage.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(`UPDATE passages SET slug = ? WHERE id = ?`, unique, passage.ID); err != nil {
+		return err
+	}
+	passage.Slug = unique
+	return nil
+}
+
+// uniqueSlug 别名已被其他文章占用时追加序号
+func (r *SQLitePassageRepository) uniqueSlug(base string, excludeID int) (string, error) {
+	candidate := base
+	for n := 2; ; n++ {
+		var count int
+		err := r.db.QueryRow(`SELECT COUNT(*) FROM passages WHERE slug = ? AND id != ?`, candidate, excludeID).Scan(&count)
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = slug.WithSuffix(base, n)
+	}
+}
+
+// BackfillSlugs 为没有别名的文章生成别名，返回处理的文章数
+func (r *SQLitePassageRepository) BackfillSlugs() (int, error) {
+	rows, err := r.db.Query(`SELECT id, title FROM passages WHERE slug IS NULL OR slug = '' ORDER BY id`)
+	if err != nil {
+		return 0, err
+	}
+	var pending []models.Passage
+	for rows.Next() {
+		var passage models.Passage
+		if err := rows.Scan(&passage.ID, &passage.Title); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, passage)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for i := range pending {
+		if err := r.generateSlug(&pending[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(pending), nil
+}
+
+// permalinks 返回文章当前可访问的路径（未转义）：基于别名的永久链接与基于文件路径的旧式链接
+func permalinks(passage *models.Passage) []string {
+	var paths []string
+	if passage.Slug != "" {
+		paths = append(paths, slug.Path(passage.CreatedAt, passage.Slug))
+	}
+	if passage.FilePath != "" {
+		paths = append(paths, slug.PathPrefix+passage.FilePath)
+	}
+	return paths
+}
+
+// recordRedirects 标题、日期或别名变化导致路径改变时，记录旧路径以便 301 跳转
+func (r *SQLitePassageRepository) recordRedirects(before, after *models.Passage) error {
+	current := permalinks(after)
+	if err := r.redirects.DeleteByPath(current...); err != nil {
+		return err
+	}
+
+	for _, old := range permalinks(before) {
+		changed := true
+		for _, path := range current {
+			if path == old {
+				changed = false
+				break
+			}
+		}
+		if changed {
+			if err := r.redirects.Save(old, after.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// PassageLinkRepository 文章双链仓库接口
+type PassageLinkRepository interface {
+	ReplaceForSource(sourceID int, links []models.PassageLink) error
+	GetBySource(sourceID int) ([]models.PassageLink, error)
+	GetSourceIDs(targetID int, targets []string) ([]int, error)
+	GetBacklinks(targetID int) ([]models.Passage, error)
+	GetOrphans() ([]models.Passage, error)
+}
+
+// SQLitePassageLinkRepository SQLite文章双链仓库实现
+type SQLitePassageLinkRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePassageLinkRepository 创建文章双链仓库
+func NewSQLitePassageLinkRepository(db *sql.DB) *SQLitePassageLinkRepository {
+	return &SQLitePassageLinkRepository{db: db}
+}
+
+// ReplaceForSource 用新的链接列表替换文章原有的全部链接
+func (r *SQLitePassageLinkRepository) ReplaceForSource(sourceID int, links []models.PassageLink) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM passage_links WHERE source_id = ?`, sourceID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, link := range links {
+		_, err := tx.Exec(`INSERT OR REPLACE INTO passage_links (source_id, target, target_id, created_at) VALUES (?, ?, ?, ?)`,
+			sourceID, link.Target, link.TargetID, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLitePassageLinkRepository) GetBySource(sourceID int) ([]models.PassageLink, error) {
+	rows, err := r.db.Query(`SELECT source_id, target, target_id, created_at FROM passage_links WHERE source_id = ? ORDER BY target`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.PassageLink
+	for rows.Next() {
+		var link models.PassageLink
+		if err := rows.Scan(&link.SourceID, &link.Target, &link.TargetID, &link.CreatedAt); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// GetSourceIDs 获取链接到指定文章、或链接目标文本属于 targets 的文章 ID
+// 目标文章创建、改名或删除后，这些文章需要重新渲染
+func (r *SQLitePassageLinkRepository) GetSourceIDs(targetID int, targets []string) ([]int, error) {
+	query := `SELECT DISTINCT source_id FROM passage_links WHERE target_id = ?`
+	args := []interface{}{targetID}
+	if len(targets) > 0 {
+		query += ` OR target IN (?` + strings.Repeat(", ?", len(targets)-1) + `)`
+		for _, target := range targets {
+			args = append(args, target)
+		}
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetBacklinks 获取链接到指定文章的已发布公开文章，不包含文章自身
+func (r *SQLitePassageLinkRepository) GetBacklinks(targetID int) ([]models.Passage, error) {
+	query := `SELECT ` + passageColumns + ` FROM passages
+	          WHERE id IN (SELECT source_id FROM passage_links WHERE target_id = ?) AND id != ?
+	          AND status = 'published' AND (visibility = 'public' OR visibility = '' OR visibility IS NULL)
+	          ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, targetID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPassages(rows)
+}
+
+// GetOrphans 获取没有被其他文章链接的已发布文章
+func (r *SQLitePassageLinkRepository) GetOrphans() ([]models.Passage, error) {
+	query := `SELECT ` + passageColumns + ` FROM passages
+	          WHERE status = 'published'
+	          AND id NOT IN (SELECT target_id FROM passage_links WHERE target_id != 0 AND target_id != source_id)
+	          ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPassages(rows)
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// PassageMetaRepository 文章分享元数据仓库接口
+type PassageMetaRepository interface {
+	Get(passageID int) (*models.PassageMeta, error)
+	Set(meta *models.PassageMeta) error
+	Delete(passageID int) error
+}
+
+// SQLitePassageMetaRepository SQLite文章分享元数据仓库实现
+type SQLitePassageMetaRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePassageMetaRepository 创建文章分享元数据仓库
+func NewSQLitePassageMetaRepository(db *sql.DB) *SQLitePassageMetaRepository {
+	return &SQLitePassageMetaRepository{db: db}
+}
+
+func (r *SQLitePassageMetaRepository) Get(passageID int) (*models.PassageMeta, error) {
+	query := `SELECT passage_id, title, description, image, updated_at FROM passage_meta WHERE passage_id = ?`
+
+	meta := &models.PassageMeta{}
+	err := r.db.QueryRow(query, passageID).Scan(&meta.PassageID, &meta.Title, &meta.Description, &meta.Image, &meta.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// Set 保存文章的分享元数据，已有记录时覆盖
+func (r *SQLitePassageMetaRepository) Set(meta *models.PassageMeta) error {
+	meta.UpdatedAt = time.Now()
+	query := `INSERT INTO passage_meta (passage_id, title, description, image, updated_at) VALUES (?, ?, ?, ?, ?)
+	          ON CONFLICT(passage_id) DO UPDATE SET title = excluded.title, description = excluded.description,
+	          image = excluded.image, updated_at = excluded.updated_at`
+	_, err := r.db.Exec(query, meta.PassageID, meta.Title, meta.Description, meta.Image, meta.UpdatedAt)
+	return err
+}
+
+func (r *SQLitePassageMetaRepository) Delete(passageID int) error {
+	_, err := r.db.Exec(`DELETE FROM passage_meta WHERE passage_id = ?`, passageID)
+	return err
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// PassagePasswordRepository 文章访问密码仓库接口
+type PassagePasswordRepository interface {
+	Get(passageID int) (*models.PassagePassword, error)
+	Set(passageID int, passwordHash string) error
+	Delete(passageID int) error
+}
+
+// SQLitePassagePasswordRepository SQLite文章访问密码仓库实现
+type SQLitePassagePasswordRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePassagePasswordRepository 创建文章访问密码仓库
+func NewSQLitePassagePasswordRepository(db *sql.DB) *SQLitePassagePasswordRepository {
+	return &SQLitePassagePasswordRepository{db: db}
+}
+
+func (r *SQLitePassagePasswordRepository) Get(passageID int) (*models.PassagePassword, error) {
+	query := `SELECT passage_id, password_hash, updated_at FROM passage_passwords WHERE passage_id = ?`
+
+	password := &models.PassagePassword{}
+	err := r.db.QueryRow(query, passageID).Scan(&password.PassageID, &password.PasswordHash, &password.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return password, nil
+}
+
+// Set 设置文章的访问密码哈希，已有密码时覆盖
+func (r *SQLitePassagePasswordRepository) Set(passageID int, passwordHash string) error {
+	query := `INSERT INTO passage_passwords (passage_id, password_hash, updated_at) VALUES (?, ?, ?)
+	          ON CONFLICT(passage_id) DO UPDATE SET password_hash = excluded.password_hash, updated_at = excluded.updated_at`
+	_, err := r.db.Exec(query, passageID, passwordHash, time.Now())
+	return err
+}
+
+func (r *SQLitePassagePasswordRepository) Delete(passageID int) error {
+	_, err := r.db.Exec(`DELETE FROM passage_passwords WHERE passage_id = ?`, passageID)
+	return err
+}
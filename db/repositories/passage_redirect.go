@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// PassageRedirectRepository 文章旧链接仓库接口
+type PassageRedirectRepository interface {
+	Save(oldPath string, passageID int) error
+	GetByPath(oldPath string) (*models.PassageRedirect, error)
+	GetByPassageID(passageID int) ([]models.PassageRedirect, error)
+	DeleteByPath(paths ...string) error
+	DeleteByPassageID(passageID int) error
+}
+
+// SQLitePassageRedirectRepository SQLite文章旧链接仓库实现
+type SQLitePassageRedirectRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePassageRedirectRepository 创建文章旧链接仓库
+func NewSQLitePassageRedirectRepository(db *sql.DB) *SQLitePassageRedirectRepository {
+	return &SQLitePassageRedirectRepository{db: db}
+}
+
+// Save 记录旧链接，同一路径已存在时改为指向新的文章
+func (r *SQLitePassageRedirectRepository) Save(oldPath string, passageID int) error {
+	query := `INSERT INTO passage_redirects (old_path, passage_id, created_at) VALUES (?, ?, ?)
+	          ON CONFLICT(old_path) DO UPDATE SET passage_id = excluded.passage_id, created_at = excluded.created_at`
+	_, err := r.db.Exec(query, oldPath, passageID, time.Now())
+	return err
+}
+
+func (r *SQLitePassageRedirectRepository) GetByPath(oldPath string) (*models.PassageRedirect, error) {
+	query := `SELECT id, old_path, passage_id, created_at FROM passage_redirects WHERE old_path = ?`
+
+	redirect := &models.PassageRedirect{}
+	err := r.db.QueryRow(query, oldPath).Scan(&redirect.ID, &redirect.OldPath, &redirect.PassageID, &redirect.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return redirect, nil
+}
+
+func (r *SQLitePassageRedirectRepository) GetByPassageID(passageID int) ([]models.PassageRedirect, error) {
+	query := `SELECT id, old_path, passage_id, created_at FROM passage_redirects
+	          WHERE passage_id = ? ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, passageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redirects []models.PassageRedirect
+	for rows.Next() {
+		var redirect models.PassageRedirect
+		if err := rows.Scan(&redirect.ID, &redirect.OldPath, &redirect.PassageID, &redirect.CreatedAt); err != nil {
+			return nil, err
+		}
+		redirects = append(redirects, redirect)
+	}
+
+	return redirects, rows.Err()
+}
+
+// DeleteByPath 删除指定路径的旧链接，路径重新成为某篇文章的当前地址时调用，避免跳转循环
+func (r *SQLitePassageRedirectRepository) DeleteByPath(paths ...string) error {
+	for _, path := range paths {
+		if _, err := r.db.Exec("DELETE FROM passage_redirects WHERE old_path = ?", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *SQLitePassageRedirectRepository) DeleteByPassageID(passageID int) error {
+	_, err := r.db.Exec("DELETE FROM passage_redirects WHERE passage_id = ?", passageID)
+	return err
+}
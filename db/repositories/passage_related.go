@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"myblog-gogogo/db/models"
+)
+
+// PassageRelatedRepository 相关文章仓库接口
+type PassageRelatedRepository interface {
+	ReplaceAll(related []models.PassageRelated) error
+	GetRelated(passageID, limit int) ([]models.Passage, error)
+}
+
+// SQLitePassageRelatedRepository SQLite相关文章仓库实现
+type SQLitePassageRelatedRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePassageRelatedRepository 创建相关文章仓库
+func NewSQLitePassageRelatedRepository(db *sql.DB) *SQLitePassageRelatedRepository {
+	return &SQLitePassageRelatedRepository{db: db}
+}
+
+// ReplaceAll 用新的计算结果替换全部相关文章
+func (r *SQLitePassageRelatedRepository) ReplaceAll(related []models.PassageRelated) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM passage_related`); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO passage_related (passage_id, related_id, score) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, item := range related {
+		if _, err := stmt.Exec(item.PassageID, item.RelatedID, item.Score); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetRelated 按得分获取文章的相关文章，只返回当前仍已发布且公开的文章
+func (r *SQLitePassageRelatedRepository) GetRelated(passageID, limit int) ([]models.Passage, error) {
+	query := `SELECT ` + passageColumns + ` FROM passages
+	          WHERE id IN (SELECT related_id FROM passage_related WHERE passage_id = ?) AND ` + publicPassageCondition + `
+	          ORDER BY (SELECT score FROM passage_related WHERE passage_id = ? AND related_id = passages.id) DESC, id DESC
+	          LIMIT ?`
+
+	rows, err := r.db.Query(query, passageID, passageID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPassages(rows)
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// PassageRevisionRepository 文章修订记录仓库接口
+type PassageRevisionRepository interface {
+	Create(revision *models.PassageRevision) error
+	GetByID(id int) (*models.PassageRevision, error)
+	GetByPassageID(passageID int, limit, offset int) ([]models.PassageRevision, error)
+	GetLatest(passageID int) (*models.PassageRevision, error)
+	CountByPassageID(passageID int) (int, error)
+	DeleteByPassageID(passageID int) error
+}
+
+// SQLitePassageRevisionRepository SQLite文章修订记录仓库实现
+type SQLitePassageRevisionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePassageRevisionRepository 创建文章修订记录仓库
+func NewSQLitePassageRevisionRepository(db *sql.DB) *SQLitePassageRevisionRepository {
+	return &SQLitePassageRevisionRepository{db: db}
+}
+
+func (r *SQLitePassageRevisionRepository) Create(revision *models.PassageRevision) error {
+	query := `INSERT INTO passage_revisions (passage_id, title, content, summary, category, author, source, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = time.Now()
+	}
+
+	result, err := r.db.Exec(query, revision.PassageID, revision.Title, revision.Content, revision.Summary,
+		revision.Category, revision.Author, revision.Source, revision.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	revision.ID = int(id)
+	return nil
+}
+
+func (r *SQLitePassageRevisionRepository) GetByID(id int) (*models.PassageRevision, error) {
+	query := `SELECT id, passage_id, title, content, summary, category, author, source, created_at
+	          FROM passage_revisions WHERE id = ?`
+
+	revision := &models.PassageRevision{}
+	err := r.db.QueryRow(query, id).Scan(
+		&revision.ID, &revision.PassageID, &revision.Title, &revision.Content, &revision.Summary,
+		&revision.Category, &revision.Author, &revision.Source, &revision.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// GetByPassageID 按时间倒序获取文章的修订记录
+func (r *SQLitePassageRevisionRepository) GetByPassageID(passageID int, limit, offset int) ([]models.PassageRevision, error) {
+	query := `SELECT id, passage_id, title, content, summary, category, author, source, created_at
+	          FROM passage_revisions WHERE passage_id = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+
+	rows, err := r.db.Query(query, passageID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.PassageRevision
+	for rows.Next() {
+		var revision models.PassageRevision
+		err := rows.Scan(
+			&revision.ID, &revision.PassageID, &revision.Title, &revision.Content, &revision.Summary,
+			&revision.Category, &revision.Author, &revision.Source, &revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// GetLatest 获取文章最新的一条修订记录，没有记录时返回 nil
+func (r *SQLitePassageRevisionRepository) GetLatest(passageID int) (*models.PassageRevision, error) {
+	revisions, err := r.GetByPassageID(passageID, 1, 0)
+	if err != nil || len(revisions) == 0 {
+		return nil, err
+	}
+	return &revisions[0], nil
+}
+
+func (r *SQLitePassageRevisionRepository) CountByPassageID(passageID int) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM passage_revisions WHERE passage_id = ?", passageID).Scan(&count)
+	return count, err
+}
+
+func (r *SQLitePassageRevisionRepository) DeleteByPassageID(passageID int) error {
+	_, err := r.db.Exec("DELETE FROM passage_revisions WHERE passage_id = ?", passageID)
+	return err
+}
@@ -61,6 +61,7 @@ func (r *passageTagRepository) Create(passageTag *models.PassageTag) error {
 		return fmt.Errorf("更新标签使用次数失败: %w", err)
 	}
 
+	notifyPassageChange(passageTag.PassageID)
 	return nil
 }
 
@@ -85,13 +86,19 @@ func (r *passageTagRepository) DeleteByPassageID(passageID int) error {
 		}
 	}
 
+	notifyPassageChange(passageID)
 	return nil
 }
 
 // DeleteByTagID 删除标签的所有文章关联
 func (r *passageTagRepository) DeleteByTagID(tagID int) error {
+	passageIDs, err := r.GetPassageIDsByTagID(tagID)
+	if err != nil {
+		return fmt.Errorf("获取文章ID失败: %w", err)
+	}
+
 	query := `DELETE FROM passage_tags WHERE tag_id = ?`
-	_, err := r.db.Exec(query, tagID)
+	_, err = r.db.Exec(query, tagID)
 	if err != nil {
 		return fmt.Errorf("删除标签文章关联失败: %w", err)
 	}
@@ -101,6 +108,7 @@ func (r *passageTagRepository) DeleteByTagID(tagID int) error {
 		return fmt.Errorf("更新标签使用次数失败: %w", err)
 	}
 
+	notifyPassageChange(passageIDs...)
 	return nil
 }
 
@@ -314,6 +322,7 @@ func (r *passageTagRepository) BatchCreate(passageID int, tagIDs []int) error {
 		return fmt.Errorf("提交事务失败: %w", err)
 	}
 
+	notifyPassageChange(passageID)
 	return nil
 }
 
@@ -431,5 +440,6 @@ func (r *passageTagRepository) ReplaceAll(passageID int, tagIDs []int) error {
 		return fmt.Errorf("提交事务失败: %w", err)
 	}
 
+	notifyPassageChange(passageID)
 	return nil
 }
\ No newline at end of file
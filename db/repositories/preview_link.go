@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// PreviewLinkRepository 文章预览链接仓库接口
+type PreviewLinkRepository interface {
+	Create(link *models.PreviewLink) error
+	GetByID(id int) (*models.PreviewLink, error)
+	GetByToken(token string) (*models.PreviewLink, error)
+	GetActiveByPassageID(passageID int, now time.Time) ([]models.PreviewLink, error)
+	Revoke(id int) error
+	RecordView(view *models.PreviewLinkView) error
+	CountViews(linkID int) (int, error)
+	GetViews(linkID, limit int) ([]models.PreviewLinkView, error)
+}
+
+// SQLitePreviewLinkRepository SQLite文章预览链接仓库实现
+type SQLitePreviewLinkRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePreviewLinkRepository 创建文章预览链接仓库
+func NewSQLitePreviewLinkRepository(db *sql.DB) *SQLitePreviewLinkRepository {
+	return &SQLitePreviewLinkRepository{db: db}
+}
+
+func (r *SQLitePreviewLinkRepository) Create(link *models.PreviewLink) error {
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+
+	query := `INSERT INTO preview_links (passage_id, token, created_by, expires_at, revoked, created_at) VALUES (?, ?, ?, ?, 0, ?)`
+	result, err := r.db.Exec(query, link.PassageID, link.Token, link.CreatedBy, link.ExpiresAt, link.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	link.ID = int(id)
+	return nil
+}
+
+func (r *SQLitePreviewLinkRepository) GetByID(id int) (*models.PreviewLink, error) {
+	return r.getOne(`SELECT id, passage_id, token, created_by, expires_at, revoked, created_at FROM preview_links WHERE id = ?`, id)
+}
+
+func (r *SQLitePreviewLinkRepository) GetByToken(token string) (*models.PreviewLink, error) {
+	return r.getOne(`SELECT id, passage_id, token, created_by, expires_at, revoked, created_at FROM preview_links WHERE token = ?`, token)
+}
+
+func (r *SQLitePreviewLinkRepository) getOne(query string, arg interface{}) (*models.PreviewLink, error) {
+	link := &models.PreviewLink{}
+	err := r.db.QueryRow(query, arg).Scan(&link.ID, &link.PassageID, &link.Token, &link.CreatedBy,
+		&link.ExpiresAt, &link.Revoked, &link.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// GetActiveByPassageID 获取文章未撤销且未过期的预览链接及其阅读次数，最新生成的在前
+func (r *SQLitePreviewLinkRepository) GetActiveByPassageID(passageID int, now time.Time) ([]models.PreviewLink, error) {
+	query := `SELECT l.id, l.passage_id, l.token, l.created_by, l.expires_at, l.revoked, l.created_at,
+	                 (SELECT COUNT(*) FROM preview_link_views v WHERE v.link_id = l.id)
+	          FROM preview_links l
+	          WHERE l.passage_id = ? AND l.revoked = 0 AND l.expires_at > ?
+	          ORDER BY l.created_at DESC, l.id DESC`
+
+	rows, err := r.db.Query(query, passageID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.PreviewLink{}
+	for rows.Next() {
+		var link models.PreviewLink
+		if err := rows.Scan(&link.ID, &link.PassageID, &link.Token, &link.CreatedBy,
+			&link.ExpiresAt, &link.Revoked, &link.CreatedAt, &link.ViewCount); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// Revoke 撤销预览链接，保留记录以便查看阅读日志
+func (r *SQLitePreviewLinkRepository) Revoke(id int) error {
+	_, err := r.db.Exec(`UPDATE preview_links SET revoked = 1 WHERE id = ?`, id)
+	return err
+}
+
+func (r *SQLitePreviewLinkRepository) RecordView(view *models.PreviewLinkView) error {
+	if view.ViewedAt.IsZero() {
+		view.ViewedAt = time.Now()
+	}
+
+	query := `INSERT INTO preview_link_views (link_id, ip, user_agent, viewed_at) VALUES (?, ?, ?, ?)`
+	result, err := r.db.Exec(query, view.LinkID, view.IP, view.UserAgent, view.ViewedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	view.ID = int(id)
+	return nil
+}
+
+func (r *SQLitePreviewLinkRepository) CountViews(linkID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM preview_link_views WHERE link_id = ?`, linkID).Scan(&count)
+	return count, err
+}
+
+// GetViews 获取预览链接最近的阅读记录
+func (r *SQLitePreviewLinkRepository) GetViews(linkID, limit int) ([]models.PreviewLinkView, error) {
+	query := `SELECT id, link_id, ip, user_agent, viewed_at FROM preview_link_views
+	          WHERE link_id = ? ORDER BY viewed_at DESC, id DESC LIMIT ?`
+
+	rows, err := r.db.Query(query, linkID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []models.PreviewLinkView{}
+	for rows.Next() {
+		var view models.PreviewLinkView
+		if err := rows.Scan(&view.ID, &view.LinkID, &view.IP, &view.UserAgent, &view.ViewedAt); err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	return views, rows.Err()
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// SeriesRepository 系列仓库接口
+type SeriesRepository interface {
+	Create(series *models.Series) error
+	GetByID(id int) (*models.Series, error)
+	GetBySlug(slug string) (*models.Series, error)
+	GetByName(name string) (*models.Series, error)
+	GetAll() ([]models.Series, error)
+	Update(series *models.Series) error
+	Delete(id int) error
+	GetMembership(passageID int) (*models.SeriesPassage, error)
+	SetMembership(member *models.SeriesPassage) error
+	RemoveMembership(passageID int) error
+	MaxPosition(seriesID int) (int, error)
+	GetPassages(seriesID int, publicOnly bool) ([]models.Passage, error)
+	CountPassages(publicOnly bool) (map[int]int, error)
+}
+
+// SQLiteSeriesRepository SQLite系列仓库实现
+type SQLiteSeriesRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteSeriesRepository 创建系列仓库
+func NewSQLiteSeriesRepository(db *sql.DB) *SQLiteSeriesRepository {
+	return &SQLiteSeriesRepository{db: db}
+}
+
+// publicPassageCondition 已发布的公开文章，与反向链接的条件一致
+const publicPassageCondition = `status = 'published' AND (visibility = 'public' OR visibility = '' OR visibility IS NULL)`
+
+const seriesColumns = `id, name, slug, description, created_at, updated_at`
+
+func scanSeries(row rowScanner) (*models.Series, error) {
+	series := &models.Series{}
+	err := row.Scan(&series.ID, &series.Name, &series.Slug, &series.Description, &series.CreatedAt, &series.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (r *SQLiteSeriesRepository) Create(series *models.Series) error {
+	now := time.Now()
+	if series.CreatedAt.IsZero() {
+		series.CreatedAt = now
+	}
+	series.UpdatedAt = now
+
+	result, err := r.db.Exec(`INSERT INTO series (name, slug, description, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		series.Name, series.Slug, series.Description, series.CreatedAt, series.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	series.ID = int(id)
+	return nil
+}
+
+func (r *SQLiteSeriesRepository) GetByID(id int) (*models.Series, error) {
+	return r.getOne(`SELECT `+seriesColumns+` FROM series WHERE id = ?`, id)
+}
+
+func (r *SQLiteSeriesRepository) GetBySlug(slug string) (*models.Series, error) {
+	return r.getOne(`SELECT `+seriesColumns+` FROM series WHERE slug = ?`, slug)
+}
+
+func (r *SQLiteSeriesRepository) GetByName(name string) (*models.Series, error) {
+	return r.getOne(`SELECT `+seriesColumns+` FROM series WHERE name = ?`, name)
+}
+
+// getOne 查询单个系列，不存在时返回 nil
+func (r *SQLiteSeriesRepository) getOne(query string, arg interface{}) (*models.Series, error) {
+	series, err := scanSeries(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return series, err
+}
+
+// GetAll 获取全部系列，按创建时间倒序
+func (r *SQLiteSeriesRepository) GetAll() ([]models.Series, error) {
+	rows, err := r.db.Query(`SELECT ` + seriesColumns + ` FROM series ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.Series{}
+	for rows.Next() {
+		series, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *series)
+	}
+	return list, rows.Err()
+}
+
+func (r *SQLiteSeriesRepository) Update(series *models.Series) error {
+	series.UpdatedAt = time.Now()
+	_, err := r.db.Exec(`UPDATE series SET name = ?, slug = ?, description = ?, updated_at = ? WHERE id = ?`,
+		series.Name, series.Slug, series.Description, series.UpdatedAt, series.ID)
+	return err
+}
+
+// Delete 删除系列及其成员关系，文章本身不受影响
+func (r *SQLiteSeriesRepository) Delete(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM series_passages WHERE series_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM series WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetMembership 获取文章所属的系列，不属于任何系列时返回 nil
+func (r *SQLiteSeriesRepository) GetMembership(passageID int) (*models.SeriesPassage, error) {
+	member := &models.SeriesPassage{}
+	err := r.db.QueryRow(`SELECT series_id, passage_id, position, created_at FROM series_passages WHERE passage_id = ?`, passageID).
+		Scan(&member.SeriesID, &member.PassageID, &member.Position, &member.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// SetMembership 设置文章所属的系列与顺序，文章原来属于其他系列时移动到新系列
+func (r *SQLiteSeriesRepository) SetMembership(member *models.SeriesPassage) error {
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+	query := `INSERT INTO series_passages (series_id, passage_id, position, created_at) VALUES (?, ?, ?, ?)
+	          ON CONFLICT(passage_id) DO UPDATE SET series_id = excluded.series_id, position = excluded.position`
+	_, err := r.db.Exec(query, member.SeriesID, member.PassageID, member.Position, member.CreatedAt)
+	return err
+}
+
+// RemoveMembership 将文章移出所属的系列
+func (r *SQLiteSeriesRepository) RemoveMembership(passageID int) error {
+	_, err := r.db.Exec(`DELETE FROM series_passages WHERE passage_id = ?`, passageID)
+	return err
+}
+
+// MaxPosition 获取系列中最大的顺序值，系列为空时返回 0
+func (r *SQLiteSeriesRepository) MaxPosition(seriesID int) (int, error) {
+	var position sql.NullInt64
+	err := r.db.QueryRow(`SELECT MAX(position) FROM series_passages WHERE series_id = ?`, seriesID).Scan(&position)
+	return int(position.Int64), err
+}
+
+// GetPassages 按顺序获取系列中的文章，顺序相同时按创建时间排列
+// publicOnly 为 true 时只返回已发布的公开文章
+func (r *SQLiteSeriesRepository) GetPassages(seriesID int, publicOnly bool) ([]models.Passage, error) {
+	query := `SELECT ` + passageColumns + ` FROM passages
+	          WHERE id IN (SELECT passage_id FROM series_passages WHERE series_id = ?)`
+	if publicOnly {
+		query += ` AND ` + publicPassageCondition
+	}
+	query += ` ORDER BY (SELECT position FROM series_passages WHERE passage_id = passages.id), created_at, id`
+
+	rows, err := r.db.Query(query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPassages(rows)
+}
+
+// CountPassages 统计每个系列的文章数，publicOnly 为 true 时只统计已发布的公开文章
+func (r *SQLiteSeriesRepository) CountPassages(publicOnly bool) (map[int]int, error) {
+	query := `SELECT series_id, COUNT(*) FROM series_passages`
+	if publicOnly {
+		query += ` WHERE passage_id IN (SELECT id FROM passages WHERE ` + publicPassageCondition + `)`
+	}
+	query += ` GROUP BY series_id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var seriesID, count int
+		if err := rows.Scan(&seriesID, &count); err != nil {
+			return nil, err
+		}
+		counts[seriesID] = count
+	}
+	return counts, rows.Err()
+}
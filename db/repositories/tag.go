@@ -168,8 +168,13 @@ func (r *SQLiteTagRepository) Update(tag *models.Tag) error {
 
 	_, err := r.db.Exec(query, tag.Name, tag.Description, tag.Color,
 		tag.CategoryID, tag.SortOrder, tag.IsEnabled, tag.UsageCount, tag.UpdatedAt, tag.ID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// 标签名可能变化，通知所有文章
+	notifyPassageChange(0)
+	return nil
 }
 
 func (r *SQLiteTagRepository) Delete(id int) error {
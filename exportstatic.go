@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"myblog-gogogo/config"
+	"myblog-gogogo/controller"
+	"myblog-gogogo/db"
+	"myblog-gogogo/middleware"
+	"myblog-gogogo/pkg/beautify"
+	"myblog-gogogo/pkg/logger"
+	"myblog-gogogo/router"
+	"myblog-gogogo/service/staticexport"
+)
+
+// runExportStatic 子命令 export-static：将公开内容导出为静态 HTML 镜像后退出
+// 用法：myblog-gogogo export-static -out dist [-base-url https://example.com] [-db-conn ...]
+func runExportStatic(args []string) int {
+	if err := extractEmbeddedResources(); err != nil {
+		beautify.Errorf("资源释放失败: %v", err)
+		return 1
+	}
+
+	out := flag.String("out", "", "Output directory for the static export")
+	baseURL := flag.String("base-url", "", "Site URL used in feeds and sitemap when the template setting is empty")
+	os.Args = append([]string{os.Args[0]}, args...)
+	cfg := config.Load()
+	logger.SetLevelFromString(cfg.LogLevel)
+	if *out == "" {
+		beautify.Error("请使用 -out 指定输出目录")
+		return 2
+	}
+
+	beautify.Header("导出静态站点")
+	beautify.Section("数据库初始化")
+	beautify.Indent()
+	if err := db.InitDB(cfg.DBDriver, cfg.DBConnStr, cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.DBConnMaxLifetime, cfg.DBConnMaxIdleTime); err != nil {
+		beautify.ErrorLeaf(fmt.Sprintf("初始化失败: %v", err))
+		return 1
+	}
+	defer db.CloseDB()
+	beautify.SuccessLeaf(fmt.Sprintf("连接: %s", cfg.DBConnStr))
+	beautify.Outdent()
+
+	controller.InitTemplates()
+	controller.InitJWTSecret(cfg.JWTSecret)
+	controller.InitAboutRepositories(db.GetDB())
+
+	// 与服务相同的认证与文章访问检查，导出内容与匿名访客看到的一致
+	handler := middleware.CheckPassageAccess(router.SetupRoutes())
+	handler = middleware.AuthMiddleware(handler)
+
+	beautify.Section(fmt.Sprintf("导出到 %s", *out))
+	beautify.Indent()
+	result, err := staticexport.New(handler, staticexport.Options{
+		OutDir:  *out,
+		BaseURL: *baseURL,
+		Assets:  controller.GetTemplateFS(),
+	}).Run()
+	if err != nil {
+		beautify.ErrorLeaf(err.Error())
+		beautify.Outdent()
+		return 1
+	}
+	for _, warning := range result.Warnings {
+		beautify.Warn(warning)
+	}
+	beautify.Leaf(fmt.Sprintf("页面 %d 个，接口快照 %d 个，静态资源 %d 个", result.Pages, result.APIs, result.Assets))
+	beautify.Outdent()
+
+	beautify.Successf("导出完成: %s", *out)
+	return 0
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"myblog-gogogo/config"
+	"myblog-gogogo/db"
+	"myblog-gogogo/pkg/beautify"
+	"myblog-gogogo/pkg/logger"
+	"myblog-gogogo/service"
+	"myblog-gogogo/service/importer"
+)
+
+// runImport 子命令 import：从 Hugo、Hexo、Jekyll 站点目录或 WordPress WXR 文件导入文章后退出
+// 用法：myblog-gogogo import -source hugo -path ./site [-dry-run]
+//
+//	myblog-gogogo import -source wordpress -path export.xml [-uploads ./wp-content/uploads] [-dry-run]
+func runImport(args []string) int {
+	// 附件与 markdown 文件写入当前目录下的资源目录
+	if err := extractEmbeddedResources(); err != nil {
+		beautify.Errorf("资源释放失败: %v", err)
+		return 1
+	}
+
+	sourceName := flag.String("source", "", "Import source: hugo, hexo, jekyll or wordpress")
+	path := flag.String("path", "", "Site root directory, or the WXR file for WordPress")
+	uploads := flag.String("uploads", "", "Copy of wp-content/uploads used to import WordPress media")
+	dryRun := flag.Bool("dry-run", false, "Only print the report without writing anything")
+	os.Args = append([]string{os.Args[0]}, args...)
+	cfg := config.Load()
+	logger.SetLevelFromString(cfg.LogLevel)
+
+	source, err := importer.ParseSource(*sourceName)
+	if err != nil {
+		beautify.Error(err.Error())
+		return 2
+	}
+	if *path == "" {
+		beautify.Error("请使用 -path 指定站点目录或 WXR 文件")
+		return 2
+	}
+
+	title := "导入文章"
+	if *dryRun {
+		title = "导入文章（预演）"
+	}
+	beautify.Header(title)
+	beautify.Section("数据库初始化")
+	beautify.Indent()
+	if err := db.InitDB(cfg.DBDriver, cfg.DBConnStr, cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.DBConnMaxLifetime, cfg.DBConnMaxIdleTime); err != nil {
+		beautify.ErrorLeaf(fmt.Sprintf("初始化失败: %v", err))
+		return 1
+	}
+	defer db.CloseDB()
+	beautify.SuccessLeaf(fmt.Sprintf("连接: %s", cfg.DBConnStr))
+	beautify.Outdent()
+
+	// 导入的图片在工作池中生成缩略图，退出前等待完成
+	service.InitWorkerPool(cfg.WorkerCount, cfg.WorkerQueueSize)
+	defer service.CloseWorkerPool()
+
+	report, err := importer.Run(importer.Options{
+		Source:  source,
+		Path:    *path,
+		Uploads: *uploads,
+		DryRun:  *dryRun,
+		Author:  "cli",
+	})
+	if err != nil {
+		beautify.Errorf("导入失败: %v", err)
+		return 1
+	}
+
+	beautify.Section(fmt.Sprintf("文章（%s）", *path))
+	beautify.Indent()
+	for _, item := range report.Items {
+		line := fmt.Sprintf("%s → %s", item.Origin, item.File)
+		switch item.Status {
+		case importer.ItemImported, importer.ItemPlanned:
+			beautify.SuccessLeaf(fmt.Sprintf("%s（图片 %d，评论 %d）", line, item.Images, item.Comments))
+		case importer.ItemConflict:
+			beautify.Warn(fmt.Sprintf("%s: %s", line, item.Message))
+		default:
+			beautify.ErrorLeaf(fmt.Sprintf("%s: %s", line, item.Message))
+		}
+		for _, warning := range item.Warnings {
+			beautify.Leaf("  " + warning)
+		}
+	}
+	beautify.Outdent()
+
+	if len(report.Unsupported) > 0 {
+		beautify.Section("未导入的内容")
+		beautify.Indent()
+		for _, name := range report.Unsupported {
+			beautify.Leaf(name)
+		}
+		beautify.Outdent()
+	}
+
+	verb := "导入"
+	if report.DryRun {
+		verb = "将导入"
+	}
+	summary := fmt.Sprintf("%s %d 篇文章、%d 张图片、%d 条评论，冲突 %d 篇，失败 %d 篇",
+		verb, report.Imported, report.Images, report.Comments, report.Conflicts, report.Failed)
+	if report.Failed > 0 {
+		beautify.Errorf("%s", summary)
+		return 1
+	}
+	beautify.Successf("%s", summary)
+	return 0
+}
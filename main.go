@@ -21,6 +21,7 @@ import (
 	"myblog-gogogo/server"
 	"myblog-gogogo/service"
 	"myblog-gogogo/service/attachment"
+	"myblog-gogogo/service/backup"
 	"myblog-gogogo/pkg/beautify"
 	"myblog-gogogo/pkg/logger"
 )
@@ -34,6 +35,24 @@ func init() {
 }
 
 func main() {
+	// 子命令：rebuild 重新渲染全部文章后退出，export-static 导出静态镜像后退出，import 导入其他博客的文章后退出，
+	// backup 与 restore 备份或恢复全站数据后退出
+	if len(os.Args) > 1 && os.Args[1] == "rebuild" {
+		os.Exit(runRebuild(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export-static" {
+		os.Exit(runExportStatic(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		os.Exit(runBackup(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		os.Exit(runRestore(os.Args[2:]))
+	}
+
 	// 初始化嵌入的模板文件系统
 	controller.SetTemplateFS(templateFS)
 	// 初始化模板
@@ -195,9 +214,40 @@ func main() {
 	}()
 	beautify.SuccessLeaf(fmt.Sprintf("会话清理任务已启动（每 %d 分钟）", cfg.SessionCleanupInterval))
 
+	// 启动定时发布调度器（启动时会补发停机期间到期的文章）
+	beautify.Branch("定时发布")
+	repo := db.GetPassageRepository()
+	service.InitPublishScheduler(repo, time.Duration(cfg.SchedulerInterval)*time.Second)
+	defer service.StopPublishScheduler()
+	beautify.SuccessLeaf(fmt.Sprintf("定时发布调度器已启动（每 %d 秒检查）", cfg.SchedulerInterval))
+
+	// 定时本地备份
+	beautify.Branch("定时备份")
+	if cfg.BackupInterval > 0 {
+		backupScheduler := backup.NewScheduler(backup.DefaultDir, ".", time.Duration(cfg.BackupInterval)*time.Hour, cfg.BackupKeep)
+		backupScheduler.Start()
+		defer backupScheduler.Stop()
+		beautify.SuccessLeaf(fmt.Sprintf("定时备份已启动（每 %d 小时，保留 %d 个）", cfg.BackupInterval, cfg.BackupKeep))
+	} else {
+		beautify.Leaf("定时备份未启用（使用 --backup-interval 启用）")
+	}
+
+	// 构建搜索索引（之后随文章的增删改自动更新）
+	beautify.Branch("搜索索引")
+	if searchService, err := service.InitSearchService(); err != nil {
+		beautify.ErrorLeaf(fmt.Sprintf("构建失败: %v", err))
+	} else {
+		beautify.SuccessLeaf(fmt.Sprintf("搜索索引构建完成（%d 篇文章）", searchService.Len()))
+	}
+
+	// 相关文章（后台计算，之后随文章的增删改重新计算）
+	beautify.Branch("相关文章")
+	service.InitRelatedJob()
+	defer service.StopRelatedJob()
+	beautify.SuccessLeaf("相关文章计算任务已启动")
+
 	// 启动文件监控
 	beautify.Branch("文件监控")
-	repo := db.GetPassageRepository()
 	syncService := service.NewSyncService(repo)
 	if cfg.EnableFileWatch {
 		go func() {
@@ -75,12 +75,15 @@ func AuthMiddleware(next http.Handler) http.Handler {
 				"/api/attachments/by-date":   true, // 根据文章日期获取附件列表API公开，无需鉴权
 				"/api/crypto/public-key":     true, // ECC公钥获取API公开
 				"/api/user/info":             true, // 用户信息API公开，用于检查登录状态
+				"/api/search":                true, // 搜索API公开，管理员登录时可检索未发布文章
+				"/api/series":                true, // 系列列表与详情API公开
 				//"/api/crypto/decrypt":        true, // ECC解密API公开
 			}
 
 			// 检查是否是公开API
+			// 公开API不要求登录，但携带有效token时仍写入用户信息，便于按角色返回不同内容
 			if publicAPIs[r.URL.Path] {
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(w, withOptionalClaims(r))
 				return
 			}
 
@@ -88,12 +91,12 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			for apiPath := range publicAPIs {
 				// 精确匹配或路径前缀匹配（确保 /api/passages/123 不会被 /api/passages 误匹配）
 				if r.URL.Path == apiPath {
-					next.ServeHTTP(w, r)
+					next.ServeHTTP(w, withOptionalClaims(r))
 					return
 				}
 				// 对于需要前缀匹配的路径，确保后面跟着 /
 				if strings.HasPrefix(r.URL.Path, apiPath+"/") {
-					next.ServeHTTP(w, r)
+					next.ServeHTTP(w, withOptionalClaims(r))
 					return
 				}
 			}
@@ -176,4 +179,28 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		next.ServeHTTP(w, r)
 	})
-}
\ No newline at end of file
+}
+
+// withOptionalClaims 从 Authorization header 或 cookie 中读取 token，验证通过时将用户信息写入 context
+// 没有 token 或 token 无效时原样返回请求
+func withOptionalClaims(r *http.Request) *http.Request {
+	tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if tokenString == "" {
+		cookie, err := r.Cookie("auth_token")
+		if err != nil {
+			return r
+		}
+		tokenString = cookie.Value
+	}
+
+	claims, err := auth.ValidateToken(tokenString)
+	if err != nil {
+		logger.Debug("[AuthMiddleware] Optional token validation failed on path %s: %v", r.URL.Path, err)
+		return r
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, UsernameKey, claims.Username)
+	ctx = context.WithValue(ctx, RoleKey, claims.Role)
+	return r.WithContext(ctx)
+}
@@ -8,12 +8,13 @@ import (
 )
 
 // ContextKey 用于在context中存储用户信息的key
-type ContextKey string
+// 与 controller 包共用同一类型，保证两个包读写的是同一个 context 值
+type ContextKey = controller.ContextKey
 
 const (
-	UserIDKey   ContextKey = "user_id"
-	UsernameKey ContextKey = "username"
-	RoleKey     ContextKey = "role"
+	UserIDKey   = controller.UserIDKey
+	UsernameKey = controller.UsernameKey
+	RoleKey     = controller.RoleKey
 )
 
 // GetUserID 从context中获取用户ID
@@ -7,8 +7,7 @@ import (
 	"net/http"
 	"strings"
 
-	"myblog-gogogo/db"
-	"myblog-gogogo/db/models"
+	"myblog-gogogo/service"
 )
 
 // CheckPassageAccess 检查文章访问权限
@@ -21,43 +20,19 @@ func CheckPassageAccess(next http.Handler) http.Handler {
 			return
 		}
 
-		// 提取文章ID
-		path := strings.TrimPrefix(r.URL.Path, "/passage/")
-		path = strings.TrimSuffix(path, "/")
-
-		// 路径格式: /passage/:year/:month/:day/:name
-		parts := strings.Split(path, "/")
-		if len(parts) < 4 {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		// 获取文章路径
-		dateDir := strings.Join(parts[:3], "/")
-		title := strings.Join(parts[3:], "/")
-		targetPath := dateDir + "/" + title
-
-		// 从数据库获取文章信息
-		repo := db.GetPassageRepository()
-		passages, err := repo.GetAll(1000, 0)
-		if err != nil {
+		// 按别名、文件路径与旧链接查找文章
+		lookup, err := service.ResolvePassageURL(r.URL.Path)
+		if err != nil || lookup == nil || lookup.Redirect != "" {
+			// 未找到或需要跳转的地址交给 PassageHandler 处理
 			next.ServeHTTP(w, r)
 			return
 		}
+		targetPassage := lookup.Passage
 
-		// 查找匹配的文章
-		var targetPassage *models.Passage
-		for _, p := range passages {
-			// 比较文件路径
-			if p.FilePath == targetPath {
-				targetPassage = &p
-				break
-			}
-		}
-
-		// 如果没有找到匹配的文章，放行
-		if targetPassage == nil {
-			next.ServeHTTP(w, r)
+		// 有效的预览链接不受状态与可见性限制，也不显示未发布提示
+		if link := service.ValidatePreviewLink(targetPassage.ID, r.URL.Query().Get("preview")); link != nil {
+			ctx := context.WithValue(r.Context(), "passage_preview_link", link)
+			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
@@ -0,0 +1,312 @@
+// Package callout 提示块与折叠块：:::note 容器与 Obsidian、GitHub 风格的 > [!NOTE] 引用
+package callout
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// titles 各类提示块的默认标题
+var titles = map[string]string{
+	"note":      "备注",
+	"info":      "信息",
+	"tip":       "提示",
+	"important": "重要",
+	"warning":   "警告",
+	"caution":   "注意",
+	"danger":    "危险",
+	"success":   "成功",
+	"question":  "问题",
+	"example":   "示例",
+	"quote":     "引用",
+	"abstract":  "摘要",
+	"todo":      "待办",
+	"bug":       "缺陷",
+	"failure":   "失败",
+	"details":   "详情",
+}
+
+// aliases Obsidian 提示块类型的别名
+var aliases = map[string]string{
+	"hint":      "tip",
+	"attention": "warning",
+	"error":     "danger",
+	"check":     "success",
+	"done":      "success",
+	"help":      "question",
+	"faq":       "question",
+	"cite":      "quote",
+	"summary":   "abstract",
+	"tldr":      "abstract",
+	"fail":      "failure",
+	"missing":   "failure",
+}
+
+// KindContainer 容器节点类型
+var KindContainer = ast.NewNodeKind("Container")
+
+// ContainerNode 提示块与折叠块节点，由 :::note 容器或 > [!NOTE] 引用生成，内部可嵌套任意 markdown
+type ContainerNode struct {
+	ast.BaseBlock
+	Callout string // 提示块类型，决定 CSS 类名 callout-<type>
+	Title   string
+	Details bool // 是否渲染为可折叠的 <details>
+	Open    bool // 折叠块是否默认展开
+	fence   int  // ::: 的长度，结束行至少要有同样多的冒号
+}
+
+// Kind 实现 Node 接口
+func (n *ContainerNode) Kind() ast.NodeKind {
+	return KindContainer
+}
+
+// Dump 实现 Node 接口
+func (n *ContainerNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Callout": n.Callout, "Title": n.Title}, nil)
+}
+
+var (
+	// containerOpenRegex :::note 标题、:::details{summary="..."}
+	containerOpenRegex = regexp.MustCompile(`^(:{3,})[ \t]*([A-Za-z]+)[ \t]*(\{[^}]*\})?[ \t]*(.*?)[ \t]*$`)
+	// containerAttrRegex 容器属性 key="value" 或单独的 key
+	containerAttrRegex = regexp.MustCompile(`([A-Za-z-]+)(?:="([^"]*)")?`)
+	// calloutRegex 引用块首行的 [!NOTE]、[!TIP]- 标题
+	calloutRegex = regexp.MustCompile(`^\[!([A-Za-z-]+)\]([+-]?)[ \t]*(.*?)[ \t]*$`)
+)
+
+// containerParser 解析 ::: 容器
+// 嵌套容器使用更长的冒号，例如 ::::warning 中包含 :::details
+type containerParser struct{}
+
+func (p *containerParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (p *containerParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	m := containerOpenRegex.FindSubmatch(bytes.TrimRight(line[pos:], "\r\n"))
+	if m == nil {
+		return nil, parser.NoChildren
+	}
+	name := strings.ToLower(string(m[2]))
+	if _, ok := titles[name]; !ok {
+		return nil, parser.NoChildren
+	}
+
+	node := &ContainerNode{
+		Callout: name,
+		Title:   string(m[4]),
+		Details: name == "details",
+		fence:   len(m[1]),
+	}
+	for _, attr := range containerAttrRegex.FindAllSubmatch(m[3], -1) {
+		switch string(attr[1]) {
+		case "summary", "title":
+			node.Title = string(attr[2])
+		case "open":
+			node.Open = true
+		}
+	}
+	if node.Title == "" {
+		node.Title = titles[name]
+	}
+
+	reader.Advance(lineLength(line))
+	return node, parser.HasChildren
+}
+
+func (p *containerParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*ContainerNode)
+	line, _ := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	trimmed := bytes.TrimSpace(line)
+	colons := 0
+	for colons < len(trimmed) && trimmed[colons] == ':' {
+		colons++
+	}
+	if colons >= n.fence && colons == len(trimmed) {
+		reader.Advance(lineLength(line))
+		return parser.Close
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+func (p *containerParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// lineLength 行的长度，不包含结尾的换行；文件最后一行可能没有换行
+func lineLength(line []byte) int {
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		return n - 1
+	}
+	return len(line)
+}
+
+func (p *containerParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *containerParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// ASTTransformer 将首行为 [!TYPE] 的引用块转换为提示块
+// [!TYPE]- 与 [!TYPE]+ 分别为默认折叠、默认展开的折叠块，未知类型按 note 处理
+type ASTTransformer struct{}
+
+// Transform 转换 AST
+func (t *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	// 先收集再替换，避免遍历过程中修改树
+	var quotes []*ast.Blockquote
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if q, ok := n.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, q)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, quote := range quotes {
+		para, ok := quote.FirstChild().(*ast.Paragraph)
+		if !ok || para.Lines().Len() == 0 {
+			continue
+		}
+		first := para.Lines().At(0)
+		m := calloutRegex.FindSubmatch(bytes.TrimRight(first.Value(source), "\r\n"))
+		if m == nil {
+			continue
+		}
+
+		name := strings.ToLower(string(m[1]))
+		title := string(m[3])
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if _, ok := titles[name]; !ok || name == "details" {
+			if title == "" {
+				title = string(m[1])
+			}
+			name = "note"
+		}
+		if title == "" {
+			title = titles[name]
+		}
+
+		container := &ContainerNode{
+			Callout: name,
+			Title:   title,
+			Details: len(m[2]) > 0,
+			Open:    string(m[2]) == "+",
+		}
+		removeFirstLine(para, first.Stop, source)
+		if para.ChildCount() == 0 {
+			quote.RemoveChild(quote, para)
+		}
+		for child := quote.FirstChild(); child != nil; child = quote.FirstChild() {
+			quote.RemoveChild(quote, child)
+			container.AppendChild(container, child)
+		}
+		quote.Parent().ReplaceChild(quote.Parent(), quote, container)
+	}
+}
+
+// removeFirstLine 删除段落中位于首行的行内节点
+func removeFirstLine(para *ast.Paragraph, stop int, source []byte) {
+	for child := para.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		if start, ok := textStart(child); ok && start >= stop {
+			break
+		}
+		para.RemoveChild(para, child)
+		child = next
+	}
+
+	lines := text.NewSegments()
+	for i := 1; i < para.Lines().Len(); i++ {
+		lines.Append(para.Lines().At(i))
+	}
+	para.SetLines(lines)
+}
+
+// textStart 返回节点中第一个文本在源码中的位置
+func textStart(n ast.Node) (int, bool) {
+	start, found := 0, false
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := child.(*ast.Text); ok && entering {
+			start, found = t.Segment.Start, true
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return start, found
+}
+
+// Renderer 提示块渲染器
+// 普通提示块渲染为 <aside class="callout callout-note">，折叠块渲染为 <details class="callout callout-details">
+type Renderer struct{}
+
+// RegisterFuncs 注册渲染函数
+func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindContainer, r.renderContainer)
+}
+
+// renderContainer 渲染提示块节点
+func (r *Renderer) renderContainer(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ContainerNode)
+	title := html.EscapeString(n.Title)
+
+	if !entering {
+		if n.Details {
+			_, _ = w.WriteString("</div>\n</details>\n")
+		} else {
+			_, _ = w.WriteString("</div>\n</aside>\n")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if n.Details {
+		open := ""
+		if n.Open {
+			open = " open"
+		}
+		_, _ = w.WriteString(`<details class="callout callout-` + n.Callout + `"` + open + ">\n")
+		_, _ = w.WriteString(`<summary class="callout-title">` + title + "</summary>\n")
+	} else {
+		_, _ = w.WriteString(`<aside class="callout callout-` + n.Callout + `">` + "\n")
+		_, _ = w.WriteString(`<p class="callout-title">` + title + "</p>\n")
+	}
+	_, _ = w.WriteString(`<div class="callout-content">` + "\n")
+	return ast.WalkContinue, nil
+}
+
+// Extension ::: 容器与 > [!NOTE] 提示块扩展
+type Extension struct{}
+
+// NewExtension 创建提示块扩展
+func NewExtension() goldmark.Extender {
+	return &Extension{}
+}
+
+// Extend 扩展 Goldmark
+func (e *Extension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&containerParser{}, 100)),
+		parser.WithASTTransformers(util.Prioritized(&ASTTransformer{}, 100)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&Renderer{}, 100)))
+}
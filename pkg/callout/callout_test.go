@@ -0,0 +1,127 @@
+package callout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// convert 使用与文章相同的换行选项转换 markdown
+func convert(t *testing.T, src string) string {
+	t.Helper()
+	md := goldmark.New(
+		goldmark.WithExtensions(NewExtension()),
+		goldmark.WithRendererOptions(html.WithHardWraps(), html.WithXHTML()),
+	)
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	return buf.String()
+}
+
+// TestContainerTypes 测试每种 ::: 容器的类名与默认标题
+func TestContainerTypes(t *testing.T) {
+	for name, title := range titles {
+		t.Run(name, func(t *testing.T) {
+			got := convert(t, ":::"+name+"\nbody\n:::\n")
+			want := []string{`<aside class="callout callout-` + name + `">`, `<p class="callout-title">` + title + `</p>`, `<p>body</p>`}
+			if name == "details" {
+				want = []string{`<details class="callout callout-details">`, `<summary class="callout-title">` + title + `</summary>`, `<p>body</p>`}
+			}
+			for _, w := range want {
+				if !strings.Contains(got, w) {
+					t.Errorf("html missing %s:\n%s", w, got)
+				}
+			}
+			if strings.Contains(got, ":::") {
+				t.Errorf("fence left in output:\n%s", got)
+			}
+		})
+	}
+}
+
+// TestContainer 测试标题、属性、引用提示块、嵌套与文件末尾的结束行
+func TestContainer(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+		not  []string
+	}{
+		{
+			name: "custom title",
+			src:  ":::warning 小心 <b>\nbody\n:::\n",
+			want: []string{`<aside class="callout callout-warning">`, `<p class="callout-title">小心 &lt;b&gt;</p>`},
+		},
+		{
+			name: "details summary",
+			src:  ":::details{summary=\"展开查看\" open}\nhidden\n:::\n",
+			want: []string{`<details class="callout callout-details" open>`, `<summary class="callout-title">展开查看</summary>`, `<p>hidden</p>`},
+		},
+		{
+			name: "unknown type",
+			src:  ":::unknown\nbody\n:::\n",
+			want: []string{`<p>:::unknown`},
+			not:  []string{`callout`},
+		},
+		{
+			name: "blockquote note",
+			src:  "> [!NOTE]\n> body\n",
+			want: []string{`<aside class="callout callout-note">`, `<p class="callout-title">备注</p>`, `<p>body</p>`},
+			not:  []string{`<blockquote>`, `[!NOTE]`},
+		},
+		{
+			name: "blockquote folded",
+			src:  "> [!TIP]- 标题\n> body\n",
+			want: []string{`<details class="callout callout-tip">`, `<summary class="callout-title">标题</summary>`},
+			not:  []string{` open>`},
+		},
+		{
+			name: "blockquote alias open",
+			src:  "> [!hint]+\n> body\n",
+			want: []string{`<details class="callout callout-tip" open>`, `<summary class="callout-title">提示</summary>`},
+		},
+		{
+			name: "blockquote unknown type",
+			src:  "> [!Custom]\n> body\n",
+			want: []string{`<aside class="callout callout-note">`, `<p class="callout-title">Custom</p>`},
+		},
+		{
+			name: "nested with longer fence",
+			src:  "::::warning\nouter\n\n:::note\ninner\n:::\n\nafter\n::::\n\nend\n",
+			want: []string{"<aside class=\"callout callout-warning\">\n<p class=\"callout-title\">警告</p>\n<div class=\"callout-content\">\n<p>outer</p>\n<aside class=\"callout callout-note\">", "<p>inner</p>\n</div>\n</aside>\n<p>after</p>\n</div>\n</aside>\n<p>end</p>"},
+		},
+		{
+			name: "closing fence at end of file",
+			src:  ":::tip\nbody\n:::",
+			want: []string{"<p>body</p>\n</div>\n</aside>"},
+			not:  []string{`<br />`, `:</p>`},
+		},
+		{
+			name: "nested closing fences at end of file",
+			src:  "::::warning\n:::note\nbody\n:::\n::::",
+			want: []string{"<p>body</p>\n</div>\n</aside>\n</div>\n</aside>"},
+			not:  []string{`:</p>`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := convert(t, tt.src)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("html missing %s:\n%s", w, got)
+				}
+			}
+			for _, n := range tt.not {
+				if strings.Contains(got, n) {
+					t.Errorf("html should not contain %s:\n%s", n, got)
+				}
+			}
+		})
+	}
+}
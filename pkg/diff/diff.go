@@ -0,0 +1,265 @@
+// Package diff 基于 Myers 算法的按行文本比较，输出 unified diff 格式
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext unified diff 默认上下文行数
+const DefaultContext = 3
+
+// opKind 编辑操作类型
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// edit 单行编辑操作，aLine/bLine 为操作前两侧已处理的行数
+type edit struct {
+	kind  opKind
+	text  string
+	aLine int
+	bLine int
+}
+
+// Stats 变更统计
+type Stats struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// Unified 比较两段文本并返回 unified diff，内容相同时返回空字符串
+func Unified(fromName, toName, from, to string, context int) string {
+	if from == to {
+		return ""
+	}
+	if context < 0 {
+		context = DefaultContext
+	}
+
+	edits := lineEdits(splitLines(from), splitLines(to))
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromName, toName)
+
+	for _, h := range hunks(edits, context) {
+		first := edits[h[0]]
+		aCount, bCount := 0, 0
+		for _, e := range edits[h[0]:h[1]] {
+			if e.kind != opInsert {
+				aCount++
+			}
+			if e.kind != opDelete {
+				bCount++
+			}
+		}
+
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(first.aLine, aCount), hunkRange(first.bLine, bCount))
+		for _, e := range edits[h[0]:h[1]] {
+			switch e.kind {
+			case opEqual:
+				buf.WriteString(" ")
+			case opDelete:
+				buf.WriteString("-")
+			case opInsert:
+				buf.WriteString("+")
+			}
+			buf.WriteString(e.text)
+			buf.WriteString("\n")
+		}
+	}
+
+	return buf.String()
+}
+
+// LineStats 统计两段文本之间新增和删除的行数
+func LineStats(from, to string) Stats {
+	var stats Stats
+	if from == to {
+		return stats
+	}
+	for _, e := range lineEdits(splitLines(from), splitLines(to)) {
+		switch e.kind {
+		case opDelete:
+			stats.Removed++
+		case opInsert:
+			stats.Added++
+		}
+	}
+	return stats
+}
+
+// splitLines 按行拆分文本，忽略末尾换行
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// hunkRange 格式化 hunk 头中的行范围（起始行从 1 开始，空范围时为前一行）
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// hunks 根据变更位置划分 hunk，返回每个 hunk 在 edits 中的 [start, end) 区间
+func hunks(edits []edit, context int) [][2]int {
+	var result [][2]int
+	for i := 0; i < len(edits); i++ {
+		if edits[i].kind == opEqual {
+			continue
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// 向后扩展，直到连续相同行超过两倍上下文
+		end := i + 1
+		for end < len(edits) {
+			if edits[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].kind == opEqual {
+				run++
+			}
+			if run < len(edits) && run-end <= 2*context {
+				end = run
+				continue
+			}
+			break
+		}
+
+		stop := end + context
+		if stop > len(edits) {
+			stop = len(edits)
+		}
+		result = append(result, [2]int{start, stop})
+		i = stop - 1
+	}
+	return result
+}
+
+// lineEdits 计算从 a 到 b 的最短编辑序列
+func lineEdits(a, b []string) []edit {
+	// 先去掉公共前缀和后缀，缩小 Myers 算法的搜索范围
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{kind: opEqual, text: a[i], aLine: i, bLine: i})
+	}
+
+	for _, e := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		e.aLine += prefix
+		e.bLine += prefix
+		edits = append(edits, e)
+	}
+
+	for i := 0; i < suffix; i++ {
+		ai, bi := len(a)-suffix+i, len(b)-suffix+i
+		edits = append(edits, edit{kind: opEqual, text: a[ai], aLine: ai, bLine: bi})
+	}
+	return edits
+}
+
+// myers Myers O(ND) 差分算法
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// 回溯得到编辑序列（逆序）
+	var reversed []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		vd := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && vd[offset+k-1] < vd[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := vd[offset+prevK]
+		prevY := prevX - prevK
+		if d == 0 {
+			prevX, prevY = 0, 0
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, edit{kind: opEqual, text: a[x], aLine: x, bLine: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				reversed = append(reversed, edit{kind: opInsert, text: b[y], aLine: x, bLine: y})
+			} else {
+				x--
+				reversed = append(reversed, edit{kind: opDelete, text: a[x], aLine: x, bLine: y})
+			}
+		}
+	}
+
+	edits := make([]edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
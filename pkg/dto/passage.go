@@ -24,8 +24,10 @@ type PassageDTO struct {
 
 // PassageAccessRequest 文章访问请求
 type PassageAccessRequest struct {
-	PassageID int    `json:"passage_id"`
-	UserRole  string `json:"user_role"`
+	PassageID    int    `json:"passage_id"`
+	UserRole     string `json:"user_role"`
+	UnlockToken  string `json:"unlock_token,omitempty"`  // 受密码保护文章的解锁凭证
+	PreviewToken string `json:"preview_token,omitempty"` // 预览链接的令牌，有效时可以阅读未发布的文章
 }
 
 // PassageAccessResponse 文章访问响应
@@ -45,7 +47,7 @@ type CreatePassageRequest struct {
 	Content        string    `json:"content" binding:"required"`
 	Status         string    `json:"status"`
 	Visibility     string    `json:"visibility"`
-	ShowTitle      bool      `json:"show_title"`
+	ShowTitle      *bool     `json:"show_title"` // 未提供时默认显示标题
 	IsScheduled    bool      `json:"is_scheduled"`
 	PublishedAt    *time.Time `json:"published_at"`
 	Categories     []string  `json:"categories"`
@@ -82,6 +82,16 @@ var (
 		message:    "此文章为私密文章，仅管理员可见",
 		httpStatus: http.StatusForbidden,
 	}
+	ErrPassageProtected = &BaseError{
+		code:       "PASSAGE_PROTECTED",
+		message:    "此文章受密码保护，请输入密码后阅读",
+		httpStatus: http.StatusLocked,
+	}
+	ErrPassagePasswordIncorrect = &BaseError{
+		code:       "PASSAGE_PASSWORD_INCORRECT",
+		message:    "文章密码错误",
+		httpStatus: http.StatusForbidden,
+	}
 
 	// 文件相关错误
 	ErrFileTooLarge = &BaseError{
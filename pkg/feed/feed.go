@@ -0,0 +1,331 @@
+// Package feed 生成 RSS 2.0、Atom 1.0 与 JSON Feed 1.1 格式的订阅源
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Feed 订阅源，所有链接都应为绝对地址
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // 站点（或分类、标签页面）地址
+	FeedURL     string // 订阅源自身地址
+	Language    string
+	Author      string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item 订阅源条目
+type Item struct {
+	ID         string // 唯一标识，为空时使用 Link
+	Title      string
+	Link       string
+	Summary    string // 纯文本摘要
+	Content    string // HTML 全文，为空时只输出摘要
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+func (item *Item) id() string {
+	if item.ID != "" {
+		return item.ID
+	}
+	return item.Link
+}
+
+func (item *Item) updated() time.Time {
+	if item.Updated.IsZero() {
+		return item.Published
+	}
+	return item.Updated
+}
+
+// LastUpdated 返回订阅源的最后更新时间，未设置时取条目中最新的更新时间
+func (f *Feed) LastUpdated() time.Time {
+	updated := f.Updated
+	for i := range f.Items {
+		if t := f.Items[i].updated(); t.After(updated) {
+			updated = t
+		}
+	}
+	return updated
+}
+
+// rss RSS 2.0 文档结构
+type rss struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	AtomNS       string     `xml:"xmlns:atom,attr"`
+	ContentNS    string     `xml:"xmlns:content,attr"`
+	DublinCoreNS string     `xml:"xmlns:dc,attr"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"content:encoded,omitempty"`
+}
+
+// RSS 生成 RSS 2.0 文档，全文放在 content:encoded 中，description 始终为摘要
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rss{
+		Version:      "2.0",
+		AtomNS:       "http://www.w3.org/2005/Atom",
+		ContentNS:    "http://purl.org/rss/1.0/modules/content/",
+		DublinCoreNS: "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			AtomLink:    rssLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Language:    f.Language,
+			Generator:   "myblog-gogogo",
+		},
+	}
+	if updated := f.LastUpdated(); !updated.IsZero() {
+		doc.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+
+	for i := range f.Items {
+		item := &f.Items[i]
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: item.ID == "" || item.ID == item.Link, Value: item.id()},
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			Description: item.Summary,
+			Content:     item.Content,
+		}
+		if !item.Published.IsZero() {
+			entry.PubDate = item.Published.Format(time.RFC1123Z)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+// atom Atom 1.0 文档结构
+type atom struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomPerson `xml:"author,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+// Atom 生成 Atom 1.0 文档
+func (f *Feed) Atom() ([]byte, error) {
+	// Atom 要求 updated 必填，没有任何条目时使用当前时间
+	updated := f.LastUpdated()
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	doc := atom{
+		ID:       f.Link,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+	if f.Author != "" {
+		doc.Author = &atomPerson{Name: f.Author}
+	}
+
+	for i := range f.Items {
+		item := &f.Items[i]
+		entry := atomEntry{
+			ID:      item.id(),
+			Title:   item.Title,
+			Link:    atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+			Updated: item.updated().Format(time.RFC3339),
+		}
+		if !item.Published.IsZero() {
+			entry.Published = item.Published.Format(time.RFC3339)
+		}
+		if item.Author != "" {
+			entry.Author = &atomPerson{Name: item.Author}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+// jsonFeed JSON Feed 1.1 文档结构
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Authors     []jsonAuthor   `json:"authors,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	ContentHTML   string       `json:"content_html,omitempty"`
+	ContentText   string       `json:"content_text,omitempty"`
+	Summary       string       `json:"summary,omitempty"`
+	DatePublished string       `json:"date_published,omitempty"`
+	DateModified  string       `json:"date_modified,omitempty"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+// JSON 生成 JSON Feed 1.1 文档
+func (f *Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Language:    f.Language,
+		Items:       make([]jsonFeedItem, 0, len(f.Items)),
+	}
+	if f.Author != "" {
+		doc.Authors = []jsonAuthor{{Name: f.Author}}
+	}
+
+	for i := range f.Items {
+		item := &f.Items[i]
+		entry := jsonFeedItem{
+			ID:      item.id(),
+			URL:     item.Link,
+			Title:   item.Title,
+			Summary: item.Summary,
+			Tags:    item.Categories,
+		}
+		// content_html 与 content_text 至少需要一个
+		if item.Content != "" {
+			entry.ContentHTML = item.Content
+		} else {
+			entry.ContentText = item.Summary
+		}
+		if !item.Published.IsZero() {
+			entry.DatePublished = item.Published.Format(time.RFC3339)
+		}
+		if updated := item.updated(); !updated.IsZero() {
+			entry.DateModified = updated.Format(time.RFC3339)
+		}
+		if item.Author != "" {
+			entry.Authors = []jsonAuthor{{Name: item.Author}}
+		}
+		doc.Items = append(doc.Items, entry)
+	}
+
+	// 标题和正文中的 HTML 字符保持原样，不转义为 \u003c 等形式
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalXML 序列化 XML 文档并加上声明头
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,863 @@
+// Package frontmatter 解析与生成 markdown 文件头部的 YAML (---) / TOML (+++) 元数据
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"myblog-gogogo/db/models"
+)
+
+// Format 头部元数据格式
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// 头部分隔符
+const (
+	yamlDelimiter = "---"
+	tomlDelimiter = "+++"
+)
+
+// FrontMatter markdown 文件头部元数据
+// 指针字段为 nil 表示文件中未设置，应用到文章时保留原值
+type FrontMatter struct {
+	Format      Format     `json:"format"`
+	Title       string     `json:"title,omitempty"`
+	Slug        string     `json:"slug,omitempty"`
+	Summary     string     `json:"summary,omitempty"`
+	Category    string     `json:"category,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Visibility  string     `json:"visibility,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	ShowTitle   *bool      `json:"show_title,omitempty"`
+	IsScheduled *bool      `json:"is_scheduled,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Date        *time.Time `json:"date,omitempty"`        // 文章创建日期
+	TOCDepth    *int       `json:"toc_depth,omitempty"`   // 目录包含的标题层数
+	Series      string     `json:"series,omitempty"`      // 所属系列的名称
+	SeriesPart  *int       `json:"series_part,omitempty"` // 在系列中的顺序
+	// 分享与搜索引擎使用的元数据，留空时使用标题、摘要与正文第一张图片
+	MetaTitle       string `json:"meta_title,omitempty"`
+	MetaDescription string `json:"meta_description,omitempty"`
+	Image           string `json:"image,omitempty"`
+	// Unknown 文件中出现但没有对应字段的键，按名称排序，不会写回文件
+	Unknown []string `json:"-"`
+}
+
+// 支持的时间格式（无时区的格式按本地时区解析）
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Parse 解析 markdown 内容开头的头部元数据
+// 返回元数据和去掉头部后的正文；没有头部时返回 nil 和原内容
+func Parse(content []byte) (*FrontMatter, []byte, error) {
+	// 去掉 UTF-8 BOM
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	var format Format
+	var delimiter string
+	switch {
+	case hasDelimiterLine(content, yamlDelimiter):
+		format, delimiter = FormatYAML, yamlDelimiter
+	case hasDelimiterLine(content, tomlDelimiter):
+		format, delimiter = FormatTOML, tomlDelimiter
+	default:
+		return nil, content, nil
+	}
+
+	// 查找结束分隔符
+	lines := strings.SplitAfter(string(content), "\n")
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r\n \t") == delimiter {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		// 没有结束分隔符，视为普通的分隔线而不是头部
+		return nil, content, nil
+	}
+
+	header := make([]string, 0, end-1)
+	for _, line := range lines[1:end] {
+		header = append(header, strings.TrimRight(line, "\r\n"))
+	}
+
+	if !startsWithKey(header, format) {
+		// 分隔线之后不是键值对，说明文件以分隔线开头而不是头部，按普通正文处理
+		return nil, content, nil
+	}
+
+	values, err := parseValues(header, format)
+	if err != nil {
+		return nil, content, err
+	}
+
+	fm := &FrontMatter{Format: format}
+	if err := fm.assign(values); err != nil {
+		return nil, content, err
+	}
+
+	body := strings.Join(lines[end+1:], "")
+	// 去掉头部与正文之间的空行
+	body = strings.TrimLeft(body, "\r\n")
+	return fm, []byte(body), nil
+}
+
+// hasDelimiterLine 判断内容第一行是否为指定分隔符
+func hasDelimiterLine(content []byte, delimiter string) bool {
+	if !bytes.HasPrefix(content, []byte(delimiter)) {
+		return false
+	}
+	rest := content[len(delimiter):]
+	rest = bytes.TrimLeft(rest, " \t")
+	return len(rest) == 0 || rest[0] == '\n' || rest[0] == '\r'
+}
+
+// startsWithKey 判断头部第一个非空、非注释的行是否为键值对
+func startsWithKey(lines []string, format Format) bool {
+	sep := ":"
+	if format == FormatTOML {
+		sep = "="
+	}
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if format == FormatTOML && strings.HasPrefix(line, "[") {
+			return true
+		}
+		idx := strings.Index(line, sep)
+		return idx > 0 && validKey(strings.TrimSpace(line[:idx]))
+	}
+	return true
+}
+
+// parseValues 将头部行解析为键值对，值为 string 或 []string
+// YAML 中缩进的子键以 "父键.子键" 保存，与 TOML 表中的键一致，不会覆盖同名的顶层键
+func parseValues(lines []string, format Format) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	table := ""
+	var listKey string
+	// parents 当前行所属的 YAML 父键，按缩进由浅到深排列
+	type parent struct {
+		indent int
+		key    string
+	}
+	var parents []parent
+
+	for i := 0; i < len(lines); i++ {
+		raw := lines[i]
+		lineNo := i + 2 // 第 1 行是分隔符
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		indent := indentOf(raw)
+
+		// YAML 块列表项: "- value"
+		if format == FormatYAML && (strings.HasPrefix(line, "- ") || line == "-") {
+			if listKey == "" {
+				return nil, fmt.Errorf("front matter line %d: list item without key", lineNo)
+			}
+			item := unquote(stripComment(strings.TrimSpace(strings.TrimPrefix(line, "-"))))
+			list, _ := values[listKey].([]string)
+			values[listKey] = append(list, item)
+			continue
+		}
+
+		// TOML 表头: "[params]"
+		if format == FormatTOML && strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			table = strings.Trim(line, "[] ") + "."
+			continue
+		}
+
+		sep := ":"
+		if format == FormatTOML {
+			sep = "="
+		}
+		idx := strings.Index(line, sep)
+		if idx <= 0 {
+			return nil, fmt.Errorf("front matter line %d: expected \"key%s value\"", lineNo, sep)
+		}
+
+		name := strings.TrimSpace(line[:idx])
+		if !validKey(name) {
+			return nil, fmt.Errorf("front matter line %d: invalid key %q", lineNo, name)
+		}
+		key := strings.Trim(strings.ToLower(name), `"'`)
+		if format == FormatTOML {
+			key = table + key
+		} else {
+			for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+				parents = parents[:len(parents)-1]
+			}
+			if len(parents) > 0 {
+				// 父键下有子键，父键本身不是单个值
+				parentKey := parents[len(parents)-1].key
+				delete(values, parentKey)
+				key = parentKey + "." + key
+			}
+		}
+		value := stripComment(strings.TrimSpace(line[idx+1:]))
+		if strings.HasPrefix(value, "[") && !strings.HasSuffix(value, "]") {
+			// 跨行的数组，读取到以 ] 结尾的行
+			for i++; ; i++ {
+				if i >= len(lines) {
+					return nil, fmt.Errorf("front matter line %d: unterminated array", lineNo)
+				}
+				value += " " + stripComment(strings.TrimSpace(lines[i]))
+				if strings.HasSuffix(value, "]") {
+					break
+				}
+			}
+		}
+
+		listKey = ""
+		switch {
+		case value == "":
+			// YAML 中空值后可能跟随块列表或缩进的子键
+			listKey = key
+			values[key] = ""
+			if format == FormatYAML {
+				parents = append(parents, parent{indent: indent, key: key})
+			}
+		case format == FormatYAML && isBlockScalar(value):
+			text, next := parseBlockScalar(lines, i+1, indent, value)
+			values[key] = text
+			i = next - 1
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			values[key] = splitInlineList(value[1 : len(value)-1])
+		default:
+			values[key] = unquote(value)
+		}
+	}
+
+	return values, nil
+}
+
+// indentOf 返回行首空白的宽度
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// validKey 判断键名是否有效：带引号的键，或只包含字母、数字、下划线、连字符与点的键
+// 用于区分头部与以分隔线开头的普通正文
+func validKey(name string) bool {
+	if len(name) >= 2 && (name[0] == '"' || name[0] == '\'') && name[len(name)-1] == name[0] {
+		return true
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// isBlockScalar 判断值是否为 YAML 块标量的开头: | 保留换行，> 折叠换行，可带 +/- 与缩进数字
+func isBlockScalar(value string) bool {
+	if value[0] != '|' && value[0] != '>' {
+		return false
+	}
+	for _, r := range value[1:] {
+		if r != '+' && r != '-' && (r < '1' || r > '9') {
+			return false
+		}
+	}
+	return len(value) <= 3
+}
+
+// parseBlockScalar 读取从 start 行开始、比键缩进更深的块标量内容，返回文本与之后第一行的下标
+// 头部中的值都是单个字段，默认与 "-" 一样去掉结尾换行，只有 "+" 保留
+func parseBlockScalar(lines []string, start, keyIndent int, header string) (string, int) {
+	folded := header[0] == '>'
+	keep := strings.Contains(header, "+")
+	blockIndent := -1
+	if idx := strings.IndexAny(header, "123456789"); idx >= 0 {
+		blockIndent = keyIndent + int(header[idx]-'0')
+	}
+
+	end := start
+	var block []string
+	for ; end < len(lines); end++ {
+		raw := strings.TrimRight(lines[end], " \t")
+		if raw == "" {
+			block = append(block, "")
+			continue
+		}
+		indent := indentOf(raw)
+		if blockIndent < 0 {
+			blockIndent = indent
+		}
+		if indent <= keyIndent || indent < blockIndent {
+			break
+		}
+		block = append(block, raw[blockIndent:])
+	}
+	trailing := 0
+	for len(block) > 0 && block[len(block)-1] == "" {
+		block = block[:len(block)-1]
+		trailing++
+	}
+	if len(block) == 0 {
+		return "", end
+	}
+
+	text := strings.Join(block, "\n")
+	if folded {
+		text = foldLines(block)
+	}
+	if keep {
+		text += "\n" + strings.Repeat("\n", trailing)
+	}
+	return text, end
+}
+
+// foldLines 按 YAML 折叠块的规则连接各行：相邻的普通行以空格连接，空行保留为换行，更深缩进的行保持原样
+func foldLines(block []string) string {
+	const (
+		start = iota
+		text
+		empty
+		indented
+	)
+	var buf strings.Builder
+	prev := start
+	for _, line := range block {
+		switch {
+		case line == "":
+			buf.WriteString("\n")
+			prev = empty
+		case line[0] == ' ' || line[0] == '\t':
+			if prev == text || prev == indented {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(line)
+			prev = indented
+		default:
+			if prev == text {
+				buf.WriteString(" ")
+			} else if prev == indented {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(line)
+			prev = text
+		}
+	}
+	return buf.String()
+}
+
+// stripComment 移除未被引号包裹的行尾注释
+func stripComment(value string) string {
+	var quote rune
+	for i, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote && (i == 0 || value[i-1] != '\\') {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t'):
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// splitInlineList 拆分行内数组 a, "b", 'c'
+func splitInlineList(inner string) []string {
+	items := []string{}
+	var current strings.Builder
+	var quote rune
+	for _, r := range inner {
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			current.WriteRune(r)
+		case r == ',':
+			if item := unquote(strings.TrimSpace(current.String())); item != "" {
+				items = append(items, item)
+			}
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if item := unquote(strings.TrimSpace(current.String())); item != "" {
+		items = append(items, item)
+	}
+	return items
+}
+
+// unquote 去掉字符串两端的引号
+func unquote(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			if s, err := strconv.Unquote(value); err == nil {
+				return s
+			}
+			return value[1 : len(value)-1]
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+	return value
+}
+
+// assign 将键值对映射到结构体字段
+func (fm *FrontMatter) assign(values map[string]interface{}) error {
+	for key, raw := range values {
+		str, list := asString(raw), asList(raw)
+
+		switch key {
+		case "title":
+			fm.Title = str
+		case "slug":
+			fm.Slug = str
+		case "summary", "description":
+			if fm.Summary == "" || key == "summary" {
+				fm.Summary = str
+			}
+		case "category":
+			fm.Category = str
+		case "categories":
+			// 博客只支持单一分类，取第一个
+			if fm.Category == "" && len(list) > 0 {
+				fm.Category = list[0]
+			}
+		case "tags", "tag":
+			fm.Tags = list
+		case "status":
+			fm.Status = strings.ToLower(str)
+		case "draft":
+			draft, err := parseBool(str)
+			if err != nil {
+				return fmt.Errorf("front matter draft: %w", err)
+			}
+			if draft && fm.Status == "" {
+				fm.Status = "draft"
+			}
+		case "published":
+			// Jekyll、Hexo 使用 published: false 表示不发布
+			published, err := parseBool(str)
+			if err != nil {
+				return fmt.Errorf("front matter published: %w", err)
+			}
+			if !published && fm.Status == "" {
+				fm.Status = "draft"
+			}
+		case "visibility":
+			fm.Visibility = strings.ToLower(str)
+		case "author":
+			fm.Author = str
+		case "show_title":
+			v, err := parseBool(str)
+			if err != nil {
+				return fmt.Errorf("front matter show_title: %w", err)
+			}
+			fm.ShowTitle = &v
+		case "is_scheduled":
+			v, err := parseBool(str)
+			if err != nil {
+				return fmt.Errorf("front matter is_scheduled: %w", err)
+			}
+			fm.IsScheduled = &v
+		case "published_at":
+			if str == "" {
+				continue
+			}
+			t, err := ParseTime(str)
+			if err != nil {
+				return fmt.Errorf("front matter published_at: %w", err)
+			}
+			fm.PublishedAt = &t
+		case "date":
+			if str == "" {
+				continue
+			}
+			t, err := ParseTime(str)
+			if err != nil {
+				return fmt.Errorf("front matter date: %w", err)
+			}
+			fm.Date = &t
+		case "toc_depth":
+			if str == "" {
+				continue
+			}
+			depth, err := strconv.Atoi(str)
+			if err != nil || depth < 1 {
+				return fmt.Errorf("front matter toc_depth: invalid depth %q", str)
+			}
+			fm.TOCDepth = &depth
+		case "series":
+			// Hugo 等把系列写成数组，只取第一个
+			fm.Series = str
+			if items, ok := raw.([]string); ok && len(items) > 0 {
+				fm.Series = items[0]
+			}
+		case "series_part":
+			if str == "" {
+				continue
+			}
+			part, err := strconv.Atoi(str)
+			if err != nil || part < 1 {
+				return fmt.Errorf("front matter series_part: invalid part %q", str)
+			}
+			fm.SeriesPart = &part
+		case "meta_title":
+			fm.MetaTitle = str
+		case "meta_description":
+			fm.MetaDescription = str
+		case "image", "cover", "cover_image", "images":
+			// images 为数组时只取第一张
+			if fm.Image == "" || key == "image" {
+				fm.Image = str
+				if items, ok := raw.([]string); ok && len(items) > 0 {
+					fm.Image = items[0]
+				}
+			}
+		default:
+			fm.Unknown = append(fm.Unknown, key)
+		}
+	}
+	sort.Strings(fm.Unknown)
+	return nil
+}
+
+// asString 将值转换为字符串，数组取逗号连接
+func asString(raw interface{}) string {
+	switch v := raw.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return ""
+}
+
+// asList 将值转换为字符串数组，单个字符串按逗号拆分
+func asList(raw interface{}) []string {
+	switch v := raw.(type) {
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+// parseBool 解析布尔值（兼容 yes/no/on/off）
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+// ParseTime 解析头部中的时间字符串
+func ParseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if layout == time.RFC3339 {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+			continue
+		}
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// TagString 返回逗号分隔的标签字符串，与上传接口的 tags 参数格式一致
+func (fm *FrontMatter) TagString() string {
+	if fm == nil {
+		return ""
+	}
+	return strings.Join(fm.Tags, ",")
+}
+
+// ApplyTo 将头部中设置过的字段应用到文章
+func (fm *FrontMatter) ApplyTo(passage *models.Passage) {
+	if fm == nil || passage == nil {
+		return
+	}
+
+	if fm.Title != "" {
+		passage.Title = fm.Title
+	}
+	if fm.Slug != "" {
+		passage.Slug = fm.Slug
+	}
+	if fm.Summary != "" {
+		passage.Summary = fm.Summary
+	}
+	if fm.Category != "" {
+		passage.Category = fm.Category
+	}
+	if visibility := fm.visibilityFor(passage); visibility != "" {
+		passage.Visibility = visibility
+	}
+	if fm.Author != "" {
+		passage.Author = fm.Author
+	}
+	if fm.ShowTitle != nil {
+		passage.ShowTitle = *fm.ShowTitle
+	}
+	if fm.IsScheduled != nil {
+		passage.IsScheduled = *fm.IsScheduled
+	}
+	if fm.PublishedAt != nil {
+		passage.PublishedAt = *fm.PublishedAt
+	}
+	if fm.Date != nil {
+		passage.CreatedAt = *fm.Date
+	}
+	if fm.TOCDepth != nil {
+		passage.TOCDepth = *fm.TOCDepth
+	}
+
+	if fm.Status != "" {
+		passage.Status = fm.Status
+	} else if passage.IsScheduled && passage.PublishedAt.After(time.Now()) {
+		// 定时发布且未到发布时间，等待调度发布
+		passage.Status = "pending"
+	}
+}
+
+// visibilityFor 返回头部可见性应用到文章后的值，返回空字符串表示保留文章原值
+// 未知的可见性被忽略；头部无法携带密码，protected 只能保留已受保护文章的设置，其余文章改为 private，避免未设置密码的文章被公开
+func (fm *FrontMatter) visibilityFor(passage *models.Passage) string {
+	switch fm.Visibility {
+	case "public", "private":
+		return fm.Visibility
+	case "protected":
+		if passage.Visibility == "protected" {
+			return ""
+		}
+		return "private"
+	}
+	return ""
+}
+
+// FromPassage 根据文章字段生成头部元数据，用于保存时回写到文件
+func FromPassage(passage *models.Passage, tags []string, format Format) *FrontMatter {
+	if format == "" {
+		format = FormatYAML
+	}
+
+	showTitle := passage.ShowTitle
+	isScheduled := passage.IsScheduled
+	fm := &FrontMatter{
+		Format:      format,
+		Title:       passage.Title,
+		Slug:        passage.Slug,
+		Summary:     passage.Summary,
+		Category:    passage.Category,
+		Tags:        tags,
+		Status:      passage.Status,
+		Visibility:  passage.Visibility,
+		Author:      passage.Author,
+		ShowTitle:   &showTitle,
+		IsScheduled: &isScheduled,
+	}
+	if !passage.PublishedAt.IsZero() {
+		publishedAt := passage.PublishedAt
+		fm.PublishedAt = &publishedAt
+	}
+	if !passage.CreatedAt.IsZero() {
+		date := passage.CreatedAt
+		fm.Date = &date
+	}
+	if passage.TOCDepth > 0 {
+		depth := passage.TOCDepth
+		fm.TOCDepth = &depth
+	}
+	return fm
+}
+
+// Update 用文章字段更新已有头部，只改写头部中原本存在的键，保持文件简洁
+// 与默认值不同的状态、可见性和定时设置总会写入，避免再次同步文件时被默认值覆盖
+func (fm *FrontMatter) Update(passage *models.Passage, tags []string) {
+	if fm == nil || passage == nil {
+		return
+	}
+
+	if fm.Title != "" {
+		fm.Title = passage.Title
+	}
+	if fm.Slug != "" || passage.Slug != "" {
+		fm.Slug = passage.Slug
+	}
+	if fm.Summary != "" {
+		fm.Summary = passage.Summary
+	}
+	if fm.Category != "" {
+		fm.Category = passage.Category
+	}
+	if len(fm.Tags) > 0 || len(tags) > 0 {
+		fm.Tags = tags
+	}
+	if fm.Author != "" {
+		fm.Author = passage.Author
+	}
+	if fm.Status != "" || passage.Status != "published" {
+		fm.Status = passage.Status
+	}
+	if fm.Visibility != "" || passage.Visibility != "public" {
+		fm.Visibility = passage.Visibility
+	}
+	if fm.ShowTitle != nil || !passage.ShowTitle {
+		showTitle := passage.ShowTitle
+		fm.ShowTitle = &showTitle
+	}
+	if fm.IsScheduled != nil || passage.IsScheduled {
+		isScheduled := passage.IsScheduled
+		fm.IsScheduled = &isScheduled
+	}
+	if (fm.PublishedAt != nil || passage.IsScheduled) && !passage.PublishedAt.IsZero() {
+		publishedAt := passage.PublishedAt
+		fm.PublishedAt = &publishedAt
+	}
+	if fm.Date != nil && !passage.CreatedAt.IsZero() {
+		date := passage.CreatedAt
+		fm.Date = &date
+	}
+	if passage.TOCDepth > 0 {
+		depth := passage.TOCDepth
+		fm.TOCDepth = &depth
+	}
+}
+
+// UpdateSeries 更新头部中的系列，头部原本没有系列且文章不属于任何系列时不写入
+// part <= 0 时去掉顺序，由系列中的位置决定
+func (fm *FrontMatter) UpdateSeries(name string, part int) {
+	if fm == nil || (fm.Series == "" && name == "") {
+		return
+	}
+	fm.Series = name
+	fm.SeriesPart = nil
+	if name != "" && part > 0 {
+		fm.SeriesPart = &part
+	}
+}
+
+// UpdateMeta 更新头部中的分享元数据，为空的字段不写入
+func (fm *FrontMatter) UpdateMeta(title, description, image string) {
+	if fm == nil {
+		return
+	}
+	fm.MetaTitle = title
+	fm.MetaDescription = description
+	fm.Image = image
+}
+
+// Marshal 生成包含分隔符的头部文本
+func (fm *FrontMatter) Marshal() []byte {
+	if fm == nil {
+		return nil
+	}
+
+	delimiter, sep := yamlDelimiter, ": "
+	if fm.Format == FormatTOML {
+		delimiter, sep = tomlDelimiter, " = "
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(delimiter + "\n")
+
+	writeString := func(key, value string) {
+		if value != "" {
+			buf.WriteString(key + sep + strconv.Quote(value) + "\n")
+		}
+	}
+	writeBool := func(key string, value *bool) {
+		if value != nil {
+			buf.WriteString(key + sep + strconv.FormatBool(*value) + "\n")
+		}
+	}
+
+	writeString("title", fm.Title)
+	writeString("slug", fm.Slug)
+	writeString("author", fm.Author)
+	writeString("summary", fm.Summary)
+	writeString("category", fm.Category)
+	if len(fm.Tags) > 0 {
+		quoted := make([]string, len(fm.Tags))
+		for i, tag := range fm.Tags {
+			quoted[i] = strconv.Quote(tag)
+		}
+		buf.WriteString("tags" + sep + "[" + strings.Join(quoted, ", ") + "]\n")
+	}
+	writeString("series", fm.Series)
+	if fm.SeriesPart != nil {
+		buf.WriteString("series_part" + sep + strconv.Itoa(*fm.SeriesPart) + "\n")
+	}
+	writeString("meta_title", fm.MetaTitle)
+	writeString("meta_description", fm.MetaDescription)
+	writeString("image", fm.Image)
+	writeString("status", fm.Status)
+	writeString("visibility", fm.Visibility)
+	writeBool("show_title", fm.ShowTitle)
+	writeBool("is_scheduled", fm.IsScheduled)
+	if fm.TOCDepth != nil {
+		buf.WriteString("toc_depth" + sep + strconv.Itoa(*fm.TOCDepth) + "\n")
+	}
+	writeTime := func(key string, value *time.Time) {
+		if value == nil {
+			return
+		}
+		formatted := value.Format(time.RFC3339)
+		if fm.Format == FormatTOML {
+			// TOML 原生支持 RFC3339 日期时间
+			buf.WriteString(key + sep + formatted + "\n")
+		} else {
+			buf.WriteString(key + sep + strconv.Quote(formatted) + "\n")
+		}
+	}
+	writeTime("date", fm.Date)
+	writeTime("published_at", fm.PublishedAt)
+
+	buf.WriteString(delimiter + "\n")
+	return buf.Bytes()
+}
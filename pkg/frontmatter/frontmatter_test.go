@@ -0,0 +1,335 @@
+package frontmatter
+
+import (
+	"strings"
+	"testing"
+
+	"myblog-gogogo/db/models"
+)
+
+// TestParseYAML 测试 YAML 头部解析
+func TestParseYAML(t *testing.T) {
+	content := "---\n" +
+		"title: \"Hello: World\"\n" +
+		"slug: hello-world\n" +
+		"category: 技术\n" +
+		"tags:\n" +
+		"  - go\n" +
+		"  - \"博客\"\n" +
+		"draft: true  # 草稿\n" +
+		"published_at: 2024-05-01 08:00\n" +
+		"---\n\n" +
+		"# Hello\n\n正文\n"
+
+	fm, body, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if fm == nil {
+		t.Fatal("Parse() returned nil front matter")
+	}
+	if fm.Format != FormatYAML {
+		t.Errorf("Format = %q, want %q", fm.Format, FormatYAML)
+	}
+	if fm.Title != "Hello: World" {
+		t.Errorf("Title = %q", fm.Title)
+	}
+	if fm.TagString() != "go,博客" {
+		t.Errorf("TagString() = %q", fm.TagString())
+	}
+	if fm.Status != "draft" {
+		t.Errorf("Status = %q, want draft", fm.Status)
+	}
+	if fm.PublishedAt == nil || fm.PublishedAt.Hour() != 8 {
+		t.Errorf("PublishedAt = %v", fm.PublishedAt)
+	}
+	if string(body) != "# Hello\n\n正文\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+// TestParseTOML 测试 TOML 头部解析
+func TestParseTOML(t *testing.T) {
+	content := "+++\n" +
+		"title = 'It''s TOML'\n" +
+		"tags = [\"a\", \"b, c\"]\n" +
+		"show_title = false\n" +
+		"+++\n" +
+		"body"
+
+	fm, body, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if fm.Format != FormatTOML || fm.Title != "It's TOML" {
+		t.Errorf("unexpected front matter: %+v", fm)
+	}
+	if len(fm.Tags) != 2 || fm.Tags[1] != "b, c" {
+		t.Errorf("Tags = %v", fm.Tags)
+	}
+	if fm.ShowTitle == nil || *fm.ShowTitle {
+		t.Errorf("ShowTitle = %v, want false", fm.ShowTitle)
+	}
+	if string(body) != "body" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+// TestParseWithoutFrontMatter 测试没有头部或头部未闭合的内容
+func TestParseWithoutFrontMatter(t *testing.T) {
+	tests := []string{
+		"# 标题\n\n正文",
+		"---\n没有结束分隔符",
+		"",
+		"---\nnot a pair\n---\n",
+		"---\n\n以分隔线开头的段落\n\n---\n\n正文",
+		"---\nNote that: 这是正文\n---\n",
+	}
+	for _, content := range tests {
+		fm, body, err := Parse([]byte(content))
+		if err != nil || fm != nil || string(body) != content {
+			t.Errorf("Parse(%q) = %v, %q, %v", content, fm, body, err)
+		}
+	}
+}
+
+// TestParseUnknownKeys 测试记录无法映射的键与带时区的日期
+func TestParseUnknownKeys(t *testing.T) {
+	content := "---\n" +
+		"title: Jekyll\n" +
+		"layout: post\n" +
+		"date: 2020-03-04 10:20:30 +0800\n" +
+		"permalink: /old/url/\n" +
+		"---\n" +
+		"body"
+
+	fm, _, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := strings.Join(fm.Unknown, ","); got != "layout,permalink" {
+		t.Errorf("Unknown = %q, want layout,permalink", got)
+	}
+	if fm.Date == nil || fm.Date.UTC().Hour() != 2 {
+		t.Errorf("Date = %v", fm.Date)
+	}
+	if strings.Contains(string(fm.Marshal()), "layout") {
+		t.Error("Marshal() should not write unknown keys")
+	}
+}
+
+// TestParseInvalid 测试无效的头部内容
+func TestParseInvalid(t *testing.T) {
+	if _, _, err := Parse([]byte("---\npublished_at: tomorrow\n---\n")); err == nil {
+		t.Error("expected error for invalid time")
+	}
+	// 以键值对开头的头部中出现无法解析的行时返回错误，而不是把头部当作正文
+	if _, _, err := Parse([]byte("---\ntitle: x\nnot a pair\n---\n")); err == nil {
+		t.Error("expected error for line without separator")
+	}
+	if _, _, err := Parse([]byte("+++\ntitle = 'x'\ntags = [\n  \"a\",\n+++\n")); err == nil {
+		t.Error("expected error for unterminated array")
+	}
+}
+
+// TestParseMultilineArray 测试跨行的数组
+func TestParseMultilineArray(t *testing.T) {
+	tests := []string{
+		"+++\ntitle = 'x'\ntags = [\n  \"a\",  # 第一个\n  \"b, c\",\n]\nslug = \"s\"\n+++\nbody",
+		"---\ntitle: x\ntags: [\n  a,\n  \"b, c\"\n]\nslug: s\n---\nbody",
+	}
+	for _, content := range tests {
+		fm, body, err := Parse([]byte(content))
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", content, err)
+		}
+		if len(fm.Tags) != 2 || fm.Tags[0] != "a" || fm.Tags[1] != "b, c" {
+			t.Errorf("Tags = %q", fm.Tags)
+		}
+		if fm.Slug != "s" || string(body) != "body" {
+			t.Errorf("Slug = %q, body = %q", fm.Slug, body)
+		}
+	}
+}
+
+// TestParseNested 测试缩进的子键不会覆盖同名的顶层键
+func TestParseNested(t *testing.T) {
+	content := "---\n" +
+		"image: /top.png\n" +
+		"cover:\n" +
+		"  image: /cover.png\n" +
+		"  alt: 封面\n" +
+		"params:\n" +
+		"  title: nested\n" +
+		"title: 顶层\n" +
+		"---\n"
+
+	fm, _, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if fm.Image != "/top.png" {
+		t.Errorf("Image = %q, want /top.png", fm.Image)
+	}
+	if fm.Title != "顶层" {
+		t.Errorf("Title = %q, want 顶层", fm.Title)
+	}
+	if got := strings.Join(fm.Unknown, ","); got != "cover.alt,cover.image,params.title" {
+		t.Errorf("Unknown = %q", got)
+	}
+}
+
+// TestParseBlockScalar 测试 | 与 > 块标量
+func TestParseBlockScalar(t *testing.T) {
+	content := "---\n" +
+		"summary: |\n" +
+		"  第一行\n" +
+		"  第二行\n" +
+		"\n" +
+		"meta_description: >-\n" +
+		"  folded\n" +
+		"  text\n" +
+		"\n" +
+		"  next paragraph\n" +
+		"title: >+\n" +
+		"  kept\n" +
+		"slug: after\n" +
+		"---\n"
+
+	fm, _, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if fm.Summary != "第一行\n第二行" {
+		t.Errorf("Summary = %q", fm.Summary)
+	}
+	if fm.MetaDescription != "folded text\nnext paragraph" {
+		t.Errorf("MetaDescription = %q", fm.MetaDescription)
+	}
+	if fm.Title != "kept\n" {
+		t.Errorf("Title = %q", fm.Title)
+	}
+	if fm.Slug != "after" {
+		t.Errorf("Slug = %q, want after", fm.Slug)
+	}
+}
+
+// TestApplyVisibility 测试头部中的可见性只接受已知的值
+func TestApplyVisibility(t *testing.T) {
+	tests := []struct {
+		header  string
+		current string
+		want    string
+	}{
+		{"private", "public", "private"},
+		{"PUBLIC", "private", "public"},
+		{"secret", "private", "private"},
+		{"protected", "public", "private"},
+		{"protected", "protected", "protected"},
+		{"public", "protected", "public"},
+	}
+	for _, tt := range tests {
+		fm, _, err := Parse([]byte("---\nvisibility: " + tt.header + "\n---\n"))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		passage := &models.Passage{Visibility: tt.current}
+		fm.ApplyTo(passage)
+		if passage.Visibility != tt.want {
+			t.Errorf("visibility %q on %q = %q, want %q", tt.header, tt.current, passage.Visibility, tt.want)
+		}
+	}
+}
+
+// TestMarshalRoundTrip 测试生成的头部可以被重新解析
+func TestMarshalRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatYAML, FormatTOML} {
+		passage := &models.Passage{
+			Title:      "标题 \"引号\"",
+			Slug:       "title",
+			Category:   "随笔",
+			Status:     "published",
+			Visibility: "public",
+			ShowTitle:  true,
+		}
+		header := FromPassage(passage, []string{"x", "y"}, format).Marshal()
+
+		fm, body, err := Parse(append(header, []byte("\n正文")...))
+		if err != nil {
+			t.Fatalf("%s: Parse() error = %v", format, err)
+		}
+
+		var restored models.Passage
+		fm.ApplyTo(&restored)
+		if restored.Title != passage.Title || restored.Slug != passage.Slug ||
+			restored.Category != passage.Category || !restored.ShowTitle {
+			t.Errorf("%s: restored = %+v", format, restored)
+		}
+		if fm.TagString() != "x,y" {
+			t.Errorf("%s: TagString() = %q", format, fm.TagString())
+		}
+		if string(body) != "正文" {
+			t.Errorf("%s: body = %q", format, body)
+		}
+	}
+}
+
+// TestSeries 测试系列字段的解析与写回
+func TestSeries(t *testing.T) {
+	content := "---\ntitle: 第二篇\nseries: [\"Go 入门\", \"其他\"]\nseries_part: 2\n---\n正文"
+	fm, _, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if fm.Series != "Go 入门" || fm.SeriesPart == nil || *fm.SeriesPart != 2 {
+		t.Fatalf("Series = %q, SeriesPart = %v", fm.Series, fm.SeriesPart)
+	}
+
+	fm.UpdateSeries("Go 进阶", 0)
+	restored, _, err := Parse(fm.Marshal())
+	if err != nil {
+		t.Fatalf("Parse(Marshal()) error = %v", err)
+	}
+	if restored.Series != "Go 进阶" || restored.SeriesPart != nil {
+		t.Errorf("restored Series = %q, SeriesPart = %v", restored.Series, restored.SeriesPart)
+	}
+
+	if _, _, err := Parse([]byte("---\nseries_part: 0\n---\n")); err == nil {
+		t.Error("Parse() with series_part 0 should fail")
+	}
+
+	plain := &FrontMatter{Title: "x"}
+	plain.UpdateSeries("", 3)
+	if strings.Contains(string(plain.Marshal()), "series") {
+		t.Errorf("Marshal() = %q, want no series", plain.Marshal())
+	}
+}
+
+// TestMeta 测试分享元数据字段的解析与写回
+func TestMeta(t *testing.T) {
+	content := "---\ntitle: 标题\ndescription: 摘要\nmeta_title: 分享标题\nmeta_description: 分享描述\nimages: [\"/a.png\", \"/b.png\"]\n---\n正文"
+	fm, _, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if fm.Summary != "摘要" || fm.MetaTitle != "分享标题" || fm.MetaDescription != "分享描述" || fm.Image != "/a.png" {
+		t.Fatalf("unexpected front matter: %+v", fm)
+	}
+
+	fm, _, err = Parse([]byte("---\ncover: /cover.png\nimage: /image.png\n---\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if fm.Image != "/image.png" {
+		t.Errorf("Image = %q, want /image.png", fm.Image)
+	}
+
+	fm.UpdateMeta("新标题", "", "/new.png")
+	restored, _, err := Parse(fm.Marshal())
+	if err != nil {
+		t.Fatalf("Parse(Marshal()) error = %v", err)
+	}
+	if restored.MetaTitle != "新标题" || restored.MetaDescription != "" || restored.Image != "/new.png" {
+		t.Errorf("restored = %+v", restored)
+	}
+}
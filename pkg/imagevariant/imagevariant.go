@@ -0,0 +1,348 @@
+// Package imagevariant 为上传的图片生成不同宽度的缩略图与 WebP 版本
+package imagevariant
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	_ "image/gif" // 读取 GIF 尺寸
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 解码 WebP 原图
+)
+
+// Dir 变体所在的子目录，与原图位于同一目录下，例如 img/_variants/photo.png.480w.webp
+const Dir = "_variants"
+
+// Widths 生成的缩略图宽度，只生成比原图窄的版本
+var Widths = []int{480, 960, 1600}
+
+// MaxPixels 处理的最大像素数，超过时只读取尺寸不生成变体
+const MaxPixels = 50_000_000
+
+// 编码质量
+const (
+	jpegQuality = 82
+	webpQuality = 80
+)
+
+// Variant 一个变体文件
+type Variant struct {
+	Width int    // 宽度，0 表示与原图同尺寸
+	WebP  bool   // 是否为 WebP 格式
+	Path  string // 文件路径
+}
+
+// Supported 判断是否为可以生成变体的图片，GIF（可能是动图）与 SVG 不处理
+func Supported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+		return true
+	}
+	return false
+}
+
+// IsVariant 判断路径是否位于变体目录中
+func IsVariant(path string) bool {
+	return filepath.Base(filepath.Dir(path)) == Dir
+}
+
+// Path 返回原图对应的变体路径，width 为 0 时表示同尺寸的 WebP
+func Path(original string, width int, webP bool) string {
+	name := filepath.Base(original)
+	if width > 0 {
+		name += "." + strconv.Itoa(width) + "w"
+	}
+	if webP {
+		name += ".webp"
+	} else if width > 0 {
+		name += strings.ToLower(filepath.Ext(original))
+	}
+	return filepath.Join(filepath.Dir(original), Dir, name)
+}
+
+// Plan 返回宽度为 width 的原图需要生成的全部变体
+// JPEG、PNG 生成同格式与 WebP 的缩略图以及同尺寸的 WebP；WebP 原图只生成缩略图
+// 不支持编码 WebP 时只生成同格式的缩略图，WebP 原图不生成变体
+func Plan(original string, width int) []Variant {
+	if !Supported(original) {
+		return nil
+	}
+	isWebP := strings.EqualFold(filepath.Ext(original), ".webp")
+	if isWebP && !WebPSupported {
+		return nil
+	}
+
+	var variants []Variant
+	for _, w := range Widths {
+		if w >= width {
+			break
+		}
+		if !isWebP {
+			variants = append(variants, Variant{Width: w, Path: Path(original, w, false)})
+		}
+		if WebPSupported {
+			variants = append(variants, Variant{Width: w, WebP: true, Path: Path(original, w, true)})
+		}
+	}
+	if !isWebP && WebPSupported {
+		variants = append(variants, Variant{WebP: true, Path: Path(original, 0, true)})
+	}
+	return variants
+}
+
+// Dimensions 读取图片显示时的宽高，已按 EXIF 方向旋转
+func Dimensions(path string) (width, height int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	if swapsAxes(readOrientation(f)) {
+		return cfg.Height, cfg.Width, nil
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Available 返回原图的显示尺寸与已经生成好的变体
+// 变体在后台生成，渲染时只使用磁盘上已存在的文件
+func Available(original string) (width, height int, variants []Variant, err error) {
+	width, height, err = Dimensions(original)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	for _, v := range Plan(original, width) {
+		if _, err := os.Stat(v.Path); err == nil {
+			variants = append(variants, v)
+		}
+	}
+	return width, height, variants, nil
+}
+
+// Generate 生成原图的全部变体，已存在且不早于原图的变体会被跳过
+// 返回新生成的变体数量
+func Generate(original string) (int, error) {
+	info, err := os.Stat(original)
+	if err != nil {
+		return 0, err
+	}
+	width, height, err := Dimensions(original)
+	if err != nil {
+		return 0, fmt.Errorf("读取图片尺寸失败: %w", err)
+	}
+	if width*height > MaxPixels {
+		return 0, fmt.Errorf("图片过大: %dx%d", width, height)
+	}
+
+	var pending []Variant
+	for _, v := range Plan(original, width) {
+		if vi, err := os.Stat(v.Path); err == nil && !vi.ModTime().Before(info.ModTime()) {
+			continue
+		}
+		pending = append(pending, v)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(original)
+	if err != nil {
+		return 0, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("解码图片失败: %w", err)
+	}
+	src = orient(src, readOrientation(bytes.NewReader(data)))
+
+	if err := os.MkdirAll(filepath.Join(filepath.Dir(original), Dir), 0755); err != nil {
+		return 0, fmt.Errorf("创建目录失败: %w", err)
+	}
+	for i, v := range pending {
+		img := src
+		if v.Width > 0 {
+			img = resize(src, v.Width)
+		}
+		if err := writeVariant(v, img); err != nil {
+			return i, fmt.Errorf("生成 %s 失败: %w", filepath.Base(v.Path), err)
+		}
+	}
+	return len(pending), nil
+}
+
+// Remove 删除原图的全部变体
+func Remove(original string) {
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(original), Dir, globEscape(filepath.Base(original))+".*"))
+	for _, m := range matches {
+		os.Remove(m)
+	}
+}
+
+// globEscape 转义文件名中的通配符
+func globEscape(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// resize 按宽度等比缩放
+func resize(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	height := (b.Dy()*width + b.Dx()/2) / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+// writeVariant 编码并写入变体，先写临时文件再重命名，渲染时不会读到写了一半的文件
+func writeVariant(v Variant, img image.Image) error {
+	tmp, err := os.CreateTemp(filepath.Dir(v.Path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	switch {
+	case v.WebP:
+		err = encodeWebP(tmp, img)
+	case strings.EqualFold(filepath.Ext(v.Path), ".png"):
+		err = png.Encode(tmp, img)
+	default:
+		err = jpeg.Encode(tmp, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), v.Path)
+}
+
+// readOrientation 读取 JPEG 的 EXIF 方向，无法识别时返回 1
+func readOrientation(r io.Reader) int {
+	var marker [4]byte
+	if _, err := io.ReadFull(r, marker[:2]); err != nil || marker[0] != 0xFF || marker[1] != 0xD8 {
+		return 1
+	}
+	for {
+		if _, err := io.ReadFull(r, marker[:]); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(marker[2:])) - 2
+		if size < 0 || marker[1] == 0xDA {
+			return 1
+		}
+		segment := make([]byte, size)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return 1
+		}
+		if marker[1] == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+	}
+}
+
+// exifOrientation 在 TIFF 结构的第一个 IFD 中查找方向标签 0x0112
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// swapsAxes 方向 5 到 8 需要交换宽高
+func swapsAxes(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// orient 按 EXIF 方向旋转、翻转图片，变体不保留 EXIF，需要直接写入像素
+func orient(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if swapsAxes(orientation) {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = w-1-x, y
+			case 3: // 旋转 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, h-1-y
+			case 5: // 沿主对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转 90°
+				dx, dy = h-1-y, x
+			case 7: // 沿副对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转 90°
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,162 @@
+package imagevariant
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPlan 测试不同格式与宽度需要生成的变体
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		width int
+		want  []string
+	}{
+		{"large png", "img/a.png", 1200, []string{"a.png.480w.png", "a.png.480w.webp", "a.png.960w.png", "a.png.960w.webp", "a.png.webp"}},
+		{"small jpeg", "img/a.jpg", 300, []string{"a.jpg.webp"}},
+		{"webp", "img/a.webp", 2000, []string{"a.webp.480w.webp", "a.webp.960w.webp", "a.webp.1600w.webp"}},
+		{"gif", "img/a.gif", 2000, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !WebPSupported {
+				tt.want = withoutWebP(tt.want)
+			}
+			var got []string
+			for _, v := range Plan(tt.file, tt.width) {
+				if filepath.Dir(v.Path) != filepath.Join("img", Dir) {
+					t.Errorf("variant %s not in %s", v.Path, Dir)
+				}
+				got = append(got, filepath.Base(v.Path))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Plan() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Plan()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestGenerate 测试生成、跳过已有变体与删除
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "photo.png")
+	writePNG(t, original, 1000, 500)
+
+	n, err := Generate(original)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := 5
+	if !WebPSupported {
+		want = 2
+	}
+	if n != want {
+		t.Errorf("Generate() = %d, want %d", n, want)
+	}
+
+	width, height, variants, err := Available(original)
+	if err != nil {
+		t.Fatalf("Available() error = %v", err)
+	}
+	if width != 1000 || height != 500 || len(variants) != want {
+		t.Errorf("Available() = %d, %d, %d variants", width, height, len(variants))
+	}
+	if w, h, _ := Dimensions(Path(original, 480, WebPSupported)); w != 480 || h != 240 {
+		t.Errorf("480w variant is %dx%d", w, h)
+	}
+
+	if n, err := Generate(original); err != nil || n != 0 {
+		t.Errorf("second Generate() = %d, %v, want 0", n, err)
+	}
+
+	Remove(original)
+	if _, _, variants, _ := Available(original); len(variants) != 0 {
+		t.Errorf("variants left after Remove(): %v", variants)
+	}
+}
+
+// TestOrientation 测试 EXIF 方向为 6 的 JPEG 按旋转后的尺寸处理
+func TestOrientation(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "phone.jpg")
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 600, 400)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	withExif := append([]byte{}, data[:2]...)
+	withExif = append(withExif, exifSegment(6)...)
+	withExif = append(withExif, data[2:]...)
+	if err := os.WriteFile(original, withExif, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if w, h, err := Dimensions(original); err != nil || w != 400 || h != 600 {
+		t.Fatalf("Dimensions() = %d, %d, %v, want 400, 600", w, h, err)
+	}
+	if _, err := Generate(original); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !WebPSupported {
+		return
+	}
+	if w, h, _ := Dimensions(Path(original, 0, true)); w != 400 || h != 600 {
+		t.Errorf("webp variant is %dx%d, want 400x600", w, h)
+	}
+}
+
+// withoutWebP 去掉 WebP 变体的文件名
+func withoutWebP(names []string) []string {
+	var kept []string
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".webp") {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+func writePNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// exifSegment 只包含方向标签的 APP1 段
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
@@ -0,0 +1,18 @@
+//go:build cgo
+
+package imagevariant
+
+import (
+	"image"
+	"io"
+
+	"github.com/chai2010/webp"
+)
+
+// WebPSupported 是否可以生成 WebP 变体，WebP 编码器依赖 cgo
+const WebPSupported = true
+
+// encodeWebP 按 webpQuality 编码 WebP
+func encodeWebP(w io.Writer, img image.Image) error {
+	return webp.Encode(w, img, &webp.Options{Quality: webpQuality})
+}
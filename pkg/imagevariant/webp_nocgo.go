@@ -0,0 +1,17 @@
+//go:build !cgo
+
+package imagevariant
+
+import (
+	"errors"
+	"image"
+	"io"
+)
+
+// WebPSupported 是否可以生成 WebP 变体，不使用 cgo 编译时只生成同格式的缩略图
+const WebPSupported = false
+
+// encodeWebP 不使用 cgo 编译时没有 WebP 编码器
+func encodeWebP(w io.Writer, img image.Image) error {
+	return errors.New("webp encoding requires cgo")
+}
@@ -0,0 +1,244 @@
+package mathml
+
+import (
+	"bytes"
+	"html"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindMath 公式节点类型
+var KindMath = ast.NewNodeKind("Math")
+
+// KindMathBlock 块级公式节点类型
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// MathNode 行内公式节点，$...$ 或 $$...$$
+type MathNode struct {
+	ast.BaseInline
+	TeX     string
+	Display bool
+}
+
+// Kind 实现 Node 接口
+func (n *MathNode) Kind() ast.NodeKind {
+	return KindMath
+}
+
+// Dump 实现 Node 接口
+func (n *MathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"TeX": n.TeX}, nil)
+}
+
+// MathBlockNode 独占多行的 $$ ... $$ 公式块
+type MathBlockNode struct {
+	ast.BaseBlock
+	TeX    []byte
+	closed bool
+}
+
+// Kind 实现 Node 接口
+func (n *MathBlockNode) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+// Dump 实现 Node 接口
+func (n *MathBlockNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"TeX": string(n.TeX)}, nil)
+}
+
+// IsRaw 公式块内容不再按 markdown 解析
+func (n *MathBlockNode) IsRaw() bool {
+	return true
+}
+
+// inlineParser 解析行内的 $...$ 与 $$...$$
+// 规则与 Pandoc 一致：开头的 $ 后不能是空白，结尾的 $ 前不能是空白且后面不能紧跟数字，避免误识别金额
+// 代码段由 goldmark 先行解析，其中的 $ 不会触发本解析器
+type inlineParser struct{}
+
+func (p *inlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *inlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delimiter := 1
+	if len(line) > 1 && line[1] == '$' {
+		delimiter = 2
+	}
+
+	start := delimiter
+	if start >= len(line) || isSpace(line[start]) {
+		return nil
+	}
+	for i := start; i+delimiter <= len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '$':
+			if !bytes.HasPrefix(line[i:], line[:delimiter]) || isSpace(line[i-1]) {
+				if delimiter == 2 {
+					continue
+				}
+				return nil
+			}
+			if delimiter == 1 && i+1 < len(line) && (line[i+1] >= '0' && line[i+1] <= '9' || line[i+1] == '$') {
+				return nil
+			}
+			block.Advance(i + delimiter)
+			return &MathNode{TeX: string(line[start:i]), Display: delimiter == 2}
+		}
+	}
+	return nil
+}
+
+// blockParser 解析以 $$ 开头的公式块
+type blockParser struct{}
+
+func (p *blockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *blockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	rest := bytes.TrimSpace(line[pos:])
+	if !bytes.HasPrefix(rest, []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+	rest = rest[2:]
+
+	node := &MathBlockNode{}
+	if bytes.HasSuffix(rest, []byte("$$")) {
+		// 单行公式块 $$ ... $$
+		node.TeX = append(node.TeX, rest[:len(rest)-2]...)
+		node.closed = true
+		reader.Advance(lineLength(line))
+		return node, parser.NoChildren
+	}
+	if bytes.Contains(rest, []byte("$")) {
+		// 同一行还有其他内容，交给行内解析
+		return nil, parser.NoChildren
+	}
+	node.TeX = append(node.TeX, rest...)
+	reader.Advance(lineLength(line))
+	return node, parser.NoChildren
+}
+
+func (p *blockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*MathBlockNode)
+	if n.closed {
+		return parser.Close
+	}
+	line, _ := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	trimmed := bytes.TrimSpace(line)
+	if bytes.HasSuffix(trimmed, []byte("$$")) {
+		n.TeX = append(n.TeX, '\n')
+		n.TeX = append(n.TeX, trimmed[:len(trimmed)-2]...)
+		n.closed = true
+		reader.Advance(lineLength(line))
+		return parser.Close
+	}
+	n.TeX = append(n.TeX, '\n')
+	n.TeX = append(n.TeX, trimmed...)
+	reader.Advance(lineLength(line))
+	return parser.Continue | parser.NoChildren
+}
+
+func (p *blockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// lineLength 行的长度，不包含结尾的换行；文件最后一行可能没有换行
+func lineLength(line []byte) int {
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		return n - 1
+	}
+	return len(line)
+}
+
+func (p *blockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *blockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// Renderer 公式渲染器，转换失败时按原样输出 TeX，由前端 KaTeX 渲染
+type Renderer struct{}
+
+// RegisterFuncs 注册渲染函数
+func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMath, r.renderMath)
+	reg.Register(KindMathBlock, r.renderMathBlock)
+}
+
+func (r *Renderer) renderMath(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*MathNode)
+	delimiter := "$"
+	if n.Display {
+		delimiter = "$$"
+	}
+	if out, err := Convert(n.TeX, n.Display); err == nil {
+		_, _ = w.WriteString(out)
+	} else {
+		_, _ = w.WriteString(html.EscapeString(delimiter + n.TeX + delimiter))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *Renderer) renderMathBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*MathBlockNode)
+	tex := string(bytes.TrimSpace(n.TeX))
+	if n.closed {
+		if out, err := Convert(tex, true); err == nil {
+			_, _ = w.WriteString(`<div class="math-display">` + out + "</div>\n")
+			return ast.WalkSkipChildren, nil
+		}
+	}
+
+	closing := "$$"
+	if !n.closed {
+		closing = ""
+	}
+	_, _ = w.WriteString(`<div class="math-display">` + html.EscapeString("$$"+string(n.TeX)+closing) + "</div>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// Extension 服务端公式渲染扩展
+type Extension struct{}
+
+// NewExtension 创建服务端公式渲染扩展
+func NewExtension() goldmark.Extender {
+	return &Extension{}
+}
+
+// Extend 扩展 Goldmark
+func (e *Extension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&blockParser{}, 150)),
+		parser.WithInlineParsers(util.Prioritized(&inlineParser{}, 150)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&Renderer{}, 150)))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,455 @@
+// Package mathml 将常用的 LaTeX 数学公式转换为 MathML，便于在服务端渲染公式
+// 只支持博客中常见的子集，遇到不支持的命令时返回 ErrUnsupported，由调用方回退到前端渲染
+package mathml
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// ErrUnsupported 公式中包含不支持的命令或语法
+var ErrUnsupported = errors.New("mathml: unsupported tex")
+
+// Convert 将 TeX 公式转换为 <math> 元素，display 为 true 时输出块级公式
+// 原始 TeX 保存在 annotation 中，便于复制与辅助技术读取
+func Convert(tex string, display bool) (string, error) {
+	p := &texParser{src: tex, display: display}
+	body, err := p.parseUntil("")
+	if err != nil {
+		return "", err
+	}
+
+	mode := "inline"
+	if display {
+		mode = "block"
+	}
+	return fmt.Sprintf(`<math xmlns="http://www.w3.org/1998/Math/MathML" display="%s"><semantics><mrow>%s</mrow><annotation encoding="application/x-tex">%s</annotation></semantics></math>`,
+		mode, body, html.EscapeString(strings.TrimSpace(tex))), nil
+}
+
+// texParser 递归下降解析器，直接输出 MathML 片段
+type texParser struct {
+	src     string
+	pos     int
+	display bool
+	// variant 当前字母字体（\mathbb、\mathbf 等），为空表示默认斜体
+	variant string
+}
+
+// unsupported 返回带位置信息的不支持错误
+func (p *texParser) unsupported(what string) error {
+	return fmt.Errorf("%w: %s at %d", ErrUnsupported, what, p.pos)
+}
+
+func (p *texParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *texParser) skipSpace() {
+	for !p.eof() && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// peekCommand 返回当前位置的命令名（不含反斜杠），不移动位置
+func (p *texParser) peekCommand() string {
+	if p.eof() || p.src[p.pos] != '\\' {
+		return ""
+	}
+	end := p.pos + 1
+	for end < len(p.src) && isLetter(p.src[end]) {
+		end++
+	}
+	if end == p.pos+1 && end < len(p.src) {
+		end++ // 单个非字母字符的命令，如 \{ \, \\
+	}
+	return p.src[p.pos+1 : end]
+}
+
+// parseUntil 解析原子序列，直到遇到 terminator（"}"、"\right" 或 "]"）或输入结束
+func (p *texParser) parseUntil(terminator string) (string, error) {
+	var out strings.Builder
+	for {
+		p.skipSpace()
+		if p.eof() {
+			if terminator != "" {
+				return "", p.unsupported("missing " + terminator)
+			}
+			return out.String(), nil
+		}
+		switch {
+		case terminator == "}" && p.src[p.pos] == '}':
+			return out.String(), nil
+		case terminator == "]" && p.src[p.pos] == ']':
+			return out.String(), nil
+		case terminator == `\right` && p.peekCommand() == "right":
+			return out.String(), nil
+		case p.src[p.pos] == '}':
+			return "", p.unsupported("unbalanced }")
+		}
+
+		atom, err := p.parseScripted()
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(atom)
+	}
+}
+
+// parseScripted 解析一个原子及其上下标
+func (p *texParser) parseScripted() (string, error) {
+	base, limits, err := p.parseAtom()
+	if err != nil {
+		return "", err
+	}
+
+	var sub, sup string
+	for {
+		p.skipSpace()
+		if p.eof() {
+			break
+		}
+		c := p.src[p.pos]
+		if c == '\'' {
+			p.pos++
+			sup += "<mo>′</mo>"
+			continue
+		}
+		if c != '^' && c != '_' {
+			break
+		}
+		p.pos++
+		if (c == '^' && sup != "") || (c == '_' && sub != "") {
+			return "", p.unsupported("double script")
+		}
+		arg, err := p.parseArgument()
+		if err != nil {
+			return "", err
+		}
+		if c == '^' {
+			sup = arg
+		} else {
+			sub = arg
+		}
+	}
+
+	if base == "" {
+		base = "<mrow></mrow>"
+	}
+	under, over := "msub", "msup"
+	both := "msubsup"
+	if limits && p.display {
+		under, over, both = "munder", "mover", "munderover"
+	}
+	switch {
+	case sub != "" && sup != "":
+		return fmt.Sprintf("<%s>%s%s%s</%s>", both, base, wrap(sub), wrap(sup), both), nil
+	case sub != "":
+		return fmt.Sprintf("<%s>%s%s</%s>", under, base, wrap(sub), under), nil
+	case sup != "":
+		return fmt.Sprintf("<%s>%s%s</%s>", over, base, wrap(sup), over), nil
+	}
+	return base, nil
+}
+
+// parseArgument 解析命令或上下标的参数：花括号分组或单个原子
+func (p *texParser) parseArgument() (string, error) {
+	p.skipSpace()
+	if p.eof() {
+		return "", p.unsupported("missing argument")
+	}
+	if p.src[p.pos] == '{' {
+		return p.parseGroup()
+	}
+	atom, _, err := p.parseAtom()
+	return atom, err
+}
+
+// parseGroup 解析 {...} 分组
+func (p *texParser) parseGroup() (string, error) {
+	p.skipSpace()
+	if p.eof() || p.src[p.pos] != '{' {
+		return "", p.unsupported("expected {")
+	}
+	p.pos++
+	body, err := p.parseUntil("}")
+	if err != nil {
+		return "", err
+	}
+	p.pos++
+	return "<mrow>" + body + "</mrow>", nil
+}
+
+// parseRawGroup 读取 {...} 中的原始文本，用于 \text 等命令
+func (p *texParser) parseRawGroup() (string, error) {
+	p.skipSpace()
+	if p.eof() || p.src[p.pos] != '{' {
+		return "", p.unsupported("expected {")
+	}
+	depth := 0
+	start := p.pos + 1
+	for i := p.pos; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos = i + 1
+				return p.src[start:i], nil
+			}
+		}
+	}
+	return "", p.unsupported("unbalanced {")
+}
+
+// parseAtom 解析单个原子，limits 表示上下标在显示模式下应放在正上下方
+func (p *texParser) parseAtom() (atom string, limits bool, err error) {
+	c := p.src[p.pos]
+	switch {
+	case c == '{':
+		atom, err = p.parseGroup()
+		return atom, false, err
+	case c == '\\':
+		return p.parseCommand()
+	case isDigit(c) || (c == '.' && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1])):
+		start := p.pos
+		for !p.eof() && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		return "<mn>" + p.styled(p.src[start:p.pos]) + "</mn>", false, nil
+	case isLetter(c):
+		p.pos++
+		return p.identifier(string(c)), false, nil
+	case c == '^' || c == '_':
+		// 没有底数的上下标，如 ^2
+		return "", false, nil
+	case c == '&' || c == '#' || c == '%' || c == '~':
+		return "", false, p.unsupported(string(c))
+	case c < 0x80:
+		p.pos++
+		return operator(string(c)), false, nil
+	}
+
+	// 非 ASCII 字符（如直接输入的 α、×）按原样输出
+	r := []rune(p.src[p.pos:])[0]
+	p.pos += len(string(r))
+	if isOperatorRune(r) {
+		return operator(string(r)), false, nil
+	}
+	return "<mi>" + html.EscapeString(string(r)) + "</mi>", false, nil
+}
+
+// identifier 输出单个字母标识符，应用当前字体
+func (p *texParser) identifier(letter string) string {
+	switch p.variant {
+	case "":
+		return "<mi>" + letter + "</mi>"
+	case "normal":
+		return `<mi mathvariant="normal">` + letter + "</mi>"
+	}
+	return "<mi>" + p.styled(letter) + "</mi>"
+}
+
+// styled 将字母和数字映射为当前字体对应的 Unicode 数学字母
+func (p *texParser) styled(s string) string {
+	if p.variant == "" || p.variant == "normal" {
+		return html.EscapeString(s)
+	}
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteRune(mathAlphanumeric(p.variant, r))
+	}
+	return b.String()
+}
+
+// parseCommand 解析反斜杠命令
+func (p *texParser) parseCommand() (string, bool, error) {
+	name := p.peekCommand()
+	p.pos += 1 + len(name)
+
+	if s, ok := greek[name]; ok {
+		if p.variant != "" || (s >= "Α" && s <= "Ω") {
+			return `<mi mathvariant="normal">` + s + "</mi>", false, nil
+		}
+		return "<mi>" + s + "</mi>", false, nil
+	}
+	if s, ok := identifiers[name]; ok {
+		return "<mi>" + s + "</mi>", false, nil
+	}
+	if s, ok := symbols[name]; ok {
+		return operator(s), false, nil
+	}
+	if op, ok := largeOperators[name]; ok {
+		attr := ""
+		if p.display {
+			attr = ` largeop="true"`
+		}
+		return fmt.Sprintf("<mo%s>%s</mo>", attr, op.symbol), op.limits, nil
+	}
+	if _, ok := functions[name]; ok {
+		return fmt.Sprintf("<mi>%s</mi><mo>&#x2061;</mo>", name), functions[name], nil
+	}
+	if width, ok := spaces[name]; ok {
+		return fmt.Sprintf(`<mspace width="%s"></mspace>`, width), false, nil
+	}
+	if accent, ok := accents[name]; ok {
+		arg, err := p.parseArgument()
+		if err != nil {
+			return "", false, err
+		}
+		if name == "underline" {
+			return fmt.Sprintf(`<munder accentunder="true">%s<mo>%s</mo></munder>`, arg, accent), false, nil
+		}
+		return fmt.Sprintf(`<mover accent="true">%s<mo>%s</mo></mover>`, arg, accent), false, nil
+	}
+	if variant, ok := variants[name]; ok {
+		saved := p.variant
+		p.variant = variant
+		arg, err := p.parseArgument()
+		p.variant = saved
+		return arg, false, err
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac":
+		num, err := p.parseArgument()
+		if err != nil {
+			return "", false, err
+		}
+		den, err := p.parseArgument()
+		if err != nil {
+			return "", false, err
+		}
+		return "<mfrac>" + wrap(num) + wrap(den) + "</mfrac>", false, nil
+	case "binom":
+		top, err := p.parseArgument()
+		if err != nil {
+			return "", false, err
+		}
+		bottom, err := p.parseArgument()
+		if err != nil {
+			return "", false, err
+		}
+		return `<mrow><mo>(</mo><mfrac linethickness="0">` + wrap(top) + wrap(bottom) + `</mfrac><mo>)</mo></mrow>`, false, nil
+	case "sqrt":
+		p.skipSpace()
+		if !p.eof() && p.src[p.pos] == '[' {
+			p.pos++
+			index, err := p.parseUntil("]")
+			if err != nil {
+				return "", false, err
+			}
+			p.pos++
+			radicand, err := p.parseArgument()
+			if err != nil {
+				return "", false, err
+			}
+			return "<mroot>" + wrap(radicand) + "<mrow>" + index + "</mrow></mroot>", false, nil
+		}
+		radicand, err := p.parseArgument()
+		if err != nil {
+			return "", false, err
+		}
+		return "<msqrt>" + radicand + "</msqrt>", false, nil
+	case "text", "textrm", "textit", "textbf", "mbox":
+		text, err := p.parseRawGroup()
+		if err != nil {
+			return "", false, err
+		}
+		return "<mtext>" + html.EscapeString(text) + "</mtext>", false, nil
+	case "operatorname":
+		text, err := p.parseRawGroup()
+		if err != nil {
+			return "", false, err
+		}
+		return "<mi>" + html.EscapeString(text) + "</mi><mo>&#x2061;</mo>", false, nil
+	case "left":
+		return p.parseFenced()
+	case "right":
+		return "", false, p.unsupported(`unbalanced \right`)
+	}
+
+	return "", false, p.unsupported(`\` + name)
+}
+
+// parseFenced 解析 \left ... \right 包围的内容
+func (p *texParser) parseFenced() (string, bool, error) {
+	open, err := p.parseDelimiter()
+	if err != nil {
+		return "", false, err
+	}
+	body, err := p.parseUntil(`\right`)
+	if err != nil {
+		return "", false, err
+	}
+	p.pos += len(`\right`)
+	closing, err := p.parseDelimiter()
+	if err != nil {
+		return "", false, err
+	}
+	return "<mrow>" + fence(open) + body + fence(closing) + "</mrow>", false, nil
+}
+
+// parseDelimiter 读取 \left、\right 后的定界符，"." 表示空定界符
+func (p *texParser) parseDelimiter() (string, error) {
+	p.skipSpace()
+	if p.eof() {
+		return "", p.unsupported("missing delimiter")
+	}
+	c := p.src[p.pos]
+	if c == '\\' {
+		name := p.peekCommand()
+		if s, ok := delimiters[name]; ok {
+			p.pos += 1 + len(name)
+			return s, nil
+		}
+		return "", p.unsupported(`delimiter \` + name)
+	}
+	if strings.IndexByte("()[]|/.", c) >= 0 {
+		p.pos++
+		if c == '.' {
+			return "", nil
+		}
+		return string(c), nil
+	}
+	return "", p.unsupported("delimiter " + string(c))
+}
+
+// fence 输出可伸缩的定界符
+func fence(s string) string {
+	if s == "" {
+		return ""
+	}
+	return `<mo fence="true" stretchy="true">` + html.EscapeString(s) + "</mo>"
+}
+
+// operator 输出运算符，转义 HTML 特殊字符
+func operator(s string) string {
+	return "<mo>" + html.EscapeString(s) + "</mo>"
+}
+
+// wrap 保证上下标、分子分母等位置只有一个子元素
+func wrap(s string) string {
+	if strings.HasPrefix(s, "<mrow>") && strings.HasSuffix(s, "</mrow>") {
+		return s
+	}
+	return "<mrow>" + s + "</mrow>"
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isOperatorRune 判断直接输入的非 ASCII 字符是否为运算符
+func isOperatorRune(r rune) bool {
+	return (r >= 0x2190 && r <= 0x22FF) || r == '×' || r == '÷' || r == '±' || r == '·'
+}
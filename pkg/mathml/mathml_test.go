@@ -0,0 +1,82 @@
+package mathml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+)
+
+// TestExtension 测试公式识别、代码段与金额的排除以及不支持宏的回退
+func TestExtension(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(NewExtension()))
+
+	tests := []struct {
+		name string
+		src  string
+		want []string
+		not  []string
+	}{
+		{
+			name: "inline",
+			src:  `质能方程 $E=mc^2$。`,
+			want: []string{`display="inline"`, `<msup><mi>c</mi><mrow><mn>2</mn></mrow></msup>`, `encoding="application/x-tex">E=mc^2<`},
+		},
+		{
+			name: "block",
+			src:  "$$\n\\sum_{i=1}^n i = \\frac{n(n+1)}{2}\n$$\n",
+			want: []string{`<div class="math-display">`, `display="block"`, `<munderover><mo largeop="true">∑</mo>`, `<mfrac>`},
+		},
+		{
+			name: "block at end of file",
+			src:  "text\n\n$$\nE = mc^2\n$$",
+			want: []string{`<div class="math-display">`, `<mi>E</mi>`},
+			not:  []string{`<p>$</p>`, `$$`},
+		},
+		{
+			name: "single line block at end of file",
+			src:  "text\n\n$$E = mc^2$$",
+			want: []string{`<div class="math-display">`},
+			not:  []string{`<p>$</p>`},
+		},
+		{
+			name: "code span",
+			src:  "`$x$`",
+			want: []string{`<code>$x$</code>`},
+			not:  []string{`<math`},
+		},
+		{
+			name: "currency",
+			src:  `售价 $5 与 $10`,
+			want: []string{`售价 $5 与 $10`},
+			not:  []string{`<math`},
+		},
+		{
+			name: "unsupported",
+			src:  `$\begin{matrix}a\end{matrix}$`,
+			want: []string{`$\begin{matrix}a\end{matrix}$`},
+			not:  []string{`<math`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := md.Convert([]byte(tt.src), &buf); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			html := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("html missing %s:\n%s", want, html)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(html, not) {
+					t.Errorf("html should not contain %s:\n%s", not, html)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package mathml
+
+// greek 希腊字母
+var greek = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ",
+	"varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ",
+	"varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+// identifiers 作为标识符输出的符号
+var identifiers = map[string]string{
+	"infty": "∞", "partial": "∂", "nabla": "∇", "hbar": "ℏ", "ell": "ℓ", "Re": "ℜ", "Im": "ℑ",
+	"aleph": "ℵ", "emptyset": "∅", "varnothing": "∅", "wp": "℘",
+}
+
+// symbols 作为运算符输出的符号
+var symbols = map[string]string{
+	"cdot": "⋅", "times": "×", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗", "star": "⋆", "circ": "∘",
+	"bullet": "∙", "oplus": "⊕", "ominus": "⊖", "otimes": "⊗", "odot": "⊙",
+	"le": "≤", "leq": "≤", "ge": "≥", "geq": "≥", "ne": "≠", "neq": "≠", "ll": "≪", "gg": "≫",
+	"approx": "≈", "equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "implies": "⟹", "iff": "⟺",
+	"mapsto": "↦", "longrightarrow": "⟶", "longleftarrow": "⟵", "uparrow": "↑", "downarrow": "↓",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃", "supseteq": "⊇",
+	"cup": "∪", "cap": "∩", "setminus": "∖", "forall": "∀", "exists": "∃", "nexists": "∄",
+	"neg": "¬", "lnot": "¬", "land": "∧", "wedge": "∧", "lor": "∨", "vee": "∨",
+	"ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+	"{": "{", "}": "}", "|": "‖", "mid": "∣", "perp": "⊥", "parallel": "∥", "angle": "∠",
+	"triangle": "△", "prime": "′", "%": "%", "$": "$", "#": "#", "&": "&", "_": "_",
+}
+
+// largeOperator 大型运算符
+type largeOperator struct {
+	symbol string
+	limits bool // 显示模式下上下标放在正上下方
+}
+
+// largeOperators 求和、积分等大型运算符
+var largeOperators = map[string]largeOperator{
+	"sum": {"∑", true}, "prod": {"∏", true}, "coprod": {"∐", true},
+	"bigcup": {"⋃", true}, "bigcap": {"⋂", true}, "bigoplus": {"⨁", true}, "bigotimes": {"⨂", true},
+	"int": {"∫", false}, "iint": {"∬", false}, "iiint": {"∭", false}, "oint": {"∮", false},
+}
+
+// functions 函数名，值表示显示模式下上下标是否放在正下方
+var functions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false, "csc": false,
+	"arcsin": false, "arccos": false, "arctan": false, "sinh": false, "cosh": false, "tanh": false,
+	"log": false, "ln": false, "lg": false, "exp": false, "det": true, "dim": false, "ker": false,
+	"deg": false, "arg": false, "gcd": true, "lim": true, "liminf": true, "limsup": true,
+	"max": true, "min": true, "sup": true, "inf": true, "Pr": true,
+}
+
+// spaces 间距命令
+var spaces = map[string]string{
+	",": "0.1667em", ":": "0.2222em", ">": "0.2222em", ";": "0.2778em", " ": "0.3333em",
+	"quad": "1em", "qquad": "2em", "!": "-0.1667em",
+}
+
+// accents 重音与上下划线
+var accents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "¯", "overline": "¯", "underline": "_",
+	"vec": "→", "overrightarrow": "→", "tilde": "~", "widetilde": "~", "dot": "˙", "ddot": "¨",
+}
+
+// variants 字体命令
+var variants = map[string]string{
+	"mathrm": "normal", "mathit": "", "mathbf": "bold", "boldsymbol": "bold",
+	"mathbb": "double-struck", "mathcal": "script", "mathfrak": "fraktur",
+}
+
+// delimiters \left、\right 可用的命令定界符
+var delimiters = map[string]string{
+	"{": "{", "}": "}", "|": "‖", "langle": "⟨", "rangle": "⟩",
+	"lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉", "vert": "|", "Vert": "‖",
+}
+
+// letterlikeExceptions Unicode 数学字母区中缺失、位于字母类符号区的字符
+var letterlikeExceptions = map[string]map[rune]rune{
+	"double-struck": {'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ'},
+	"script": {'B': 'ℬ', 'E': 'ℰ', 'F': 'ℱ', 'H': 'ℋ', 'I': 'ℐ', 'L': 'ℒ', 'M': 'ℳ', 'R': 'ℛ',
+		'e': 'ℯ', 'g': 'ℊ', 'o': 'ℴ'},
+	"fraktur": {'C': 'ℭ', 'H': 'ℌ', 'I': 'ℑ', 'R': 'ℜ', 'Z': 'ℨ'},
+}
+
+// alphanumericBases 各字体大写字母 A 在数学字母区的码位
+var alphanumericBases = map[string]rune{
+	"bold": 0x1D400, "script": 0x1D49C, "fraktur": 0x1D504, "double-struck": 0x1D538,
+}
+
+// digitBases 各字体数字 0 的码位
+var digitBases = map[string]rune{
+	"bold": 0x1D7CE, "double-struck": 0x1D7D8,
+}
+
+// mathAlphanumeric 将字母或数字映射到指定字体的数学字母
+func mathAlphanumeric(variant string, r rune) rune {
+	if mapped, ok := letterlikeExceptions[variant][r]; ok {
+		return mapped
+	}
+	switch {
+	case r >= 'A' && r <= 'Z':
+		if base, ok := alphanumericBases[variant]; ok {
+			return base + (r - 'A')
+		}
+	case r >= 'a' && r <= 'z':
+		if base, ok := alphanumericBases[variant]; ok {
+			return base + 26 + (r - 'a')
+		}
+	case r >= '0' && r <= '9':
+		if base, ok := digitBases[variant]; ok {
+			return base + (r - '0')
+		}
+	}
+	return r
+}
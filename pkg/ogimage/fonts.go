@@ -0,0 +1,41 @@
+package ogimage
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// embeddedFonts 编译时嵌入的字体，用于在没有自定义字体时绘制中文标题
+//
+//go:embed fonts
+var embeddedFonts embed.FS
+
+// EmbeddedFont 随程序嵌入的字体文件
+type EmbeddedFont struct {
+	Name string
+	Data []byte
+}
+
+// EmbeddedFonts 返回 fonts 目录中嵌入的 .ttf、.otf 与 .ttc 字体，按文件名排序
+func EmbeddedFonts() []EmbeddedFont {
+	entries, err := fs.ReadDir(embeddedFonts, "fonts")
+	if err != nil {
+		return nil
+	}
+	var fonts []EmbeddedFont
+	for _, entry := range entries {
+		switch strings.ToLower(path.Ext(entry.Name())) {
+		case ".ttf", ".otf", ".ttc":
+		default:
+			continue
+		}
+		data, err := embeddedFonts.ReadFile(path.Join("fonts", entry.Name()))
+		if err != nil {
+			continue
+		}
+		fonts = append(fonts, EmbeddedFont{Name: entry.Name(), Data: data})
+	}
+	return fonts
+}
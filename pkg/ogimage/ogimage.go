@@ -0,0 +1,518 @@
+// Package ogimage 为没有封面的文章生成 Open Graph 分享卡片图片
+package ogimage
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// 卡片尺寸，与 Open Graph 推荐的 1.91:1 一致
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// 布局参数
+const (
+	margin       = 48  // 玻璃面板与图片边缘的距离
+	padding      = 64  // 文字与玻璃面板边缘的距离
+	cornerRadius = 28  // 玻璃面板圆角
+	maxBlur      = 60  // 背景模糊半径上限
+	maxTags      = 5   // 最多绘制的标签数
+	titleLines   = 3   // 标题最多行数
+	tagSize      = 30  // 标签字号
+	siteNameSize = 34  // 站点名称字号
+	lineSpacing  = 1.3 // 标题行高与字号的比例
+)
+
+// titleSizes 标题依次尝试的字号，放不下时使用最小字号并截断
+var titleSizes = []float64{72, 60, 50}
+
+// Card 一张分享卡片的内容
+type Card struct {
+	Title      string
+	SiteName   string
+	Tags       []string
+	Background image.Image // 背景图片，nil 时使用渐变背景
+	Blur       int         // 背景模糊半径（像素）
+	GlassColor color.NRGBA // 玻璃面板颜色，透明度为 0 时使用默认颜色
+}
+
+// Renderer 卡片渲染器，按顺序在字体中查找字形，缺少的字形使用后面的字体
+type Renderer struct {
+	fonts []*sfnt.Font
+}
+
+// NewRenderer 使用给定的字体数据创建渲染器，支持 TTF、OTF 与 TTC
+// 内置的 Go Bold 字体总是作为最后的回退字体，只包含拉丁字母
+func NewRenderer(fontData ...[]byte) (*Renderer, error) {
+	r := &Renderer{}
+	for i, data := range fontData {
+		collection, err := opentype.ParseCollection(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse font %d: %w", i, err)
+		}
+		f, err := collection.Font(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load font %d: %w", i, err)
+		}
+		r.fonts = append(r.fonts, f)
+	}
+
+	fallback, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fallback font: %w", err)
+	}
+	r.fonts = append(r.fonts, fallback)
+	return r, nil
+}
+
+// Encode 渲染卡片并以 PNG 格式写入 w
+func (r *Renderer) Encode(w io.Writer, card Card) error {
+	img, err := r.Render(card)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Render 渲染卡片
+func (r *Renderer) Render(card Card) (*image.RGBA, error) {
+	dst := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	drawBackground(dst, card.Background, card.Blur)
+
+	// 压暗背景，保证白色文字清晰
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.NRGBA{0, 0, 0, 0x55}), image.Point{}, draw.Over)
+
+	// 玻璃面板：半透明填充加浅色描边
+	glass := card.GlassColor
+	if glass.A == 0 {
+		glass = color.NRGBA{220, 138, 221, 0x40}
+	}
+	if glass.A < 0x30 {
+		glass.A = 0x30
+	}
+	panel := image.Rect(margin, margin, Width-margin, Height-margin)
+	draw.DrawMask(dst, panel, image.NewUniform(color.NRGBA{255, 255, 255, 0x50}), image.Point{},
+		&roundedRect{rect: panel, radius: cornerRadius}, panel.Min, draw.Over)
+	inner := panel.Inset(2)
+	draw.DrawMask(dst, inner, image.NewUniform(glass), image.Point{},
+		&roundedRect{rect: inner, radius: cornerRadius - 2}, inner.Min, draw.Over)
+	draw.DrawMask(dst, inner, image.NewUniform(color.NRGBA{20, 20, 30, 0x30}), image.Point{},
+		&roundedRect{rect: inner, radius: cornerRadius - 2}, inner.Min, draw.Over)
+
+	left := panel.Min.X + padding
+	maxWidth := fixed.I(panel.Dx() - 2*padding)
+	white := color.NRGBA{255, 255, 255, 255}
+
+	// 标题：从大到小尝试字号，最小字号仍放不下时截断并加省略号
+	title := strings.Join(strings.Fields(card.Title), " ")
+	var lines []string
+	var titleFaces []font.Face
+	var titleSize float64
+	for _, size := range titleSizes {
+		faces, err := r.faces(size)
+		if err != nil {
+			return nil, err
+		}
+		var truncated bool
+		lines, truncated = wrap(title, maxWidth, titleLines, func(s string) fixed.Int26_6 { return r.measure(faces, s) })
+		titleFaces, titleSize = faces, size
+		if !truncated {
+			break
+		}
+	}
+	y := panel.Min.Y + padding
+	for _, line := range lines {
+		r.drawText(dst, titleFaces, line, left, y+int(titleSize), white)
+		y += int(titleSize * lineSpacing)
+	}
+
+	// 底部：标签在上，站点名称在下
+	bottom := panel.Max.Y - padding
+	siteFaces, err := r.faces(siteNameSize)
+	if err != nil {
+		return nil, err
+	}
+	siteName, _ := wrap(card.SiteName, maxWidth, 1, func(s string) fixed.Int26_6 { return r.measure(siteFaces, s) })
+	if len(siteName) > 0 {
+		r.drawText(dst, siteFaces, siteName[0], left, bottom, color.NRGBA{255, 255, 255, 0xdd})
+		bottom -= siteNameSize + 28
+	}
+
+	tagFaces, err := r.faces(tagSize)
+	if err != nil {
+		return nil, err
+	}
+	x := left
+	for i, tag := range card.Tags {
+		tag = strings.TrimSpace(tag)
+		if i >= maxTags || tag == "" {
+			continue
+		}
+		label := "#" + tag
+		width := r.measure(tagFaces, label).Ceil()
+		if x+width+24 > panel.Max.X-padding {
+			break
+		}
+		chip := image.Rect(x, bottom-tagSize-8, x+width+24, bottom+12)
+		draw.DrawMask(dst, chip, image.NewUniform(color.NRGBA{255, 255, 255, 0x38}), image.Point{},
+			&roundedRect{rect: chip, radius: chip.Dy() / 2}, chip.Min, draw.Over)
+		r.drawText(dst, tagFaces, label, x+12, bottom, white)
+		x = chip.Max.X + 14
+	}
+
+	return dst, nil
+}
+
+// faces 为每个字体创建指定字号的 font.Face
+func (r *Renderer) faces(size float64) ([]font.Face, error) {
+	faces := make([]font.Face, len(r.fonts))
+	for i, f := range r.fonts {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create font face: %w", err)
+		}
+		faces[i] = face
+	}
+	return faces, nil
+}
+
+// Covers 判断字体是否包含文字中除空白外全部字符的字形，缺少字形的字符会显示为方框
+func (r *Renderer) Covers(s string) bool {
+	var buf sfnt.Buffer
+	for _, c := range s {
+		if unicode.IsSpace(c) {
+			continue
+		}
+		found := false
+		for _, f := range r.fonts {
+			if glyph, err := f.GlyphIndex(&buf, c); err == nil && glyph != 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// run 使用同一字体绘制的一段文字
+type run struct {
+	face font.Face
+	text string
+}
+
+// runs 按字体拆分文字，每个字符使用第一个包含该字形的字体，都不包含时使用第一个字体
+func (r *Renderer) runs(faces []font.Face, s string) []run {
+	var buf sfnt.Buffer
+	var result []run
+	for _, c := range s {
+		index := 0
+		for i, f := range r.fonts {
+			if glyph, err := f.GlyphIndex(&buf, c); err == nil && glyph != 0 {
+				index = i
+				break
+			}
+		}
+		if n := len(result); n > 0 && result[n-1].face == faces[index] {
+			result[n-1].text += string(c)
+		} else {
+			result = append(result, run{face: faces[index], text: string(c)})
+		}
+	}
+	return result
+}
+
+// measure 计算文字的绘制宽度
+func (r *Renderer) measure(faces []font.Face, s string) fixed.Int26_6 {
+	var width fixed.Int26_6
+	for _, part := range r.runs(faces, s) {
+		width += font.MeasureString(part.face, part.text)
+	}
+	return width
+}
+
+// drawText 以 (x, baseline) 为起点绘制一行文字
+func (r *Renderer) drawText(dst draw.Image, faces []font.Face, s string, x, baseline int, c color.Color) {
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Dot: fixed.P(x, baseline)}
+	for _, part := range r.runs(faces, s) {
+		d.Face = part.face
+		d.DrawString(part.text)
+	}
+}
+
+// wrap 将文字按宽度折行，英文单词不拆开，中日韩文字可在任意字符间折行
+// 超过 maxLines 行时截断最后一行并加上省略号，truncated 表示是否发生了截断
+func wrap(text string, maxWidth fixed.Int26_6, maxLines int, measure func(string) fixed.Int26_6) (lines []string, truncated bool) {
+	var current string
+	flush := func() {
+		lines = append(lines, strings.TrimSpace(current))
+		current = ""
+	}
+	for _, token := range tokenize(text) {
+		if current == "" && token == " " {
+			continue
+		}
+		if measure(current+token) <= maxWidth {
+			current += token
+			continue
+		}
+		if strings.TrimSpace(current) != "" {
+			flush()
+			if token == " " {
+				continue
+			}
+		}
+		// 单个单词比一行还宽时按字符拆开
+		for measure(token) > maxWidth && utf8.RuneCountInString(token) > 1 {
+			cut := len(token)
+			for cut > 0 && measure(token[:cut]) > maxWidth {
+				_, size := utf8.DecodeLastRuneInString(token[:cut])
+				cut -= size
+			}
+			if cut == 0 {
+				_, cut = utf8.DecodeRuneInString(token)
+			}
+			current = token[:cut]
+			flush()
+			token = token[cut:]
+		}
+		current = token
+	}
+	if strings.TrimSpace(current) != "" {
+		flush()
+	}
+
+	if len(lines) <= maxLines {
+		return lines, false
+	}
+	lines = lines[:maxLines]
+	last := []rune(lines[maxLines-1])
+	for len(last) > 0 && measure(string(last)+"…") > maxWidth {
+		last = last[:len(last)-1]
+	}
+	lines[maxLines-1] = strings.TrimSpace(string(last)) + "…"
+	return lines, true
+}
+
+// tokenize 将文字拆分为不可再分的片段：连续的字母数字、单个空格或单个其他字符
+func tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flushWord := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, c := range text {
+		switch {
+		case unicode.IsSpace(c):
+			flushWord()
+			tokens = append(tokens, " ")
+		case c < utf8.RuneSelf && !unicode.IsSpace(c):
+			word.WriteRune(c)
+		default:
+			flushWord()
+			tokens = append(tokens, string(c))
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// drawBackground 将背景图片按 cover 方式铺满并模糊，没有背景图片时绘制渐变
+func drawBackground(dst *image.RGBA, background image.Image, blur int) {
+	if background == nil || background.Bounds().Empty() {
+		drawGradient(dst, color.NRGBA{94, 96, 206, 255}, color.NRGBA{220, 138, 221, 255})
+		return
+	}
+
+	if blur > maxBlur {
+		blur = maxBlur
+	}
+	// 先缩小再模糊并放大，大半径模糊也只需处理很少的像素
+	scale := 1
+	if blur >= 8 {
+		scale = blur / 4
+	}
+	small := image.NewRGBA(image.Rect(0, 0, Width/scale, Height/scale))
+	src := background.Bounds()
+	crop := coverRect(src, small.Bounds().Dx(), small.Bounds().Dy())
+	draw.CatmullRom.Scale(small, small.Bounds(), background, crop, draw.Src, nil)
+	if blur > 0 {
+		boxBlur(small, max(1, blur/scale))
+	}
+	draw.BiLinear.Scale(dst, dst.Bounds(), small, small.Bounds(), draw.Src, nil)
+}
+
+// coverRect 返回在 src 中居中裁剪出的、宽高比与 width:height 一致的区域
+func coverRect(src image.Rectangle, width, height int) image.Rectangle {
+	w, h := src.Dx(), src.Dy()
+	if w*height > h*width {
+		cropW := h * width / height
+		x := src.Min.X + (w-cropW)/2
+		return image.Rect(x, src.Min.Y, x+cropW, src.Max.Y)
+	}
+	cropH := w * height / width
+	y := src.Min.Y + (h-cropH)/2
+	return image.Rect(src.Min.X, y, src.Max.X, y+cropH)
+}
+
+// drawGradient 绘制从左上到右下的渐变
+func drawGradient(dst *image.RGBA, from, to color.NRGBA) {
+	b := dst.Bounds()
+	total := b.Dx() + b.Dy()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			t := float64(x-b.Min.X+y-b.Min.Y) / float64(total)
+			dst.SetRGBA(x, y, color.RGBA{
+				R: lerp(from.R, to.R, t),
+				G: lerp(from.G, to.G, t),
+				B: lerp(from.B, to.B, t),
+				A: 255,
+			})
+		}
+	}
+}
+
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
+
+// boxBlur 三次水平与垂直方向的盒式模糊，近似高斯模糊
+func boxBlur(img *image.RGBA, radius int) {
+	b := img.Bounds()
+	tmp := make([]uint8, len(img.Pix))
+	for pass := 0; pass < 3; pass++ {
+		blurLine(img.Pix, tmp, b.Dx(), b.Dy(), img.Stride, 4, radius)
+		blurLine(tmp, img.Pix, b.Dy(), b.Dx(), 4, img.Stride, radius)
+	}
+}
+
+// blurLine 对 lines 条长度为 length 的像素线做一次盒式模糊，step 为线内相邻像素的间距，lineStep 为相邻线的间距
+// 结果写入 dst 中相同的位置；第二次调用交换两个间距即为另一个方向
+func blurLine(src, dst []uint8, length, lines, lineStep, step, radius int) {
+	window := 2*radius + 1
+	for line := 0; line < lines; line++ {
+		base := line * lineStep
+		for c := 0; c < 4; c++ {
+			at := func(i int) int {
+				if i < 0 {
+					i = 0
+				} else if i >= length {
+					i = length - 1
+				}
+				return int(src[base+i*step+c])
+			}
+			sum := 0
+			for i := -radius; i <= radius; i++ {
+				sum += at(i)
+			}
+			for i := 0; i < length; i++ {
+				dst[base+i*step+c] = uint8(sum / window)
+				sum += at(i+radius+1) - at(i-radius)
+			}
+		}
+	}
+}
+
+// roundedRect 圆角矩形遮罩
+type roundedRect struct {
+	rect   image.Rectangle
+	radius int
+}
+
+func (m *roundedRect) ColorModel() color.Model { return color.AlphaModel }
+
+func (m *roundedRect) Bounds() image.Rectangle { return m.rect }
+
+func (m *roundedRect) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(m.rect)) {
+		return color.Alpha{}
+	}
+	// 只有四个角需要判断与圆心的距离
+	cx, cy := x, y
+	if x < m.rect.Min.X+m.radius {
+		cx = m.rect.Min.X + m.radius
+	} else if x >= m.rect.Max.X-m.radius {
+		cx = m.rect.Max.X - m.radius - 1
+	}
+	if y < m.rect.Min.Y+m.radius {
+		cy = m.rect.Min.Y + m.radius
+	} else if y >= m.rect.Max.Y-m.radius {
+		cy = m.rect.Max.Y - m.radius - 1
+	}
+	dx, dy := x-cx, y-cy
+	if dx*dx+dy*dy > m.radius*m.radius {
+		return color.Alpha{}
+	}
+	return color.Alpha{A: 255}
+}
+
+// ParseColor 解析 CSS 颜色，支持 #rgb、#rrggbb、rgb() 与 rgba()
+func ParseColor(value string) (color.NRGBA, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if strings.HasPrefix(value, "#") {
+		hex := value[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return color.NRGBA{}, false
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		return color.NRGBA{uint8(n >> 16), uint8(n >> 8), uint8(n), 255}, true
+	}
+
+	var inner string
+	switch {
+	case strings.HasPrefix(value, "rgba(") && strings.HasSuffix(value, ")"):
+		inner = value[len("rgba(") : len(value)-1]
+	case strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")"):
+		inner = value[len("rgb(") : len(value)-1]
+	default:
+		return color.NRGBA{}, false
+	}
+	parts := strings.Split(inner, ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.NRGBA{}, false
+	}
+	var channels [3]uint8
+	for i := 0; i < 3; i++ {
+		n, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil || n < 0 || n > 255 {
+			return color.NRGBA{}, false
+		}
+		channels[i] = uint8(n)
+	}
+	alpha := 1.0
+	if len(parts) == 4 {
+		a, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		if err != nil || a < 0 || a > 1 {
+			return color.NRGBA{}, false
+		}
+		alpha = a
+	}
+	return color.NRGBA{channels[0], channels[1], channels[2], uint8(alpha*255 + 0.5)}, true
+}
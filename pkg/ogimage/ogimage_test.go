@@ -0,0 +1,150 @@
+package ogimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/math/fixed"
+)
+
+// runeWidth 每个字符宽 10 像素的测量函数
+func runeWidth(s string) fixed.Int26_6 {
+	return fixed.I(10 * utf8.RuneCountInString(s))
+}
+
+// TestWrap 测试英文按单词折行、中文按字符折行以及超出行数时截断
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		width     int
+		lines     int
+		want      []string
+		truncated bool
+	}{
+		{"words", "hello go world", 80, 3, []string{"hello go", "world"}, false},
+		{"cjk", "中文标题可以折行", 50, 3, []string{"中文标题可", "以折行"}, false},
+		{"mixed", "使用 Go 语言", 40, 3, []string{"使用", "Go 语", "言"}, false},
+		{"long word", "abcdefghijkl", 50, 3, []string{"abcde", "fghij", "kl"}, false},
+		{"truncated", "一二三四五六七八九十", 40, 2, []string{"一二三四", "五六七…"}, true},
+		{"empty", "", 40, 2, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := wrap(tt.text, fixed.I(tt.width), tt.lines, runeWidth)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || truncated != tt.truncated {
+				t.Errorf("wrap() = %q, %v, want %q, %v", got, truncated, tt.want, tt.truncated)
+			}
+		})
+	}
+}
+
+// TestParseColor 测试 CSS 颜色解析
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  color.NRGBA
+		ok    bool
+	}{
+		{"rgba(220, 138, 221, 0.2)", color.NRGBA{220, 138, 221, 51}, true},
+		{"rgb(1,2,3)", color.NRGBA{1, 2, 3, 255}, true},
+		{"#FFF", color.NRGBA{255, 255, 255, 255}, true},
+		{"#0a0b0c", color.NRGBA{10, 11, 12, 255}, true},
+		{"rgba(300, 0, 0, 1)", color.NRGBA{}, false},
+		{"red", color.NRGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseColor(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestRender 测试带背景与不带背景的卡片都能生成正确尺寸的 PNG
+func TestRender(t *testing.T) {
+	r, err := NewRenderer(goregular.TTF)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+
+	background := image.NewRGBA(image.Rect(0, 0, 800, 800))
+	for y := 0; y < 800; y++ {
+		for x := 0; x < 800; x++ {
+			background.Set(x, y, color.RGBA{uint8(x / 4), uint8(y / 4), 128, 255})
+		}
+	}
+
+	for _, card := range []Card{
+		{Title: "A very long title that needs to wrap across several lines on the card", SiteName: "Blog", Tags: []string{"go", "测试"}, Background: background, Blur: 20},
+		{Title: "中文标题", SiteName: "我的博客"},
+	} {
+		var buf bytes.Buffer
+		if err := r.Encode(&buf, card); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("png.Decode() error = %v", err)
+		}
+		if img.Bounds().Dx() != Width || img.Bounds().Dy() != Height {
+			t.Errorf("size = %v, want %dx%d", img.Bounds(), Width, Height)
+		}
+	}
+
+	if !r.Covers("Hello, world") || r.Covers("中文标题") {
+		t.Error("Covers() should report only glyphs present in the fonts")
+	}
+
+	if _, err := NewRenderer([]byte("not a font")); err == nil {
+		t.Error("NewRenderer() with invalid font should fail")
+	}
+}
+
+// TestRenderEmbeddedChinese 测试使用内置字体绘制中文标题
+func TestRenderEmbeddedChinese(t *testing.T) {
+	var fonts [][]byte
+	for _, f := range EmbeddedFonts() {
+		fonts = append(fonts, f.Data)
+	}
+	r, err := NewRenderer(fonts...)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+	if !r.Covers("中文标题") {
+		t.Skip("fonts 目录中没有嵌入中文字体")
+	}
+
+	blank, err := r.Render(Card{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	titled, err := r.Render(Card{Title: "中文标题", SiteName: "我的博客", Tags: []string{"随笔"}})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if bytes.Equal(blank.Pix, titled.Pix) {
+		t.Error("Render() drew nothing for a Chinese title")
+	}
+}
+
+// TestBoxBlur 测试模糊后边缘被平滑
+func TestBoxBlur(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 10; x < 20; x++ {
+			img.Set(x, y, color.RGBA{255, 255, 255, 255})
+		}
+	}
+	boxBlur(img, 2)
+	left, edge, right := img.RGBAAt(0, 10).R, img.RGBAAt(10, 10).R, img.RGBAAt(19, 10).R
+	if left != 0 || right != 255 || edge == 0 || edge == 255 {
+		t.Errorf("blurred row = %d, %d, %d", left, edge, right)
+	}
+}
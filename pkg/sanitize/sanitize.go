@@ -0,0 +1,458 @@
+// Package sanitize 按策略过滤 HTML 片段
+// 过滤在词法层面逐个标签进行，不重建文档树，因此可以处理 markdown 中被拆开的原始 HTML 片段，
+// 未被修改的标签按原样输出，文本解码后重新转义，分词器按纯文本读出的内容不会在输出中变回标签
+package sanitize
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// 策略名称
+const (
+	NameTrusted = "trusted" // 管理员：允许原始 HTML，去掉脚本
+	NameEditor  = "editor"  // 编辑：安全的排版子集
+	NameComment = "comment" // 评论：最小子集
+)
+
+// Policy HTML 过滤策略
+type Policy struct {
+	Name string
+
+	// elements 允许的元素及其属性，为 nil 时允许任意元素与属性
+	elements map[string]map[string]bool
+	// globalAttrs 所有允许的元素都可以使用的属性
+	globalAttrs map[string]bool
+	// blocked 任何情况下都移除的元素，内容一并移除
+	blocked map[string]bool
+	// scriptSources 允许的外部脚本地址前缀，只在允许任意元素时生效
+	scriptSources []string
+	// iframeSources 允许嵌入的 iframe 地址前缀，为 nil 时不限制
+	iframeSources []string
+	// linkRel 为链接追加的 rel
+	linkRel string
+	// newlines 将文本中的换行转换为 <br>
+	newlines bool
+}
+
+// dropContent 不允许时连同内容一起移除的元素，其余不允许的元素只移除标签、保留文本
+var dropContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "applet": true,
+	"noscript": true, "noembed": true, "noframes": true, "template": true, "title": true,
+	"xmp": true, "plaintext": true, "frame": true, "frameset": true, "select": true, "textarea": true,
+}
+
+// textEscaper 文本的转义
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\u00a0", "&nbsp;")
+
+// rawTextElements 词法分析时内容按纯文本处理的元素
+// 在 svg 与 math 的外来内容中浏览器会把它们的内容当作标签解析，例如
+// <math><mtext><table><mglyph><style><img onerror>，外来内容还可能由前面的片段打开，因此无法只凭当前片段判断
+var rawTextElements = map[string]bool{
+	"iframe": true, "noembed": true, "noframes": true, "noscript": true, "plaintext": true,
+	"script": true, "style": true, "textarea": true, "title": true, "xmp": true,
+}
+
+// urlAttrs 值为地址的属性
+var urlAttrs = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true, "poster": true, "cite": true,
+	"background": true, "data": true, "xlink:href": true, "longdesc": true, "manifest": true,
+}
+
+// Trusted 管理员策略：允许任意原始 HTML，去掉脚本、事件属性、javascript: 地址以及内容中含有标签的 <style> 等纯文本元素
+// scriptSources 中列出的地址前缀的外部脚本会被保留，内联脚本始终移除
+func Trusted(scriptSources []string) *Policy {
+	return &Policy{
+		Name:          NameTrusted,
+		blocked:       map[string]bool{"base": true, "meta": true},
+		scriptSources: scriptSources,
+	}
+}
+
+// Editor 编辑策略：常用的排版元素、图片、音视频与表格，不允许样式、表单与脚本
+// iframeSources 中列出的地址前缀的 iframe 会被保留并加上 sandbox
+func Editor(iframeSources []string) *Policy {
+	elements := map[string]map[string]bool{}
+	for _, name := range strings.Fields(`abbr b bdi bdo blockquote br caption center cite code col colgroup dd del
+		details dfn div dl dt em figcaption figure h1 h2 h3 h4 h5 h6 hr i ins kbd li mark ol p picture pre q rp rt
+		ruby s samp small span strong sub summary sup table tbody td tfoot th thead time tr u ul var wbr`) {
+		elements[name] = map[string]bool{}
+	}
+	attrs := map[string]string{
+		"a":          "href name target rel hreflang",
+		"img":        "src srcset sizes alt width height loading decoding",
+		"video":      "src poster controls width height loop muted preload playsinline",
+		"audio":      "src controls loop muted preload",
+		"source":     "src srcset type media sizes",
+		"track":      "src kind srclang label default",
+		"td":         "colspan rowspan align valign headers",
+		"th":         "colspan rowspan align valign headers scope abbr",
+		"col":        "span",
+		"colgroup":   "span",
+		"ol":         "start reversed type",
+		"li":         "value",
+		"details":    "open",
+		"time":       "datetime",
+		"q":          "cite",
+		"blockquote": "cite",
+		"del":        "cite datetime",
+		"ins":        "cite datetime",
+		"iframe":     "src width height allowfullscreen frameborder loading referrerpolicy",
+	}
+	for name, list := range attrs {
+		elements[name] = set(list)
+	}
+	if iframeSources == nil {
+		iframeSources = []string{}
+	}
+	return &Policy{
+		Name:          NameEditor,
+		elements:      elements,
+		globalAttrs:   set("id class title lang dir align"),
+		iframeSources: iframeSources,
+		linkRel:       "noopener",
+	}
+}
+
+// Comment 评论策略：只保留强调、代码、引用与链接，换行转换为 <br>，链接加上 nofollow
+func Comment() *Policy {
+	return &Policy{
+		Name: NameComment,
+		elements: map[string]map[string]bool{
+			"a": set("href"), "b": {}, "strong": {}, "i": {}, "em": {}, "code": {}, "pre": {},
+			"blockquote": {}, "br": {}, "p": {}, "del": {}, "s": {},
+		},
+		linkRel:  "nofollow ugc noopener",
+		newlines: true,
+	}
+}
+
+// set 由空白分隔的名称生成集合
+func set(names string) map[string]bool {
+	m := make(map[string]bool)
+	for _, name := range strings.Fields(names) {
+		m[name] = true
+	}
+	return m
+}
+
+// Sanitize 过滤 HTML 片段
+func (p *Policy) Sanitize(fragment string) string {
+	out, _ := p.Clean(fragment)
+	return out
+}
+
+// Clean 过滤 HTML 片段，同时返回被移除或改写的内容说明，输出未变化时说明为空
+func (p *Policy) Clean(fragment string) (string, []string) {
+	var out bytes.Buffer
+	notes := map[string]bool{}
+	note := func(format string, args ...interface{}) {
+		notes[fmt.Sprintf(format, args...)] = true
+	}
+
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	skip := ""        // 正在跳过内容的元素
+	pending := ""     // 等待检查内容的纯文本元素的开始标签
+	pendingName := "" // 等待检查内容的纯文本元素
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				note("无法解析的 HTML")
+			}
+			break
+		}
+		raw := z.Raw()
+
+		if skip != "" {
+			if tt == html.EndTagToken {
+				if name, _ := z.TagName(); string(name) == skip {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		if pending != "" {
+			// 纯文本元素的内容中含有 < 时，浏览器在外来内容中可能将其解析为标签，连同元素一起移除
+			if tt == html.TextToken && bytes.Contains(raw, []byte("<")) {
+				note("<%s> 中的标签", pendingName)
+				skip = pendingName
+				pending = ""
+				continue
+			}
+			out.WriteString(pending)
+			pending = ""
+			if tt == html.TextToken {
+				// 保留的纯文本元素（如 <style>）的内容原样输出，转义会改变样式与脚本
+				out.Write(raw)
+				continue
+			}
+		}
+
+		switch tt {
+		case html.TextToken:
+			text := textEscaper.Replace(string(z.Text()))
+			if p.newlines && strings.Contains(text, "\n") {
+				text = strings.ReplaceAll(strings.TrimRight(text, "\r"), "\n", "<br>")
+			}
+			out.WriteString(text)
+		case html.CommentToken, html.DoctypeToken:
+			if p.elements != nil {
+				continue
+			}
+			out.Write(raw)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			tag, ok := p.filterTag(&tok, note)
+			if !ok {
+				if dropContent[tok.Data] && tt == html.StartTagToken {
+					skip = tok.Data
+				}
+				continue
+			}
+			if tag == "" {
+				tag = string(raw)
+			}
+			if p.elements == nil && rawTextElements[tok.Data] && tt == html.StartTagToken {
+				pending, pendingName = tag, tok.Data
+				continue
+			}
+			out.WriteString(tag)
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if p.allowedElement(string(name)) {
+				out.Write(raw)
+			}
+		}
+	}
+
+	out.WriteString(pending)
+
+	list := make([]string, 0, len(notes))
+	for n := range notes {
+		list = append(list, n)
+	}
+	sort.Strings(list)
+	return out.String(), list
+}
+
+// allowedElement 判断元素在结束标签时是否保留
+func (p *Policy) allowedElement(name string) bool {
+	if p.blocked[name] {
+		return false
+	}
+	if p.elements == nil {
+		return true
+	}
+	_, ok := p.elements[name]
+	return ok
+}
+
+// filterTag 过滤开始标签的属性，返回改写后的标签；标签未改动时返回空字符串，不允许时 ok 为 false
+func (p *Policy) filterTag(tok *html.Token, note func(string, ...interface{})) (tag string, ok bool) {
+	name := tok.Data
+	if !p.allowedElement(name) {
+		note("<%s>", name)
+		return "", false
+	}
+
+	switch name {
+	case "script":
+		// 只有允许任意元素的策略会走到这里，外部脚本需要在白名单中
+		if !hasPrefix(attr(tok, "src"), p.scriptSources) {
+			note("<script>")
+			return "", false
+		}
+	case "iframe":
+		if p.iframeSources != nil && !hasPrefix(attr(tok, "src"), p.iframeSources) {
+			note("<iframe src=%q>", attr(tok, "src"))
+			return "", false
+		}
+	}
+
+	changed := false
+	kept := tok.Attr[:0]
+	for _, a := range tok.Attr {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" {
+			key = a.Namespace + ":" + key
+		}
+		switch {
+		case strings.HasPrefix(key, "on"), key == "srcdoc":
+			note("%s 属性", key)
+			changed = true
+			continue
+		case p.elements != nil && !p.globalAttrs[key] && !p.elements[name][key]:
+			note("%s 属性", key)
+			changed = true
+			continue
+		case urlAttrs[key] && !p.AllowURL(a.Val, name == "img" && key == "src"):
+			note("%s 地址", Scheme(a.Val))
+			changed = true
+			continue
+		case key == "srcset" && !p.allowSrcset(a.Val):
+			note("srcset 地址")
+			changed = true
+			continue
+		case p.elements == nil && isScriptURL(a.Val):
+			// SVG 动画等可以把任意属性设置为地址
+			note("%s 地址", Scheme(a.Val))
+			changed = true
+			continue
+		case key == "target" && p.elements != nil && a.Val != "_blank":
+			note("target 属性")
+			changed = true
+			continue
+		}
+		kept = append(kept, a)
+	}
+	tok.Attr = kept
+
+	if name == "a" && p.linkRel != "" && attr(tok, "href") != "" {
+		if rel := mergeRel(attr(tok, "rel"), p.linkRel); rel != attr(tok, "rel") {
+			setAttr(tok, "rel", rel)
+			changed = true
+		}
+	}
+	if name == "iframe" && p.elements != nil && attr(tok, "sandbox") == "" {
+		setAttr(tok, "sandbox", "allow-scripts allow-same-origin allow-popups allow-presentation")
+		changed = true
+	}
+
+	if !changed {
+		return "", true
+	}
+	return tok.String(), true
+}
+
+// attr 获取属性值
+func attr(tok *html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// setAttr 设置属性值
+func setAttr(tok *html.Token, key, val string) {
+	for i, a := range tok.Attr {
+		if strings.EqualFold(a.Key, key) {
+			tok.Attr[i].Val = val
+			return
+		}
+	}
+	tok.Attr = append(tok.Attr, html.Attribute{Key: key, Val: val})
+}
+
+// mergeRel 在原有 rel 中补上缺少的值
+func mergeRel(rel, extra string) string {
+	values := strings.Fields(rel)
+	for _, v := range strings.Fields(extra) {
+		found := false
+		for _, existing := range values {
+			if strings.EqualFold(existing, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, v)
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// hasPrefix 判断地址是否以任一前缀开头
+func hasPrefix(u string, prefixes []string) bool {
+	if u == "" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(u, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheme 取地址的协议部分，用于说明
+func Scheme(u string) string {
+	s := normalizeURL(u)
+	if i := strings.IndexByte(s, ':'); i > 0 {
+		return s[:i+1]
+	}
+	return s
+}
+
+// normalizeURL 去掉浏览器会忽略的空白与控制字符并转为小写，用于判断协议
+func normalizeURL(u string) string {
+	var b strings.Builder
+	for _, r := range u {
+		if r <= ' ' || r == 0x7f {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
+
+// isScriptURL 判断是否为可以执行脚本的地址
+func isScriptURL(u string) bool {
+	s := normalizeURL(u)
+	return strings.HasPrefix(s, "javascript:") || strings.HasPrefix(s, "vbscript:") || strings.HasPrefix(s, "livescript:")
+}
+
+// SafeURL 判断地址是否可以出现在链接与图片中：相对地址、http(s)、mailto、tel，图片另外允许 data:image
+func SafeURL(u string, image bool) bool {
+	return safeURL(u, image)
+}
+
+func safeURL(u string, image bool) bool {
+	s := normalizeURL(u)
+	colon := strings.IndexByte(s, ':')
+	if colon < 0 {
+		return true
+	}
+	// 冒号出现在路径、查询或锚点之后时不是协议
+	if i := strings.IndexAny(s, "/?#"); i >= 0 && i < colon {
+		return true
+	}
+	switch s[:colon] {
+	case "http", "https", "mailto", "tel":
+		return true
+	case "data":
+		return image && strings.HasPrefix(s, "data:image/") && !strings.HasPrefix(s, "data:image/svg")
+	}
+	return false
+}
+
+// AllowURL 判断策略是否允许该地址，允许任意元素的策略只拒绝脚本地址与非图片的 data: 地址
+func (p *Policy) AllowURL(u string, image bool) bool {
+	if p.elements != nil {
+		return safeURL(u, image)
+	}
+	s := normalizeURL(u)
+	if strings.HasPrefix(s, "data:") {
+		return image && strings.HasPrefix(s, "data:image/")
+	}
+	return !isScriptURL(u)
+}
+
+// allowSrcset 检查 srcset 中的每个地址
+func (p *Policy) allowSrcset(srcset string) bool {
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 && !p.AllowURL(fields[0], true) {
+			return false
+		}
+	}
+	return true
+}
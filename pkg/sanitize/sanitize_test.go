@@ -0,0 +1,116 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrusted(t *testing.T) {
+	p := Trusted([]string{"https://gist.github.com/"})
+	tests := []struct {
+		in, want string
+	}{
+		// 未改动的 HTML 原样输出
+		{`<div style="color:red" data-x='1'><iframe src="https://x.test/v"></iframe></div>`, `<div style="color:red" data-x='1'><iframe src="https://x.test/v"></iframe></div>`},
+		{`<p>a</p><script>alert(1)</script><p>b</p>`, `<p>a</p><p>b</p>`},
+		{`<script src="https://evil.test/x.js"></script>`, ``},
+		{`<script src="https://gist.github.com/u/1.js"></script>`, `<script src="https://gist.github.com/u/1.js"></script>`},
+		{`<img src=x onerror="alert(1)">`, `<img src="x">`},
+		{`<a href=" JaVa&#x09;script:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="magnet:?xt=urn:btih:1">x</a>`, `<a href="magnet:?xt=urn:btih:1">x</a>`},
+		{`<iframe srcdoc="<script>alert(1)</script>"></iframe>`, `<iframe></iframe>`},
+		{`<svg><animate attributeName="href" to="javascript:alert(1)"/></svg>`, `<svg><animate attributename="href"/></svg>`},
+		{`<base href="https://evil.test/"><meta http-equiv="refresh" content="0">`, ``},
+		{`<!-- more -->`, `<!-- more -->`},
+		// 纯文本元素的内容在外来内容中会被解析为标签
+		{`<div><math><mtext><table><mglyph><style><img src=x onerror=alert(1)>`, `<div><math><mtext><table><mglyph>`},
+		{"<svg>\n\n<style><a title=\"</style><img src=x onerror=alert(1)>\">", `<svg>` + "\n\n" + `<img src="x">"&gt;`},
+		{`<svg><title>Icon</title><style>.a{fill:red}</style></svg><style>p{color:red}</style>`, `<svg><title>Icon</title><style>.a{fill:red}</style></svg><style>p{color:red}</style>`},
+		{`<noscript><img src="a.png"></noscript>`, ``},
+		{`<p>a &amp; b &lt;c&gt;&nbsp;"q"</p>`, `<p>a &amp; b &lt;c&gt;&nbsp;"q"</p>`},
+	}
+	for _, tt := range tests {
+		if got := p.Sanitize(tt.in); got != tt.want {
+			t.Errorf("Sanitize(%s)\n got %s\nwant %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEditor(t *testing.T) {
+	p := Editor([]string{"https://player.bilibili.com/"})
+	tests := []struct {
+		in, want string
+	}{
+		{`<details open><summary>more</summary><p class="x">text</p></details>`, `<details open><summary>more</summary><p class="x">text</p></details>`},
+		{`<span style="position:fixed">x</span>`, `<span>x</span>`},
+		{`<form action="/login"><input name="p"></form>ok`, `ok`},
+		{`<style>body{display:none}</style>ok`, `ok`},
+		{`<iframe src="https://evil.test/"></iframe>ok`, `ok`},
+		{`<iframe src="https://player.bilibili.com/player.html?bvid=1"></iframe>`, `<iframe src="https://player.bilibili.com/player.html?bvid=1" sandbox="allow-scripts allow-same-origin allow-popups allow-presentation"></iframe>`},
+		{`<a href="/passage/a" target="_blank">a</a>`, `<a href="/passage/a" target="_blank" rel="noopener">a</a>`},
+		{`<img src="data:image/png;base64,AAAA" alt="">`, `<img src="data:image/png;base64,AAAA" alt="">`},
+		{`<img src="data:image/svg+xml;base64,AAAA">`, `<img>`},
+		{`<a href="data:text/html,x">x</a>`, `<a>x</a>`},
+	}
+	for _, tt := range tests {
+		if got := p.Sanitize(tt.in); got != tt.want {
+			t.Errorf("Sanitize(%s)\n got %s\nwant %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestComment(t *testing.T) {
+	p := Comment()
+	got, notes := p.Clean("第一行\n<b>粗体</b> <a href=\"https://example.com\" onclick=\"x()\">链接</a>\n<img src=x onerror=alert(1)><script>alert(1)</script>")
+	want := `第一行<br><b>粗体</b> <a href="https://example.com" rel="nofollow ugc noopener">链接</a><br>`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if strings.Join(notes, ",") != "<img>,<script>,onclick 属性" {
+		t.Errorf("notes = %v", notes)
+	}
+
+	if _, notes := p.Clean("普通文本 1 < 2 & 3 > 2"); len(notes) != 0 {
+		t.Errorf("plain text reported changes: %v", notes)
+	}
+}
+
+// TestRawTextElements 测试分词器按纯文本读取内容的元素不会把其中的标签带回输出
+func TestRawTextElements(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`<plaintext><script>alert(1)</script>`, ``},
+		{`<p>a<plaintext></p><img src=x onerror=alert(1)>`, `<p>a`},
+		{`<p><xmp><script>alert(1)</script></xmp>ok</p>`, `<p>ok</p>`},
+		{`<blockquote><noembed><img src=x onerror=alert(1)></noembed>ok</blockquote>`, `<blockquote>ok</blockquote>`},
+		{`<p><noframes><script>alert(1)</script></noframes>ok</p>`, `<p>ok</p>`},
+		{`<p><b>1 &lt;script&gt; 2</b></p>`, `<p><b>1 &lt;script&gt; 2</b></p>`},
+	}
+	for _, p := range []*Policy{Comment(), Editor(nil)} {
+		for _, tt := range tests {
+			if got := p.Sanitize(tt.in); got != tt.want {
+				t.Errorf("%s: Sanitize(%s)\n got %s\nwant %s", p.Name, tt.in, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	for u, want := range map[string]bool{
+		"/a/b":                  true,
+		"#top":                  true,
+		"a.png":                 true,
+		"./x:y":                 true,
+		"https://example.com":   true,
+		"mailto:a@example.com":  true,
+		"javascript:alert(1)":   false,
+		"  javascript:alert(1)": false,
+		"vbscript:x":            false,
+		"ftp://example.com":     false,
+	} {
+		if got := SafeURL(u, false); got != want {
+			t.Errorf("SafeURL(%q) = %v, want %v", u, got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BM25 参数与字段权重
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	titleWeight = 3
+	tagWeight   = 2
+	bodyWeight  = 1
+)
+
+// Document 被索引的文章
+type Document struct {
+	ID         int
+	Title      string
+	Summary    string
+	Body       string // 纯文本正文
+	Category   string
+	Tags       []string
+	Status     string
+	Visibility string
+	CreatedAt  time.Time
+}
+
+// Public 是否对未登录用户和普通用户可见
+func (d *Document) Public() bool {
+	return d.Status == "published" && (d.Visibility == "" || d.Visibility == "public")
+}
+
+// Query 检索条件
+type Query struct {
+	Text          string
+	Tags          []string  // 必须同时包含的标签
+	Category      string    // 分类，空表示不限
+	Since         time.Time // 起始时间（含），零值表示不限
+	Until         time.Time // 截止时间（不含），零值表示不限
+	IncludeHidden bool      // 是否包含未发布和私密文章（仅管理员）
+	Limit         int
+	Offset        int
+}
+
+// Hit 单条检索结果
+type Hit struct {
+	Document     *Document
+	Score        float64
+	Snippet      string // 已转义的 HTML，命中词用 <mark> 标记
+	TitleSnippet string // 已转义的 HTML 标题
+}
+
+// fieldFreq 词项在各字段中出现的次数
+type fieldFreq struct {
+	title int
+	tags  int
+	body  int
+}
+
+// weighted 按字段权重合并后的词频
+func (f fieldFreq) weighted() float64 {
+	return float64(f.title*titleWeight + f.tags*tagWeight + f.body*bodyWeight)
+}
+
+// entry 已索引文章及其加权长度
+type entry struct {
+	doc    *Document
+	length float64
+	terms  []string
+}
+
+// Index 内存倒排索引，并发安全
+type Index struct {
+	mu          sync.RWMutex
+	docs        map[int]*entry
+	postings    map[string]map[int]fieldFreq
+	totalLength float64
+}
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[int]*entry),
+		postings: make(map[string]map[int]fieldFreq),
+	}
+}
+
+// Len 返回已索引的文章数
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Add 索引文章，已存在时替换
+func (idx *Index) Add(doc *Document) {
+	freqs := make(map[string]fieldFreq)
+	var length float64
+
+	for _, t := range tokenizeIndex(doc.Title) {
+		f := freqs[t.term]
+		f.title++
+		freqs[t.term] = f
+		length += titleWeight
+	}
+	for _, tag := range doc.Tags {
+		for _, t := range tokenizeIndex(tag) {
+			f := freqs[t.term]
+			f.tags++
+			freqs[t.term] = f
+			length += tagWeight
+		}
+	}
+	for _, t := range tokenizeIndex(doc.Body) {
+		f := freqs[t.term]
+		f.body++
+		freqs[t.term] = f
+		length += bodyWeight
+	}
+
+	terms := make([]string, 0, len(freqs))
+	for term := range freqs {
+		terms = append(terms, term)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(doc.ID)
+	for term, f := range freqs {
+		posting := idx.postings[term]
+		if posting == nil {
+			posting = make(map[int]fieldFreq)
+			idx.postings[term] = posting
+		}
+		posting[doc.ID] = f
+	}
+	idx.docs[doc.ID] = &entry{doc: doc, length: length, terms: terms}
+	idx.totalLength += length
+}
+
+// Remove 从索引中移除文章
+func (idx *Index) Remove(id int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(id)
+}
+
+func (idx *Index) removeLocked(id int) {
+	e, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range e.terms {
+		posting := idx.postings[term]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= e.length
+	delete(idx.docs, id)
+}
+
+// Search 执行检索，返回当前页结果和符合条件的总数
+// 有查询文本时要求所有词项都命中并按 BM25 得分排序，否则只按筛选条件过滤并按时间倒序
+func (idx *Index) Search(q Query) ([]Hit, int) {
+	terms := tokenizeQuery(q.Text)
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var hits []Hit
+	if len(terms) == 0 {
+		if strings.TrimSpace(q.Text) != "" {
+			// 查询只包含标点等无法切分的字符
+			return nil, 0
+		}
+		for _, e := range idx.docs {
+			if q.matches(e.doc) {
+				hits = append(hits, Hit{Document: e.doc})
+			}
+		}
+	} else {
+		hits = idx.rank(terms, q)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].Document.CreatedAt.Equal(hits[j].Document.CreatedAt) {
+			return hits[i].Document.CreatedAt.After(hits[j].Document.CreatedAt)
+		}
+		return hits[i].Document.ID > hits[j].Document.ID
+	})
+
+	total := len(hits)
+	if q.Offset >= total {
+		return []Hit{}, total
+	}
+	hits = hits[q.Offset:]
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+
+	// 只为当前页生成摘要
+	page := make([]Hit, len(hits))
+	for i, hit := range hits {
+		hit.Snippet = Snippet(hit.Document.Body, terms, snippetLength)
+		if hit.Snippet == "" {
+			hit.Snippet = Snippet(hit.Document.Summary, terms, snippetLength)
+		}
+		hit.TitleSnippet = Highlight(hit.Document.Title, terms)
+		page[i] = hit
+	}
+	return page, total
+}
+
+// rank 计算所有词项都命中的文章的 BM25 得分
+func (idx *Index) rank(terms []string, q Query) []Hit {
+	// 从文档数最少的词项开始求交集
+	postings := make([]map[int]fieldFreq, len(terms))
+	for i, term := range terms {
+		postings[i] = idx.postings[term]
+		if len(postings[i]) == 0 {
+			return nil
+		}
+	}
+	sort.Slice(postings, func(i, j int) bool { return len(postings[i]) < len(postings[j]) })
+
+	n := float64(len(idx.docs))
+	avgLength := idx.totalLength / n
+
+	var hits []Hit
+	for id := range postings[0] {
+		e := idx.docs[id]
+		if !q.matches(e.doc) {
+			continue
+		}
+
+		score := 0.0
+		matched := true
+		for _, posting := range postings {
+			f, ok := posting[id]
+			if !ok {
+				matched = false
+				break
+			}
+			df := float64(len(posting))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			tf := f.weighted()
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*e.length/avgLength))
+		}
+		if matched {
+			hits = append(hits, Hit{Document: e.doc, Score: score})
+		}
+	}
+	return hits
+}
+
+// matches 判断文章是否满足筛选条件
+func (q Query) matches(doc *Document) bool {
+	if !q.IncludeHidden && !doc.Public() {
+		return false
+	}
+	if q.Category != "" && !strings.EqualFold(doc.Category, q.Category) {
+		return false
+	}
+	if !q.Since.IsZero() && doc.CreatedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !doc.CreatedAt.Before(q.Until) {
+		return false
+	}
+	for _, want := range q.Tags {
+		found := false
+		for _, tag := range doc.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
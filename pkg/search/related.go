@@ -0,0 +1,154 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// 相关文章得分中各部分的权重，三部分的取值都在 [0, 1] 之间
+const (
+	RelatedTagWeight      = 0.4 // 标签重合度（Jaccard）
+	RelatedCategoryWeight = 0.2 // 同一分类
+	RelatedTextWeight     = 0.4 // 正文 TF-IDF 余弦相似度
+)
+
+// RelatedScore 一篇相关文章及其得分
+type RelatedScore struct {
+	ID    int
+	Score float64
+}
+
+// Related 计算每篇文章最相关的 limit 篇文章，得分为 0 的不计入
+// 调用方负责只传入参与推荐的文章（如已发布的公开文章），返回值按文章ID索引，结果按得分从高到低排列
+func Related(docs []*Document, limit int) map[int][]RelatedScore {
+	result := make(map[int][]RelatedScore, len(docs))
+	if limit <= 0 || len(docs) < 2 {
+		return result
+	}
+
+	vectors := tfidfVectors(docs)
+
+	// 通过倒排表累加点积，只计算至少共享一个词项的文章对
+	postings := make(map[string][]int)
+	for i, vec := range vectors {
+		for term := range vec {
+			postings[term] = append(postings[term], i)
+		}
+	}
+
+	for i, doc := range docs {
+		dots := make(map[int]float64)
+		for term, w := range vectors[i] {
+			for _, j := range postings[term] {
+				if j != i {
+					dots[j] += w * vectors[j][term]
+				}
+			}
+		}
+
+		var scores []RelatedScore
+		for j, other := range docs {
+			if j == i {
+				continue
+			}
+			score := RelatedTagWeight*tagOverlap(doc.Tags, other.Tags) + RelatedTextWeight*dots[j]
+			if doc.Category != "" && doc.Category == other.Category {
+				score += RelatedCategoryWeight
+			}
+			if score > 0 {
+				scores = append(scores, RelatedScore{ID: other.ID, Score: score})
+			}
+		}
+
+		sort.Slice(scores, func(a, b int) bool {
+			if scores[a].Score != scores[b].Score {
+				return scores[a].Score > scores[b].Score
+			}
+			return scores[a].ID > scores[b].ID // 得分相同时较新的文章优先
+		})
+		if len(scores) > limit {
+			scores = scores[:limit]
+		}
+		result[doc.ID] = scores
+	}
+	return result
+}
+
+// tfidfVectors 计算每篇文章标题与正文的 TF-IDF 向量，已归一化为单位长度
+// 词频取对数，出现在所有文章中的词项 IDF 为 0，不参与计算
+func tfidfVectors(docs []*Document) []map[string]float64 {
+	counts := make([]map[string]int, len(docs))
+	df := make(map[string]int)
+	for i, doc := range docs {
+		counts[i] = relatedTerms(doc.Title + "\n" + doc.Body)
+		for term := range counts[i] {
+			df[term]++
+		}
+	}
+
+	n := float64(len(docs))
+	vectors := make([]map[string]float64, len(docs))
+	for i := range docs {
+		vec := make(map[string]float64, len(counts[i]))
+		var norm float64
+		for term, count := range counts[i] {
+			idf := math.Log(n / float64(df[term]))
+			if idf <= 0 {
+				continue
+			}
+			w := (1 + math.Log(float64(count))) * idf
+			vec[term] = w
+			norm += w * w
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for term := range vec {
+				vec[term] /= norm
+			}
+		}
+		vectors[i] = vec
+	}
+	return vectors
+}
+
+// relatedTerms 统计文本中的词项：拉丁词与中日韩二元组，单个中日韩文字区分度太低，不计入
+func relatedTerms(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, t := range tokenizeIndex(text) {
+		if t.end-t.start == 1 {
+			r, _ := utf8.DecodeRuneInString(t.term)
+			if isCJK(r) || !isWordRune(r) {
+				continue
+			}
+		}
+		counts[t.term]++
+	}
+	return counts
+}
+
+// tagOverlap 两组标签的 Jaccard 相似度
+func tagOverlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, tag := range a {
+		set[tag] = true
+	}
+	union := len(set)
+	shared := 0
+	seen := make(map[string]bool, len(b))
+	for _, tag := range b {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		if set[tag] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared) / float64(union)
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestTokenizeQuery 测试查询切分：中文取二元组，单字取单字，拉丁词统一小写和半角
+func TestTokenizeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"中文分词", []string{"中文", "文分", "分词"}},
+		{"Go 语言", []string{"go", "语言"}},
+		{"ＧＯ 与 SQLite", []string{"go", "与", "sqlite"}},
+		{"  ,. ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := tokenizeQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenizeQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestSearch 测试排序、筛选和可见性过滤
+func TestSearch(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	idx := NewIndex()
+	idx.Add(&Document{ID: 1, Title: "Go 并发编程", Body: "介绍 goroutine 和 channel 的使用。", Tags: []string{"Go"},
+		Category: "技术", Status: "published", Visibility: "public", CreatedAt: day})
+	idx.Add(&Document{ID: 2, Title: "周末随笔", Body: "今天学习了 Go 的并发模型。", Category: "生活",
+		Status: "published", Visibility: "public", CreatedAt: day.AddDate(0, 0, 10)})
+	idx.Add(&Document{ID: 3, Title: "并发草稿", Body: "Go 并发", Status: "draft", Visibility: "public", CreatedAt: day})
+	idx.Add(&Document{ID: 4, Title: "私密并发笔记", Body: "Go 并发", Status: "published", Visibility: "private", CreatedAt: day})
+
+	hits, total := idx.Search(Query{Text: "go 并发"})
+	if total != 2 || hits[0].Document.ID != 1 {
+		t.Fatalf("Search() = %d hits, first %+v", total, hits)
+	}
+
+	if _, total := idx.Search(Query{Text: "go 并发", IncludeHidden: true}); total != 4 {
+		t.Errorf("IncludeHidden total = %d, want 4", total)
+	}
+	if hits, total := idx.Search(Query{Text: "并发", Category: "生活"}); total != 1 || hits[0].Document.ID != 2 {
+		t.Errorf("category filter = %d hits", total)
+	}
+	if _, total := idx.Search(Query{Text: "并发", Tags: []string{"go"}}); total != 1 {
+		t.Errorf("tag filter total = %d, want 1", total)
+	}
+	if hits, total := idx.Search(Query{Text: "并发", Since: day.AddDate(0, 0, 1)}); total != 1 || hits[0].Document.ID != 2 {
+		t.Errorf("date filter = %d hits", total)
+	}
+	if _, total := idx.Search(Query{Text: "并发 rust"}); total != 0 {
+		t.Errorf("AND semantics total = %d, want 0", total)
+	}
+
+	idx.Remove(1)
+	if _, total := idx.Search(Query{Text: "goroutine"}); total != 0 {
+		t.Errorf("removed document still found")
+	}
+}
+
+// TestSnippet 测试摘要截取与高亮转义
+func TestSnippet(t *testing.T) {
+	got := Snippet("使用 <b>中文分词</b> 检索", tokenizeQuery("中文分词"), 40)
+	want := "使用 &lt;b&gt;<mark>中文分词</mark>&lt;/b&gt; 检索"
+	if got != want {
+		t.Errorf("Snippet() = %q, want %q", got, want)
+	}
+
+	if got := Highlight("Going to Go", tokenizeQuery("go")); got != "Going to <mark>Go</mark>" {
+		t.Errorf("Highlight() = %q", got)
+	}
+}
+
+// TestRelated 测试相关文章得分：标签、分类与正文相似度共同决定排序
+func TestRelated(t *testing.T) {
+	docs := []*Document{
+		{ID: 1, Title: "Go 并发编程", Body: "goroutine 与 channel 的调度模型", Tags: []string{"Go", "并发"}, Category: "技术"},
+		{ID: 2, Title: "Go 并发进阶", Body: "channel 的关闭与 goroutine 泄漏", Tags: []string{"Go", "并发"}, Category: "技术"},
+		{ID: 3, Title: "SQLite 调优", Body: "索引与事务的使用", Tags: []string{"数据库"}, Category: "技术"},
+		{ID: 4, Title: "周末随笔", Body: "爬山看日落", Tags: []string{"生活"}, Category: "生活"},
+	}
+
+	related := Related(docs, 2)
+	if got := related[1]; len(got) != 2 || got[0].ID != 2 || got[1].ID != 3 {
+		t.Fatalf("Related()[1] = %+v, want [2 3]", got)
+	}
+	if got := related[4]; len(got) != 0 {
+		t.Errorf("Related()[4] = %+v, want none", got)
+	}
+	if related[1][0].Score <= related[1][1].Score {
+		t.Errorf("scores not sorted: %+v", related[1])
+	}
+
+	if got := Related(docs[:1], 5); len(got) != 0 {
+		t.Errorf("Related() with one document = %+v", got)
+	}
+}
+
+// TestTagOverlap 测试标签的 Jaccard 相似度，重复标签只计一次
+func TestTagOverlap(t *testing.T) {
+	if got := tagOverlap([]string{"a", "b"}, []string{"b", "c", "c"}); got != 1.0/3 {
+		t.Errorf("tagOverlap() = %v, want 1/3", got)
+	}
+	if got := tagOverlap(nil, []string{"a"}); got != 0 {
+		t.Errorf("tagOverlap(nil) = %v, want 0", got)
+	}
+}
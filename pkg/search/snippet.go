@@ -0,0 +1,133 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// snippetLength 摘要默认长度（字符数）
+const snippetLength = 120
+
+// snippetLead 命中位置之前保留的字符数
+const snippetLead = 30
+
+// Snippet 截取包含查询命中词的一段文本，转义 HTML 并用 <mark> 标记命中词
+// 没有命中时返回文本开头的一段
+func Snippet(text string, terms []string, length int) string {
+	runes := []rune(collapseSpace(text))
+	if len(runes) == 0 {
+		return ""
+	}
+	if length <= 0 {
+		length = snippetLength
+	}
+
+	spans := matchSpans(runes, terms)
+
+	start := 0
+	if len(spans) > 0 {
+		start = spans[0][0] - snippetLead
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + length
+	if end > len(runes) {
+		end = len(runes)
+		// 靠近结尾时向前补足长度
+		if start = end - length; start < 0 {
+			start = 0
+		}
+	}
+
+	var buf strings.Builder
+	if start > 0 {
+		buf.WriteString("…")
+	}
+	writeHighlighted(&buf, runes, start, end, spans)
+	if end < len(runes) {
+		buf.WriteString("…")
+	}
+	return buf.String()
+}
+
+// Highlight 转义整段文本并用 <mark> 标记命中词
+func Highlight(text string, terms []string) string {
+	runes := []rune(text)
+	var buf strings.Builder
+	writeHighlighted(&buf, runes, 0, len(runes), matchSpans(runes, terms))
+	return buf.String()
+}
+
+// writeHighlighted 输出 [start, end) 区间的文本，与命中区间相交的部分加上 <mark>
+func writeHighlighted(buf *strings.Builder, runes []rune, start, end int, spans [][2]int) {
+	pos := start
+	for _, span := range spans {
+		s, e := span[0], span[1]
+		if e <= pos {
+			continue
+		}
+		if s >= end {
+			break
+		}
+		if s < pos {
+			s = pos
+		}
+		if e > end {
+			e = end
+		}
+		buf.WriteString(html.EscapeString(string(runes[pos:s])))
+		buf.WriteString("<mark>")
+		buf.WriteString(html.EscapeString(string(runes[s:e])))
+		buf.WriteString("</mark>")
+		pos = e
+	}
+	buf.WriteString(html.EscapeString(string(runes[pos:end])))
+}
+
+// matchSpans 找出文本中所有命中查询词项的位置，合并重叠和相邻的区间
+func matchSpans(runes []rune, terms []string) [][2]int {
+	if len(terms) == 0 {
+		return nil
+	}
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	var spans [][2]int
+	for _, t := range tokenizeIndex(string(runes)) {
+		if !wanted[t.term] {
+			continue
+		}
+		// tokenizeIndex 按起始位置有序输出，只需与最后一个区间合并
+		if n := len(spans); n > 0 && t.start <= spans[n-1][1] {
+			if t.end > spans[n-1][1] {
+				spans[n-1][1] = t.end
+			}
+			continue
+		}
+		spans = append(spans, [2]int{t.start, t.end})
+	}
+	return spans
+}
+
+// collapseSpace 把连续空白合并为一个空格
+func collapseSpace(text string) string {
+	var buf strings.Builder
+	buf.Grow(len(text))
+	space := false
+	for _, r := range strings.TrimSpace(text) {
+		if unicode.IsSpace(r) {
+			if !space {
+				buf.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
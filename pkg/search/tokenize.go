@@ -0,0 +1,123 @@
+// Package search 文章全文检索：中日韩文字按二元组切分的倒排索引、BM25 排序与摘要高亮
+package search
+
+import (
+	"unicode"
+)
+
+// token 切分出的词项，start/end 为在折叠后文本中的 rune 下标区间 [start, end)
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+// isCJK 判断是否为需要按字切分的中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// isWordRune 判断是否为组成拉丁词的字符（字母或数字，不含中日韩文字）
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// foldRune 统一大小写并把全角字母数字转为半角，保证一个 rune 只映射为一个 rune
+func foldRune(r rune) rune {
+	switch {
+	case r >= 0xFF01 && r <= 0xFF5E:
+		r -= 0xFEE0
+	case r == 0x3000:
+		r = ' '
+	}
+	return unicode.ToLower(r)
+}
+
+// fold 折叠整段文本，返回的 rune 切片与原文逐字对应
+func fold(text string) []rune {
+	runes := []rune(text)
+	for i, r := range runes {
+		runes[i] = foldRune(r)
+	}
+	return runes
+}
+
+// tokenizeIndex 切分待索引文本：拉丁词整体作为词项，中日韩文字同时产生单字和相邻二元组
+// 单字保证单字查询可以命中，二元组保证多字查询的精度
+func tokenizeIndex(text string) []token {
+	runes := fold(text)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			j := i
+			for j < len(runes) && isCJK(runes[j]) {
+				j++
+			}
+			for k := i; k < j; k++ {
+				tokens = append(tokens, token{term: string(runes[k]), start: k, end: k + 1})
+				if k+1 < j {
+					tokens = append(tokens, token{term: string(runes[k : k+2]), start: k, end: k + 2})
+				}
+			}
+			i = j
+		case isWordRune(r):
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{term: string(runes[i:j]), start: i, end: j})
+			i = j
+		default:
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// tokenizeQuery 切分查询文本，返回去重后的词项
+// 连续两个以上的中日韩文字只取二元组，单个文字取单字
+func tokenizeQuery(text string) []string {
+	runes := fold(text)
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			j := i
+			for j < len(runes) && isCJK(runes[j]) {
+				j++
+			}
+			if j-i == 1 {
+				add(string(runes[i]))
+			}
+			for k := i; k+1 < j; k++ {
+				add(string(runes[k : k+2]))
+			}
+			i = j
+		case isWordRune(r):
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			add(string(runes[i:j]))
+			i = j
+		default:
+			i++
+		}
+	}
+
+	return terms
+}
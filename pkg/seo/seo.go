@@ -0,0 +1,267 @@
+// Package seo 生成页面的 Open Graph、Twitter Card 元数据与 schema.org JSON-LD
+package seo
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 页面类型
+const (
+	TypeWebsite = "website"
+	TypeArticle = "article"
+)
+
+// DescriptionLength 自动生成描述时保留的最大字符数
+const DescriptionLength = 160
+
+// Site 站点级别的默认值，页面未提供对应字段时使用
+type Site struct {
+	Name        string
+	Description string
+	BaseURL     string // 不带结尾斜杠的站点根地址
+	Image       string // 默认分享图片，可以是站内路径
+	TwitterSite string // Twitter 账号，如 @example
+	Locale      string // 如 zh_CN
+}
+
+// Crumb 面包屑导航中的一级，URL 可以是站内路径
+type Crumb struct {
+	Name string
+	URL  string
+}
+
+// Page 单个页面的元数据
+type Page struct {
+	Type        string // TypeWebsite 或 TypeArticle
+	Title       string
+	Description string
+	Path        string // 页面的站内路径，用于生成规范链接
+	Image       string
+	ImageWidth  int // 图片尺寸，未知时为 0
+	ImageHeight int
+	Author      string
+	Section     string // 文章分类
+	Tags        []string
+	PublishedAt time.Time
+	ModifiedAt  time.Time
+	Breadcrumbs []Crumb
+	NoIndex     bool // 不希望被搜索引擎收录，如预览页面
+}
+
+// Render 生成放在 <head> 中的 meta、link 标签与 JSON-LD 脚本
+func Render(site Site, page Page) template.HTML {
+	if page.Type == "" {
+		page.Type = TypeWebsite
+	}
+	if page.Title == "" {
+		page.Title = site.Name
+	}
+	if page.Description == "" {
+		page.Description = site.Description
+	}
+	if page.Image == "" {
+		// 尺寸只属于页面自己的图片
+		page.Image, page.ImageWidth, page.ImageHeight = site.Image, 0, 0
+	}
+	pageURL := Absolute(site.BaseURL, page.Path)
+	image := Absolute(site.BaseURL, page.Image)
+
+	var buf bytes.Buffer
+	meta := func(attr, key, value string) {
+		if value != "" {
+			buf.WriteString(`<meta ` + attr + `="` + key + `" content="` + html.EscapeString(value) + "\">\n")
+		}
+	}
+
+	if pageURL != "" {
+		buf.WriteString(`<link rel="canonical" href="` + html.EscapeString(pageURL) + "\">\n")
+	}
+	meta("name", "description", page.Description)
+	if page.NoIndex {
+		meta("name", "robots", "noindex, nofollow")
+	}
+
+	meta("property", "og:type", page.Type)
+	meta("property", "og:title", page.Title)
+	meta("property", "og:description", page.Description)
+	meta("property", "og:url", pageURL)
+	meta("property", "og:site_name", site.Name)
+	meta("property", "og:locale", site.Locale)
+	meta("property", "og:image", image)
+	if image != "" && page.ImageWidth > 0 && page.ImageHeight > 0 {
+		meta("property", "og:image:width", strconv.Itoa(page.ImageWidth))
+		meta("property", "og:image:height", strconv.Itoa(page.ImageHeight))
+	}
+	if page.Type == TypeArticle {
+		meta("property", "article:published_time", formatTime(page.PublishedAt))
+		meta("property", "article:modified_time", formatTime(page.ModifiedAt))
+		meta("property", "article:author", page.Author)
+		meta("property", "article:section", page.Section)
+		for _, tag := range page.Tags {
+			meta("property", "article:tag", tag)
+		}
+	}
+
+	card := "summary"
+	if image != "" {
+		card = "summary_large_image"
+	}
+	meta("name", "twitter:card", card)
+	meta("name", "twitter:site", site.TwitterSite)
+	meta("name", "twitter:title", page.Title)
+	meta("name", "twitter:description", page.Description)
+	meta("name", "twitter:image", image)
+
+	for _, data := range JSONLD(site, page) {
+		// json.Marshal 会转义 <、> 与 &，内容无法提前结束 script 标签
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			continue
+		}
+		buf.WriteString(`<script type="application/ld+json">`)
+		buf.Write(encoded)
+		buf.WriteString("</script>\n")
+	}
+
+	return template.HTML(buf.String())
+}
+
+// JSONLD 生成页面的 schema.org 结构化数据
+// 文章页面为 BlogPosting，其他页面为 WebSite；有面包屑时追加 BreadcrumbList
+func JSONLD(site Site, page Page) []map[string]interface{} {
+	pageURL := Absolute(site.BaseURL, page.Path)
+	image := Absolute(site.BaseURL, page.Image)
+
+	var main map[string]interface{}
+	if page.Type == TypeArticle {
+		main = map[string]interface{}{
+			"@context": "https://schema.org",
+			"@type":    "BlogPosting",
+			"headline": page.Title,
+		}
+		if pageURL != "" {
+			main["url"] = pageURL
+			main["mainEntityOfPage"] = map[string]interface{}{"@type": "WebPage", "@id": pageURL}
+		}
+		if page.Author != "" {
+			main["author"] = map[string]interface{}{"@type": "Person", "name": page.Author}
+		}
+		if site.Name != "" {
+			main["publisher"] = map[string]interface{}{"@type": "Organization", "name": site.Name}
+		}
+		if published := formatTime(page.PublishedAt); published != "" {
+			main["datePublished"] = published
+		}
+		if modified := formatTime(page.ModifiedAt); modified != "" {
+			main["dateModified"] = modified
+		}
+		if page.Section != "" {
+			main["articleSection"] = page.Section
+		}
+		if len(page.Tags) > 0 {
+			main["keywords"] = strings.Join(page.Tags, ", ")
+		}
+	} else {
+		main = map[string]interface{}{
+			"@context": "https://schema.org",
+			"@type":    "WebSite",
+			"name":     site.Name,
+		}
+		if page.Title != "" && page.Title != site.Name {
+			main["alternateName"] = page.Title
+		}
+		if pageURL != "" {
+			main["url"] = pageURL
+		}
+	}
+	if page.Description != "" {
+		main["description"] = page.Description
+	}
+	if image != "" {
+		main["image"] = image
+	}
+
+	result := []map[string]interface{}{main}
+	if len(page.Breadcrumbs) > 0 {
+		items := make([]map[string]interface{}, len(page.Breadcrumbs))
+		for i, crumb := range page.Breadcrumbs {
+			item := map[string]interface{}{
+				"@type":    "ListItem",
+				"position": i + 1,
+				"name":     crumb.Name,
+			}
+			if u := Absolute(site.BaseURL, crumb.URL); u != "" {
+				item["item"] = u
+			}
+			items[i] = item
+		}
+		result = append(result, map[string]interface{}{
+			"@context":        "https://schema.org",
+			"@type":           "BreadcrumbList",
+			"itemListElement": items,
+		})
+	}
+	return result
+}
+
+// Absolute 将站内路径转换为绝对地址，已经是绝对地址时原样返回
+func Absolute(baseURL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	if u, err := url.Parse(ref); err == nil && u.IsAbs() {
+		return ref
+	}
+	if strings.HasPrefix(ref, "//") {
+		return "https:" + ref
+	}
+	if !strings.HasPrefix(ref, "/") {
+		ref = "/" + ref
+	}
+	return strings.TrimRight(baseURL, "/") + ref
+}
+
+var (
+	imgSrcPattern = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']([^"']+)["']`)
+	tagPattern    = regexp.MustCompile(`<[^>]*>`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// FirstImage 返回 HTML 中第一张图片的地址，忽略 data: 内联图片
+func FirstImage(htmlContent string) string {
+	for _, match := range imgSrcPattern.FindAllStringSubmatch(htmlContent, -1) {
+		src := html.UnescapeString(strings.TrimSpace(match[1]))
+		if src != "" && !strings.HasPrefix(strings.ToLower(src), "data:") {
+			return src
+		}
+	}
+	return ""
+}
+
+// Description 去掉 HTML 标签并合并空白，超过 max 个字符时截断并加上省略号
+func Description(text string, max int) string {
+	text = html.UnescapeString(tagPattern.ReplaceAllString(text, " "))
+	text = strings.TrimSpace(spacePattern.ReplaceAllString(text, " "))
+	if max <= 0 || utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
+
+// formatTime 按 ISO 8601 格式化时间，零值返回空字符串
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
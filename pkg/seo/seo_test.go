@@ -0,0 +1,167 @@
+package seo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSite = Site{
+	Name:        "我的博客",
+	Description: "站点描述",
+	BaseURL:     "https://example.com/",
+	Image:       "/img/avatar.png",
+	TwitterSite: "@example",
+	Locale:      "zh_CN",
+}
+
+// TestRenderArticle 测试文章页面的 Open Graph、Twitter 与 JSON-LD 输出
+func TestRenderArticle(t *testing.T) {
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	out := string(Render(testSite, Page{
+		Type:        TypeArticle,
+		Title:       `Go "泛型" <入门>`,
+		Description: "摘要 & 说明",
+		Path:        "/passage/2026/01/02/go",
+		Image:       "/img/cover.png",
+		ImageWidth:  1200,
+		ImageHeight: 630,
+		Author:      "张三",
+		Section:     "编程",
+		Tags:        []string{"go", "泛型"},
+		PublishedAt: published,
+		Breadcrumbs: []Crumb{{Name: "首页", URL: "/"}, {Name: "编程", URL: "/category/编程"}, {Name: "Go"}},
+	}))
+
+	for _, want := range []string{
+		`<link rel="canonical" href="https://example.com/passage/2026/01/02/go">`,
+		`<meta property="og:type" content="article">`,
+		`<meta property="og:title" content="Go &#34;泛型&#34; &lt;入门&gt;">`,
+		`<meta name="description" content="摘要 &amp; 说明">`,
+		`<meta property="og:image" content="https://example.com/img/cover.png">`,
+		`<meta property="og:image:width" content="1200">`,
+		`<meta property="article:published_time" content="2026-01-02T03:04:05Z">`,
+		`<meta property="article:tag" content="泛型">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+		`<meta name="twitter:site" content="@example">`,
+		`"@type":"BlogPosting"`,
+		`"@type":"BreadcrumbList"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<入门>") || strings.Contains(out, "article:modified_time") {
+		t.Errorf("unexpected content in output:\n%s", out)
+	}
+	if strings.Contains(out, `name="robots"`) {
+		t.Errorf("robots meta should only be emitted for NoIndex pages")
+	}
+}
+
+// TestJSONLD 测试结构化数据的字段与面包屑序号
+func TestJSONLD(t *testing.T) {
+	data := JSONLD(testSite, Page{
+		Type:        TypeArticle,
+		Title:       "标题",
+		Path:        "/passage/a",
+		Author:      "张三",
+		Breadcrumbs: []Crumb{{Name: "首页", URL: "/"}, {Name: "标题"}},
+	})
+	if len(data) != 2 {
+		t.Fatalf("len(JSONLD) = %d, want 2", len(data))
+	}
+
+	encoded, _ := json.Marshal(data[0])
+	var posting struct {
+		Type     string `json:"@type"`
+		Headline string `json:"headline"`
+		Author   struct {
+			Name string `json:"name"`
+		} `json:"author"`
+		MainEntity struct {
+			ID string `json:"@id"`
+		} `json:"mainEntityOfPage"`
+	}
+	if err := json.Unmarshal(encoded, &posting); err != nil {
+		t.Fatal(err)
+	}
+	if posting.Type != "BlogPosting" || posting.Headline != "标题" || posting.Author.Name != "张三" ||
+		posting.MainEntity.ID != "https://example.com/passage/a" {
+		t.Errorf("BlogPosting = %+v", posting)
+	}
+
+	items := data[1]["itemListElement"].([]map[string]interface{})
+	if items[0]["item"] != "https://example.com/" || items[1]["position"] != 2 {
+		t.Errorf("breadcrumbs = %+v", items)
+	}
+	if _, ok := items[1]["item"]; ok {
+		t.Errorf("last crumb without URL should not have item: %+v", items[1])
+	}
+
+	website := JSONLD(testSite, Page{Title: "我的博客", Path: "/"})
+	if len(website) != 1 || website[0]["@type"] != "WebSite" || website[0]["description"] != "" && website[0]["description"] != nil {
+		t.Errorf("website = %+v", website)
+	}
+}
+
+// TestRenderDefaults 测试使用站点默认描述与图片，以及 JSON-LD 中的脚本转义
+func TestRenderDefaults(t *testing.T) {
+	out := string(Render(testSite, Page{Title: "</script><script>alert(1)</script>", Path: "/about", NoIndex: true}))
+	for _, want := range []string{
+		`<meta name="description" content="站点描述">`,
+		`<meta property="og:type" content="website">`,
+		`<meta property="og:image" content="https://example.com/img/avatar.png">`,
+		`<meta name="robots" content="noindex, nofollow">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "</script>") != strings.Count(out, `<script type="application/ld+json">`) {
+		t.Errorf("title escaped out of JSON-LD script:\n%s", out)
+	}
+
+	out = string(Render(Site{Name: "blog"}, Page{Title: "无图"}))
+	if !strings.Contains(out, `<meta name="twitter:card" content="summary">`) || strings.Contains(out, "og:image") {
+		t.Errorf("page without image:\n%s", out)
+	}
+	if out := string(Render(testSite, Page{ImageWidth: 100, ImageHeight: 100})); strings.Contains(out, "og:image:width") {
+		t.Errorf("image size should not apply to the site image:\n%s", out)
+	}
+}
+
+func TestAbsolute(t *testing.T) {
+	cases := []struct{ base, ref, want string }{
+		{"https://example.com", "/img/a.png", "https://example.com/img/a.png"},
+		{"https://example.com/", "img/a.png", "https://example.com/img/a.png"},
+		{"https://example.com", "https://cdn.example.com/a.png", "https://cdn.example.com/a.png"},
+		{"https://example.com", "//cdn.example.com/a.png", "https://cdn.example.com/a.png"},
+		{"https://example.com", "", ""},
+	}
+	for _, c := range cases {
+		if got := Absolute(c.base, c.ref); got != c.want {
+			t.Errorf("Absolute(%q, %q) = %q, want %q", c.base, c.ref, got, c.want)
+		}
+	}
+}
+
+func TestFirstImage(t *testing.T) {
+	content := `<p>text</p><img alt="x" src="data:image/png;base64,AAA"><p><img class="a" src='/attachments/a&amp;b.png'></p><img src="/b.png">`
+	if got := FirstImage(content); got != "/attachments/a&b.png" {
+		t.Errorf("FirstImage() = %q", got)
+	}
+	if got := FirstImage("<p>no image</p>"); got != "" {
+		t.Errorf("FirstImage() without image = %q", got)
+	}
+}
+
+func TestDescription(t *testing.T) {
+	if got := Description("<p>第一段</p>\n\n<p>第二  段 &amp; 更多</p>", 0); got != "第一段 第二 段 & 更多" {
+		t.Errorf("Description() = %q", got)
+	}
+	if got := Description("一二三四五六", 4); got != "一二三四…" {
+		t.Errorf("Description() truncated = %q", got)
+	}
+}
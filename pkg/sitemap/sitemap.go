@@ -0,0 +1,90 @@
+// Package sitemap 生成符合 sitemaps.org 协议的 sitemap 与 sitemap index
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs 单个 sitemap 文件允许的最大 URL 数
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL sitemap 条目，Loc 必须为绝对地址
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type xmlURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []xmlURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Sitemaps []xmlURL `xml:"sitemap"`
+}
+
+// URLSet 生成 <urlset> 文档
+func URLSet(urls []URL) ([]byte, error) {
+	return marshal(urlSet{XMLNS: namespace, URLs: toXML(urls)})
+}
+
+// Index 生成 <sitemapindex> 文档，sitemaps 为各个子 sitemap 的地址
+func Index(sitemaps []URL) ([]byte, error) {
+	return marshal(sitemapIndex{XMLNS: namespace, Sitemaps: toXML(sitemaps)})
+}
+
+// Split 按 MaxURLs 将 URL 列表分页
+func Split(urls []URL) [][]URL {
+	var pages [][]URL
+	for len(urls) > MaxURLs {
+		pages = append(pages, urls[:MaxURLs])
+		urls = urls[MaxURLs:]
+	}
+	return append(pages, urls)
+}
+
+// LastMod 返回 URL 列表中最新的修改时间
+func LastMod(urls []URL) time.Time {
+	var latest time.Time
+	for _, u := range urls {
+		if u.LastMod.After(latest) {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
+
+func toXML(urls []URL) []xmlURL {
+	result := make([]xmlURL, len(urls))
+	for i, u := range urls {
+		result[i].Loc = u.Loc
+		if !u.LastMod.IsZero() {
+			result[i].LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+	}
+	return result
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
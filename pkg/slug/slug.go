@@ -0,0 +1,100 @@
+// Package slug 生成文章的 URL 别名与永久链接
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength 自动生成的别名最大长度（字节）
+const MaxLength = 80
+
+// PathPrefix 文章永久链接前缀
+const PathPrefix = "/passage/"
+
+// ligatures 无法通过 Unicode 分解去掉变音符号的拉丁字母
+var ligatures = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i",
+}
+
+// Make 将标题转写为只包含小写 ASCII 字母、数字与连字符的别名
+// 带变音符号的拉丁字母会被转写，其余无法转写的字符（如中文）被丢弃，此时 lossy 为 true
+func Make(title string) (s string, lossy bool) {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(title)) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		case unicode.Is(unicode.Mn, r):
+			// 变音符号
+		case ligatures[r] != "":
+			b.WriteString(ligatures[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			lossy = true
+			b.WriteByte('-')
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return truncate(collapse(b.String())), lossy
+}
+
+// ForPassage 为文章生成别名
+// 标题无法完整转写时追加文章 ID，转写结果为空时使用 post-{id}
+func ForPassage(title string, id int) string {
+	s, lossy := Make(title)
+	switch {
+	case s == "":
+		return "post-" + strconv.Itoa(id)
+	case lossy:
+		return s + "-" + strconv.Itoa(id)
+	}
+	return s
+}
+
+// Normalize 规范化手动设置的别名（如头部元数据中的 slug）
+// 保留各语言的字母与数字，其余字符替换为连字符
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return collapse(b.String())
+}
+
+// WithSuffix 为冲突的别名追加序号，n 从 2 开始
+func WithSuffix(s string, n int) string {
+	return s + "-" + strconv.Itoa(n)
+}
+
+// Path 返回文章的永久链接路径（未转义），格式为 /passage/{yyyy}/{mm}/{dd}/{slug}
+// 日期按本地时区格式化，避免数据库读出的 UTC 时间与内存中的时间得到不同的路径
+func Path(date time.Time, s string) string {
+	return PathPrefix + date.Local().Format("2006/01/02") + "/" + s
+}
+
+// collapse 合并连续的连字符并去掉首尾连字符
+func collapse(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '-' })
+	return strings.Join(parts, "-")
+}
+
+// truncate 将别名截断到 MaxLength 以内，尽量在连字符处截断
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+	s = s[:MaxLength]
+	if i := strings.LastIndexByte(s, '-'); i > MaxLength/2 {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, "-")
+}
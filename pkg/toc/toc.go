@@ -0,0 +1,214 @@
+// Package toc 为 markdown 标题生成稳定的锚点 ID，并提取嵌套的文章目录
+package toc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"myblog-gogogo/pkg/slug"
+)
+
+// DefaultDepth 未单独设置时目录包含的标题层数
+const DefaultDepth = 3
+
+// MaxDepth 目录最多包含的标题层数
+const MaxDepth = 6
+
+// Entry 目录条目
+type Entry struct {
+	Level    int     `json:"level"`
+	ID       string  `json:"id"`
+	Text     string  `json:"text"`
+	Children []Entry `json:"children,omitempty"`
+}
+
+// heading 解析过程中记录的标题
+type heading struct {
+	level int
+	id    string
+	text  string
+}
+
+var headingsKey = parser.NewContextKey()
+
+// HeadingIDTransformer AST 转换器，为所有标题分配 ID 并记录到解析上下文
+// ID 保留中日韩文字，例如 "## 安装" 的 ID 为 "安装"；重复的标题依次追加 -1、-2
+// 文档开头的一级标题视为文章标题，分配 ID 但不收录到目录
+type HeadingIDTransformer struct{}
+
+// Transform 转换 AST
+func (t *HeadingIDTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	used := make(map[string]bool)
+	var headings []heading
+
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		h, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		title := strings.TrimSpace(plainText(h, source))
+		id := ""
+		if value, ok := h.AttributeString("id"); ok {
+			if b, ok := value.([]byte); ok {
+				id = string(b)
+			}
+		}
+		if id == "" {
+			id = uniqueID(headingID(title), used)
+			h.SetAttributeString("id", []byte(id))
+		}
+		used[id] = true
+
+		if !(h.Level == 1 && h == node.FirstChild()) {
+			headings = append(headings, heading{level: h.Level, id: id, text: title})
+		}
+		return ast.WalkSkipChildren, nil
+	})
+
+	pc.Set(headingsKey, headings)
+}
+
+// Extension 标题 ID 与目录扩展
+type Extension struct{}
+
+// NewExtension 创建标题 ID 与目录扩展
+func NewExtension() goldmark.Extender {
+	return &Extension{}
+}
+
+// Extend 扩展 Goldmark
+func (e *Extension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(&HeadingIDTransformer{}, 200)))
+}
+
+// Convert 使用注册了 Extension 的 goldmark 实例转换 markdown，同时返回目录
+// depth 为目录包含的标题层数，从文中出现的最高级标题算起，<= 0 时使用 DefaultDepth
+func Convert(md goldmark.Markdown, source []byte, w io.Writer, depth int) ([]Entry, error) {
+	return ConvertContext(md, parser.NewContext(), source, w, depth)
+}
+
+// ConvertContext 与 Convert 相同，使用调用方提供的解析上下文，便于其他扩展通过上下文传入参数或取回结果
+func ConvertContext(md goldmark.Markdown, pc parser.Context, source []byte, w io.Writer, depth int) ([]Entry, error) {
+	if err := md.Convert(source, w, parser.WithContext(pc)); err != nil {
+		return nil, err
+	}
+	headings, _ := pc.Get(headingsKey).([]heading)
+	return build(headings, depth), nil
+}
+
+// build 按层级将标题组织为嵌套目录
+func build(headings []heading, depth int) []Entry {
+	if depth <= 0 {
+		depth = DefaultDepth
+	}
+	if depth > MaxDepth {
+		depth = MaxDepth
+	}
+	if len(headings) == 0 {
+		return nil
+	}
+
+	top := headings[0].level
+	for _, h := range headings {
+		if h.level < top {
+			top = h.level
+		}
+	}
+	maxLevel := top + depth - 1
+
+	var root Entry
+	// stack 保存从根到当前位置的条目路径
+	stack := []*Entry{&root}
+	for _, h := range headings {
+		if h.level > maxLevel {
+			continue
+		}
+		for len(stack) > 1 && stack[len(stack)-1].Level >= h.level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, Entry{Level: h.level, ID: h.id, Text: h.text})
+		stack = append(stack, &parent.Children[len(parent.Children)-1])
+	}
+	return root.Children
+}
+
+// Marshal 将目录序列化为 JSON 以便存储，目录为空时返回空字符串
+func Marshal(entries []Entry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Unmarshal 解析存储的目录，内容为空或无效时返回空列表
+func Unmarshal(data string) []Entry {
+	entries := []Entry{}
+	if data != "" {
+		_ = json.Unmarshal([]byte(data), &entries)
+	}
+	return entries
+}
+
+// headingID 根据标题文本生成 ID
+func headingID(title string) string {
+	id := slug.Normalize(title)
+	if id == "" {
+		return "section"
+	}
+	return id
+}
+
+// uniqueID 为重复的 ID 追加序号
+func uniqueID(id string, used map[string]bool) string {
+	if !used[id] {
+		return id
+	}
+	for n := 1; ; n++ {
+		candidate := id + "-" + strconv.Itoa(n)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// plainText 提取节点中的纯文本
+func plainText(n ast.Node, source []byte) string {
+	var buf bytes.Buffer
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := child.(type) {
+		case *ast.Text:
+			buf.Write(c.Value(source))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(c.Value)
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return buf.String()
+}
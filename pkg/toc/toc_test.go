@@ -0,0 +1,51 @@
+package toc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+)
+
+const sample = "# 文章标题\n\n## 安装\n\n### 依赖\n\n## 使用 `go run`\n\n### 依赖\n\n#### 细节\n\n## 安装\n"
+
+// TestConvert 测试标题 ID、重复标题的后缀以及目录嵌套
+func TestConvert(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(NewExtension()))
+
+	var buf bytes.Buffer
+	entries, err := Convert(md, []byte(sample), &buf, 0)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	html := buf.String()
+	for _, want := range []string{`<h1 id="文章标题">`, `<h2 id="安装">`, `<h3 id="依赖">`, `<h2 id="使用-go-run">`, `<h3 id="依赖-1">`, `<h2 id="安装-1">`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html missing %s:\n%s", want, html)
+		}
+	}
+
+	want := []Entry{
+		{Level: 2, ID: "安装", Text: "安装", Children: []Entry{{Level: 3, ID: "依赖", Text: "依赖"}}},
+		{Level: 2, ID: "使用-go-run", Text: "使用 go run", Children: []Entry{
+			{Level: 3, ID: "依赖-1", Text: "依赖", Children: []Entry{{Level: 4, ID: "细节", Text: "细节"}}},
+		}},
+		{Level: 2, ID: "安装-1", Text: "安装"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+
+	// 限制为两层时不包含四级标题
+	entries, _ = Convert(md, []byte(sample), &bytes.Buffer{}, 2)
+	if len(entries[1].Children[0].Children) != 0 {
+		t.Errorf("depth 2 should drop level 4 headings: %+v", entries)
+	}
+
+	if got := Unmarshal(Marshal(entries)); !reflect.DeepEqual(got, entries) {
+		t.Errorf("Marshal/Unmarshal round trip = %+v", got)
+	}
+}
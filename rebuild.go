@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"myblog-gogogo/config"
+	"myblog-gogogo/db"
+	"myblog-gogogo/pkg/beautify"
+	"myblog-gogogo/pkg/logger"
+	"myblog-gogogo/service"
+)
+
+// runRebuild 子命令 rebuild：从 markdown 原文重新渲染全部文章后退出
+// 用法：myblog-gogogo rebuild [-db-driver ...] [-db-conn ...] [-worker-count ...]
+func runRebuild(args []string) int {
+	// 与启动服务时相同，渲染图片尺寸等需要本地资源目录
+	if err := extractEmbeddedResources(); err != nil {
+		beautify.Errorf("资源释放失败: %v", err)
+		return 1
+	}
+
+	os.Args = append([]string{os.Args[0]}, args...)
+	cfg := config.Load()
+	logger.SetLevelFromString(cfg.LogLevel)
+
+	beautify.Header("重新渲染文章")
+	beautify.Section("数据库初始化")
+	beautify.Indent()
+	if err := db.InitDB(cfg.DBDriver, cfg.DBConnStr, cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.DBConnMaxLifetime, cfg.DBConnMaxIdleTime); err != nil {
+		beautify.ErrorLeaf(fmt.Sprintf("初始化失败: %v", err))
+		return 1
+	}
+	defer db.CloseDB()
+	beautify.SuccessLeaf(fmt.Sprintf("连接: %s", cfg.DBConnStr))
+	beautify.Outdent()
+
+	service.InitWorkerPool(cfg.WorkerCount, cfg.WorkerQueueSize)
+	defer service.CloseWorkerPool()
+
+	// Ctrl+C 时处理完当前批次后停止
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	beautify.Section(fmt.Sprintf("渲染（渲染器版本 %d）", service.RendererVersion))
+	beautify.Indent()
+	progress, err := service.RebuildPassages(ctx, func(p service.RebuildProgress) {
+		beautify.Leaf(fmt.Sprintf("%d/%d 篇，失败 %d 篇", p.Done, p.Total, p.Failed))
+	})
+	if err != nil {
+		beautify.ErrorLeaf(err.Error())
+		beautify.Outdent()
+		return 1
+	}
+	if progress.LastError != "" {
+		beautify.ErrorLeaf("最后一个错误: " + progress.LastError)
+	}
+	beautify.Outdent()
+
+	elapsed := progress.FinishedAt.Sub(progress.StartedAt)
+	if progress.Failed > 0 {
+		beautify.Errorf("完成 %d 篇，失败 %d 篇，耗时 %v", progress.Total-progress.Failed, progress.Failed, elapsed)
+		return 1
+	}
+	beautify.Successf("完成 %d 篇，耗时 %v", progress.Total, elapsed)
+	return 0
+}
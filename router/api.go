@@ -20,6 +20,9 @@ func SetupAPIRoutes(mux *http.ServeMux) {
 	apiMux.HandleFunc("/tags", controller.TagsAPIHandler)
 	apiMux.HandleFunc("/categories", controller.CategoriesAPIHandler)
 	apiMux.HandleFunc("/archive", controller.ArchiveAPIHandler)
+	apiMux.HandleFunc("/search", controller.SearchAPIHandler)
+	apiMux.HandleFunc("/series", controller.SeriesAPIHandler)
+	apiMux.HandleFunc("/series/", controller.SeriesDetailAPIHandler)
 
 	// 评论API
 	apiMux.HandleFunc("/comments", controller.CommentHandler)
@@ -30,6 +33,9 @@ func SetupAPIRoutes(mux *http.ServeMux) {
 
 	// Markdown编辑器API
 	apiMux.HandleFunc("/markdown-editor/save", controller.MarkdownEditorSaveHandler)
+	apiMux.HandleFunc("/markdown-editor/preview", controller.MarkdownEditorPreviewHandler)
+	apiMux.HandleFunc("/markdown-editor/draft", controller.MarkdownEditorDraftHandler)
+	apiMux.HandleFunc("/markdown-editor/draft/publish", controller.MarkdownEditorPublishDraftHandler)
 
 	// 用户信息API
 	apiMux.HandleFunc("/user/info", controller.UserInfoHandler)
@@ -56,8 +62,21 @@ func SetupAPIRoutes(mux *http.ServeMux) {
 	// 管理后台API
 	apiMux.HandleFunc("/admin/users", admin.AdminUsersHandler)
 	apiMux.HandleFunc("/admin/passages", admin.AdminPassagesHandler)
+	apiMux.HandleFunc("/admin/passages/revisions", admin.AdminPassageRevisionsHandler)
+	apiMux.HandleFunc("/admin/passages/revisions/diff", admin.AdminPassageRevisionDiffHandler)
+	apiMux.HandleFunc("/admin/passages/revisions/restore", admin.AdminPassageRevisionRestoreHandler)
+	apiMux.HandleFunc("/admin/passages/previews", admin.AdminPassagePreviewsHandler)
+	apiMux.HandleFunc("/admin/passages/orphans", admin.AdminPassageOrphansHandler)
+	apiMux.HandleFunc("/admin/passages/rebuild", admin.AdminPassageRebuildHandler)
+	apiMux.HandleFunc("/admin/passages/sanitize-report", admin.AdminPassageSanitizeReportHandler)
+	apiMux.HandleFunc("/admin/images/backfill", admin.AdminImageBackfillHandler)
+	apiMux.HandleFunc("/admin/import", admin.AdminImportHandler)
+	apiMux.HandleFunc("/admin/backups", admin.AdminBackupsHandler)
+	apiMux.HandleFunc("/admin/backups/download", admin.AdminBackupDownloadHandler)
+	apiMux.HandleFunc("/admin/backups/verify", admin.AdminBackupVerifyHandler)
 	apiMux.HandleFunc("/admin/categories", admin.AdminCategoriesHandler)
 	apiMux.HandleFunc("/admin/tags", admin.AdminTagsHandler)
+	apiMux.HandleFunc("/admin/series", admin.AdminSeriesHandler)
 	apiMux.HandleFunc("/admin/stats", admin.AdminStatsHandler)
 	apiMux.HandleFunc("/admin/comments", admin.AdminCommentsHandler)
 	apiMux.HandleFunc("/admin/analytics", controller.AdminAnalyticsHandler)
@@ -21,6 +21,26 @@ func SetupPageRoutes(mux *http.ServeMux, baseDir string) {
 	mux.HandleFunc("/about", controller.AboutHandler)
 	mux.HandleFunc("/markdown-editor", controller.MarkdownEditorHandler)
 
+	// 分类和标签页面（含对应的订阅源）
+	mux.HandleFunc("/category/", controller.TaxonomyHandler)
+	mux.HandleFunc("/tag/", controller.TaxonomyHandler)
+
+	// 系列页面
+	mux.HandleFunc("/series/", controller.SeriesHandler)
+
+	// 订阅源
+	mux.HandleFunc("/feed.xml", controller.FeedHandler)
+	mux.HandleFunc("/atom.xml", controller.FeedHandler)
+	mux.HandleFunc("/feed.json", controller.FeedHandler)
+
+	// 搜索引擎
+	mux.HandleFunc("/sitemap.xml", controller.SitemapHandler)
+	mux.HandleFunc("/sitemaps/", controller.SitemapPageHandler)
+	mux.HandleFunc("/robots.txt", controller.RobotsHandler)
+
+	// 文章分享卡片
+	mux.HandleFunc("/og/", controller.OGImageHandler)
+
 	// 管理后台
 	mux.HandleFunc("/admin", admin.AdminHandler)
 
@@ -14,6 +14,8 @@ import (
 	"myblog-gogogo/db"
 	"myblog-gogogo/db/models"
 	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/imagevariant"
+	"myblog-gogogo/service"
 	"myblog-gogogo/service/kafka"
 	"myblog-gogogo/service/validation"
 )
@@ -50,9 +52,14 @@ func (s *Service) Upload(file multipart.File, header *multipart.FileHeader, pass
 	if err != nil {
 		return nil, fmt.Errorf("读取文件失败: %w", err)
 	}
+	return s.Save(header.Filename, content, passageID, time.Time{})
+}
 
+// Save 校验并保存文件内容
+// date 决定存储目录，为零值时使用关联文章的创建日期，没有关联文章时使用当前时间
+func (s *Service) Save(fileName string, content []byte, passageID int, date time.Time) (*UploadResult, error) {
 	// 获取文件扩展名
-	ext := strings.ToLower(filepath.Ext(header.Filename))
+	ext := strings.ToLower(filepath.Ext(fileName))
 
 	// 根据文件类型进行安全验证
 	fileType := validation.GetFileType(ext)
@@ -66,8 +73,8 @@ func (s *Service) Upload(file multipart.File, header *multipart.FileHeader, pass
 	}
 
 	// 获取文章的创建日期（如果有关联文章）
-	var articleDate time.Time
-	if passageID > 0 {
+	articleDate := date
+	if articleDate.IsZero() && passageID > 0 {
 		passageRepo := db.GetPassageRepository()
 		passage, err := passageRepo.GetByID(passageID)
 		if err == nil && passage != nil {
@@ -94,7 +101,7 @@ func (s *Service) Upload(file multipart.File, header *multipart.FileHeader, pass
 	// 生成唯一的文件名
 	now := time.Now()
 	timestamp := now.Format("20060102-150405")
-	baseName := strings.TrimSuffix(header.Filename, ext)
+	baseName := strings.TrimSuffix(fileName, ext)
 
 	var nameBuilder strings.Builder
 	nameBuilder.Grow(len(baseName) + len(timestamp) + len(ext) + 2)
@@ -118,7 +125,7 @@ func (s *Service) Upload(file multipart.File, header *multipart.FileHeader, pass
 	}
 
 	attachment := &models.Attachment{
-		FileName:      header.Filename,
+		FileName:      fileName,
 		StoredName:    storedName,
 		FilePath:      filePath,
 		FileType:      string(fileType),
@@ -135,6 +142,9 @@ func (s *Service) Upload(file multipart.File, header *multipart.FileHeader, pass
 		return nil, fmt.Errorf("保存到数据库失败: %w", err)
 	}
 
+	// 后台生成图片的缩略图与 WebP 版本
+	service.QueueImageVariants(filePath)
+
 	// 发布上传事件
 	ctx := context.Background()
 	if err := kafka.PublishAttachmentUploadEvent(ctx, attachment.ID, attachment.FileName, attachment.FileSize, attachment.FileType, passageID); err != nil {
@@ -158,7 +168,7 @@ func (s *Service) Upload(file multipart.File, header *multipart.FileHeader, pass
 	return &UploadResult{
 		ID:          attachment.ID,
 		Type:        string(fileType),
-		FileName:    header.Filename,
+		FileName:    fileName,
 		StoredName:  storedName,
 		Path:        filePath,
 		URL:         url,
@@ -168,6 +178,11 @@ func (s *Service) Upload(file multipart.File, header *multipart.FileHeader, pass
 	}, nil
 }
 
+// SetPassage 将附件关联到文章
+func (s *Service) SetPassage(id, passageID int) error {
+	return s.repo.UpdatePassageID(id, passageID)
+}
+
 // List 获取附件列表
 func (s *Service) List(passageID *int, limit, offset int) ([]*models.Attachment, int, error) {
 	if passageID != nil {
@@ -199,10 +214,11 @@ func (s *Service) Delete(id int) error {
 		return fmt.Errorf("删除数据库记录失败: %w", err)
 	}
 
-	// 删除文件
+	// 删除文件及生成的图片变体
 	if err := os.Remove(attachment.FilePath); err != nil {
 		fmt.Printf("删除文件失败: %v\n", err)
 	}
+	imagevariant.Remove(attachment.FilePath)
 
 	return nil
 }
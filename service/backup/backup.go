@@ -0,0 +1,252 @@
+// Package backup 全站备份与恢复
+// 备份为一个 tar.gz 归档，包含数据库快照、markdown、attachments、music、img 目录、
+// data/jwt-secret，以及记录每个文件大小与 SHA-256 的清单 manifest.json
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"myblog-gogogo/db"
+)
+
+// FormatVersion 归档格式版本，恢复时拒绝更高版本的归档
+const FormatVersion = 1
+
+// DefaultDir 本地备份目录，相对站点工作目录
+const DefaultDir = "backups"
+
+const (
+	// ManifestName 清单在归档中的路径，写在归档末尾，校验和在写入文件时同步计算
+	ManifestName = "manifest.json"
+	// DatabaseName 数据库快照在归档中的路径
+	DatabaseName = "db/blog.db"
+	// filePrefix 备份文件名前缀，List 与 Prune 只处理此前缀的文件
+	filePrefix = "dango-backup-"
+	fileSuffix = ".tar.gz"
+)
+
+// ContentDirs 备份的内容目录，相对站点工作目录
+var ContentDirs = []string{"markdown", "attachments", "music", "img"}
+
+// ContentFiles 备份的单个文件
+var ContentFiles = []string{"data/jwt-secret"}
+
+// Manifest 备份清单
+type Manifest struct {
+	Format    int            `json:"format"`
+	CreatedAt time.Time      `json:"created_at"`
+	DBDriver  string         `json:"db_driver"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile 清单中的一个文件
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// TotalSize 清单中文件的总大小
+func (m *Manifest) TotalSize() int64 {
+	var total int64
+	for _, f := range m.Files {
+		total += f.Size
+	}
+	return total
+}
+
+// Info 本地备份文件信息
+type Info struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Create 将数据库快照与内容目录写入 w，root 为站点工作目录
+func Create(w io.Writer, root string) (*Manifest, error) {
+	tmp, err := os.MkdirTemp("", "dango-backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	snapshot := filepath.Join(tmp, "blog.db")
+	if err := db.Snapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifest := &Manifest{Format: FormatVersion, CreatedAt: time.Now(), DBDriver: db.GetDriverName(), Files: []ManifestFile{}}
+
+	if err := addFile(tw, manifest, snapshot, DatabaseName); err != nil {
+		return nil, err
+	}
+	for _, dir := range ContentDirs {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			return addFile(tw, manifest, path, filepath.ToSlash(rel))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("备份 %s 失败: %w", dir, err)
+		}
+	}
+	for _, name := range ContentFiles {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		if err := addFile(tw, manifest, path, name); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0644, Size: int64(len(data)), ModTime: manifest.CreatedAt}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// addFile 写入一个文件并记录到清单，大小与校验和以实际写入的内容为准
+func addFile(tw *tar.Writer, manifest *Manifest, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: int64(info.Mode().Perm()), Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+		return err
+	}
+	h := sha256.New()
+	// 文件在备份过程中被截断时 CopyN 返回错误，被追加的内容不会写入
+	if _, err := io.CopyN(io.MultiWriter(tw, h), f, info.Size()); err != nil {
+		return fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	manifest.Files = append(manifest.Files, ManifestFile{Path: name, Size: info.Size(), SHA256: hex.EncodeToString(h.Sum(nil))})
+	return nil
+}
+
+// CreateFile 在 dir 中创建一个备份文件，写入完成后才以正式文件名出现
+func CreateFile(dir, root string) (Info, *Manifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Info{}, nil, err
+	}
+	now := time.Now()
+	name := filePrefix + now.Format("20060102-150405") + fileSuffix
+	tmp, err := os.CreateTemp(dir, ".tmp-"+filePrefix)
+	if err != nil {
+		return Info{}, nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := Create(tmp, root)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Info{}, nil, err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return Info{}, nil, err
+	}
+	info, err := os.Stat(filepath.Join(dir, name))
+	if err != nil {
+		return Info{}, nil, err
+	}
+	return Info{Name: name, Size: info.Size(), CreatedAt: now}, manifest, nil
+}
+
+// List 列出 dir 中的备份文件，最新的在前
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Info{}, nil
+		}
+		return nil, err
+	}
+	backups := []Info{}
+	for _, entry := range entries {
+		if !ValidName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		createdAt, err := time.ParseInLocation("20060102-150405", strings.TrimSuffix(strings.TrimPrefix(entry.Name(), filePrefix), fileSuffix), time.Local)
+		if err != nil {
+			createdAt = info.ModTime()
+		}
+		backups = append(backups, Info{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// ValidName 判断是否为本程序生成的备份文件名，下载接口用它防止读取其他文件
+func ValidName(name string) bool {
+	return strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) && filepath.Base(name) == name
+}
+
+// Prune 只保留 dir 中最新的 keep 个备份，返回删除的文件名
+func Prune(dir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	backups, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(dir, backups[i].Name)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, backups[i].Name)
+	}
+	return removed, nil
+}
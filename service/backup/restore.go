@@ -0,0 +1,350 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotEmpty 目标实例已有内容，需要强制恢复
+var ErrNotEmpty = errors.New("当前实例已有内容，使用强制恢复覆盖")
+
+// maxManifestSize 清单文件的大小上限
+const maxManifestSize = 64 << 20
+
+// RestoreOptions 恢复选项
+type RestoreOptions struct {
+	// Root 站点工作目录
+	Root string
+	// DBPath SQLite 数据库文件路径
+	DBPath string
+	// Force 目标实例已有内容时仍然恢复，原有数据移动到回滚目录
+	Force bool
+	// DryRun 只校验归档与目标实例，不写入
+	DryRun bool
+}
+
+// RestoreReport 恢复结果
+type RestoreReport struct {
+	Manifest *Manifest `json:"manifest"`
+	DryRun   bool      `json:"dry_run"`
+	// Existing 目标实例中已有的内容，非空时需要强制恢复
+	Existing []string `json:"existing,omitempty"`
+	// Rollback 被替换的原有数据所在目录，确认无误后可以删除
+	Rollback string `json:"rollback,omitempty"`
+}
+
+// Restore 校验归档并恢复到 opts.Root，恢复前服务需要停止
+func Restore(archive string, opts RestoreOptions) (*RestoreReport, error) {
+	if opts.Root == "" {
+		opts.Root = "."
+	}
+	report := &RestoreReport{DryRun: opts.DryRun}
+
+	staging := ""
+	if !opts.DryRun {
+		staging = filepath.Join(opts.Root, ".restore-"+time.Now().Format("20060102-150405"))
+		if err := os.Mkdir(staging, 0755); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(staging)
+	}
+
+	manifest, err := readArchive(archive, staging)
+	if err != nil {
+		return nil, err
+	}
+	report.Manifest = manifest
+
+	report.Existing = existingContent(opts.Root, opts.DBPath)
+	if len(report.Existing) > 0 && !opts.Force && !opts.DryRun {
+		return report, ErrNotEmpty
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	rollback := filepath.Join(opts.Root, ".restore-rollback-"+time.Now().Format("20060102-150405"))
+	moved, err := apply(staging, rollback, opts)
+	if moved {
+		report.Rollback = rollback
+	} else {
+		os.RemoveAll(rollback)
+	}
+	return report, err
+}
+
+// readArchive 读取归档并校验清单，staging 非空时把文件解压到该目录
+func readArchive(archive, staging string) (*Manifest, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("不是有效的备份归档: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *Manifest
+	actual := make(map[string]ManifestFile)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取归档失败: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("归档中包含不支持的文件类型: %s", hdr.Name)
+		}
+
+		if hdr.Name == ManifestName {
+			data, err := io.ReadAll(io.LimitReader(tr, maxManifestSize))
+			if err != nil {
+				return nil, err
+			}
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("清单格式错误: %w", err)
+			}
+			continue
+		}
+
+		if !allowedPath(hdr.Name) {
+			return nil, fmt.Errorf("归档中包含未知文件: %s", hdr.Name)
+		}
+		if _, dup := actual[hdr.Name]; dup {
+			return nil, fmt.Errorf("归档中的文件重复: %s", hdr.Name)
+		}
+		var dst io.Writer = io.Discard
+		var out *os.File
+		if staging != "" {
+			target := filepath.Join(staging, filepath.FromSlash(hdr.Name))
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return nil, err
+			}
+			if out, err = os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fs.FileMode(hdr.Mode).Perm()); err != nil {
+				return nil, err
+			}
+			dst = out
+		}
+		h := sha256.New()
+		n, err := io.Copy(io.MultiWriter(dst, h), tr)
+		if out != nil {
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解压 %s 失败: %w", hdr.Name, err)
+		}
+		actual[hdr.Name] = ManifestFile{Path: hdr.Name, Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("归档中没有 %s", ManifestName)
+	}
+	if err := verifyManifest(manifest, actual); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// allowedPath 判断归档中的路径是否属于备份内容，拒绝绝对路径与 ../
+func allowedPath(name string) bool {
+	if name != path.Clean(name) || path.IsAbs(name) || strings.HasPrefix(name, "../") {
+		return false
+	}
+	if name == DatabaseName {
+		return true
+	}
+	for _, file := range ContentFiles {
+		if name == file {
+			return true
+		}
+	}
+	for _, dir := range ContentDirs {
+		if strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyManifest 校验清单版本，以及每个文件的大小与校验和
+func verifyManifest(manifest *Manifest, actual map[string]ManifestFile) error {
+	if manifest.Format < 1 || manifest.Format > FormatVersion {
+		return fmt.Errorf("不支持的归档格式版本 %d，当前程序支持 %d", manifest.Format, FormatVersion)
+	}
+	if manifest.DBDriver != "sqlite3" {
+		return fmt.Errorf("不支持恢复 %s 数据库的备份", manifest.DBDriver)
+	}
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, want := range manifest.Files {
+		got, ok := actual[want.Path]
+		if !ok {
+			return fmt.Errorf("归档缺少清单中的文件: %s", want.Path)
+		}
+		if got.Size != want.Size || got.SHA256 != want.SHA256 {
+			return fmt.Errorf("文件校验失败: %s", want.Path)
+		}
+		listed[want.Path] = true
+	}
+	for name := range actual {
+		if !listed[name] {
+			return fmt.Errorf("归档中的文件不在清单中: %s", name)
+		}
+	}
+	if !listed[DatabaseName] {
+		return fmt.Errorf("归档中没有数据库快照")
+	}
+	return nil
+}
+
+// existingContent 列出目标实例中已有的内容：数据库中的文章、markdown 与附件
+func existingContent(root, dbPath string) []string {
+	var existing []string
+	if dbPath != "" {
+		if _, err := os.Stat(dbPath); err == nil {
+			if n := countPassages(dbPath); n > 0 {
+				existing = append(existing, fmt.Sprintf("数据库中有 %d 篇文章", n))
+			}
+		}
+	}
+	for _, dir := range []string{"markdown", "attachments"} {
+		if n := countFiles(filepath.Join(root, dir)); n > 0 {
+			existing = append(existing, fmt.Sprintf("%s 目录中有 %d 个文件", dir, n))
+		}
+	}
+	return existing
+}
+
+// countPassages 以只读方式统计数据库中的文章数，表不存在时为 0
+func countPassages(dbPath string) int {
+	conn, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return 0
+	}
+	defer conn.Close()
+	var n int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM passages").Scan(&n); err != nil {
+		return 0
+	}
+	return n
+}
+
+// countFiles 统计目录中的文件数
+func countFiles(dir string) int {
+	n := 0
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+// apply 用解压好的内容替换当前数据，原有数据移动到 rollback，返回是否移动过原有数据
+func apply(staging, rollback string, opts RestoreOptions) (bool, error) {
+	moved := false
+	replace := func(src, dst, name string) error {
+		if _, err := os.Lstat(dst); err == nil {
+			backup := filepath.Join(rollback, name)
+			if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+				return err
+			}
+			if err := move(dst, backup); err != nil {
+				return fmt.Errorf("移动原有 %s 失败: %w", name, err)
+			}
+			moved = true
+		}
+		if src == "" {
+			return nil
+		}
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := move(src, dst); err != nil {
+			return fmt.Errorf("恢复 %s 失败: %w", name, err)
+		}
+		return nil
+	}
+
+	// 数据库连同 WAL 文件一起替换，避免旧的 WAL 被应用到新数据库
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := replace("", opts.DBPath+suffix, "db/"+filepath.Base(opts.DBPath)+suffix); err != nil {
+			return moved, err
+		}
+	}
+	if err := replace(filepath.Join(staging, filepath.FromSlash(DatabaseName)), opts.DBPath, "db/"+filepath.Base(opts.DBPath)); err != nil {
+		return moved, err
+	}
+	for _, dir := range ContentDirs {
+		if err := replace(filepath.Join(staging, dir), filepath.Join(opts.Root, dir), dir); err != nil {
+			return moved, err
+		}
+	}
+	for _, name := range ContentFiles {
+		if err := replace(filepath.Join(staging, filepath.FromSlash(name)), filepath.Join(opts.Root, filepath.FromSlash(name)), name); err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// move 移动文件或目录，跨文件系统时复制后删除
+func move(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if err := os.CopyFS(dst, os.DirFS(src)); err != nil {
+			return err
+		}
+		return os.RemoveAll(src)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeArchive 按 Create 的格式写入归档，tamper 可以在写入清单前修改它
+func writeArchive(t *testing.T, files map[string]string, tamper func(*Manifest)) string {
+	t.Helper()
+	src := t.TempDir()
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	manifest := &Manifest{Format: FormatVersion, CreatedAt: time.Now(), DBDriver: "sqlite3"}
+	for name, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := addFile(tw, manifest, path, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tamper != nil {
+		tamper(manifest)
+	}
+	data, _ := json.Marshal(manifest)
+	tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0644, Size: int64(len(data))})
+	tw.Write(data)
+	tw.Close()
+	gz.Close()
+	return archive
+}
+
+func TestRestore(t *testing.T) {
+	archive := writeArchive(t, map[string]string{
+		DatabaseName:               "db",
+		"markdown/2026/01/02/a.md": "# a",
+		"data/jwt-secret":          "secret",
+	}, nil)
+
+	root := t.TempDir()
+	dbPath := filepath.Join(root, "db", "data", "blog.db")
+
+	report, err := Restore(archive, RestoreOptions{Root: root, DBPath: dbPath, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(report.Manifest.Files) != 3 {
+		t.Errorf("manifest files = %d", len(report.Manifest.Files))
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatal("dry run wrote the database")
+	}
+
+	if _, err := Restore(archive, RestoreOptions{Root: root, DBPath: dbPath}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for path, want := range map[string]string{
+		dbPath: "db",
+		filepath.Join(root, "markdown", "2026", "01", "02", "a.md"): "# a",
+		filepath.Join(root, "data", "jwt-secret"):                   "secret",
+	} {
+		if got, err := os.ReadFile(path); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v", path, got, err)
+		}
+	}
+
+	// 已有 markdown 时需要强制恢复，原有数据移动到回滚目录
+	_, err = Restore(archive, RestoreOptions{Root: root, DBPath: dbPath})
+	if !errors.Is(err, ErrNotEmpty) {
+		t.Fatalf("expected ErrNotEmpty, got %v", err)
+	}
+	report, err = Restore(archive, RestoreOptions{Root: root, DBPath: dbPath, Force: true})
+	if err != nil {
+		t.Fatalf("forced restore: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(report.Rollback, "markdown", "2026", "01", "02", "a.md")); err != nil {
+		t.Errorf("previous markdown not kept in rollback: %v", err)
+	}
+}
+
+func TestRestoreRejectsInvalidArchive(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		tamper func(*Manifest)
+		want   string
+	}{
+		{"checksum", map[string]string{DatabaseName: "db"}, func(m *Manifest) { m.Files[0].SHA256 = strings.Repeat("0", 64) }, "校验失败"},
+		{"future format", map[string]string{DatabaseName: "db"}, func(m *Manifest) { m.Format = FormatVersion + 1 }, "格式版本"},
+		{"unlisted file", map[string]string{DatabaseName: "db", "markdown/a.md": "a"}, func(m *Manifest) { m.Files = m.Files[:1] }, "不在清单中"},
+		{"missing database", map[string]string{"markdown/a.md": "a"}, nil, "数据库快照"},
+		{"unknown path", map[string]string{DatabaseName: "db", "etc/passwd": "x"}, nil, "未知文件"},
+		{"traversal", map[string]string{DatabaseName: "db", "markdown/../../x": "x"}, nil, "未知文件"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := writeArchive(t, tt.files, tt.tamper)
+			_, err := Restore(archive, RestoreOptions{Root: t.TempDir(), DryRun: true})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"dango-backup-20260101-000000.tar.gz",
+		"dango-backup-20260103-000000.tar.gz",
+		"dango-backup-20260102-000000.tar.gz",
+		"notes.txt",
+	} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+	removed, err := Prune(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != "dango-backup-20260101-000000.tar.gz" {
+		t.Errorf("removed = %v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Error("unrelated file removed")
+	}
+}
@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Scheduler 定时本地备份，每次备份后只保留最新的 keep 个
+type Scheduler struct {
+	dir      string
+	root     string
+	interval time.Duration
+	keep     int
+	quit     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+// NewScheduler 创建定时备份任务
+func NewScheduler(dir, root string, interval time.Duration, keep int) *Scheduler {
+	return &Scheduler{
+		dir:      dir,
+		root:     root,
+		interval: interval,
+		keep:     keep,
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start 启动定时备份
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop 停止定时备份并等待正在进行的备份完成
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		close(s.quit)
+	})
+	s.wg.Wait()
+}
+
+// loop 按间隔执行备份
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			s.run()
+		}
+	}
+}
+
+// run 执行一次备份并清理旧备份
+func (s *Scheduler) run() {
+	info, _, err := CreateFile(s.dir, s.root)
+	if err != nil {
+		log.Printf("Warning: 定时备份失败: %v", err)
+		return
+	}
+	log.Printf("定时备份完成: %s (%d bytes)", info.Name, info.Size)
+	removed, err := Prune(s.dir, s.keep)
+	if err != nil {
+		log.Printf("Warning: 清理旧备份失败: %v", err)
+	}
+	for _, name := range removed {
+		log.Printf("已删除旧备份: %s", name)
+	}
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/frontmatter"
+	"myblog-gogogo/pkg/toc"
+)
+
+// EditorPassage 在线编辑器提交的文章，Content 开头可以带有头部元数据
+type EditorPassage struct {
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Category string `json:"category"`
+	Tags     string `json:"tags"`
+	Summary  string `json:"summary"`
+}
+
+// parseEditorContent 解析内容开头的头部元数据，正文中不保留头部，头部中的标题与标签优先
+func parseEditorContent(input *EditorPassage) (*frontmatter.FrontMatter, error) {
+	fm, body, err := frontmatter.Parse([]byte(input.Content))
+	if err != nil {
+		return nil, fmt.Errorf("头部元数据解析失败: %w", err)
+	}
+	if fm != nil {
+		input.Content = string(body)
+		if fm.Title != "" {
+			input.Title = fm.Title
+		}
+		if input.Tags == "" {
+			input.Tags = fm.TagString()
+		}
+	}
+
+	if input.Title == "" {
+		return nil, fmt.Errorf("文章标题不能为空")
+	}
+	if input.Content == "" {
+		return nil, fmt.Errorf("文章内容不能为空")
+	}
+	return fm, nil
+}
+
+// CreateEditorPassage 将编辑器内容发布为新文章
+// 按日期写入 markdown 文件并创建文章记录、初始修订、双链、系列与标签关联，policy 为按保存者角色确定的过滤策略
+func CreateEditorPassage(input EditorPassage, author, policy string) (*models.Passage, error) {
+	fm, err := parseEditorContent(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	// 构建文件路径（按日期组织）
+	now := time.Now()
+	dateDir := now.Format("2006/01/02")
+	filePath := strings.Join([]string{"markdown", dateDir, input.Title + ".md"}, "/")
+
+	if input.Category == "" {
+		input.Category = "未分类"
+	}
+	if input.Summary == "" {
+		input.Summary = "暂无摘要"
+	}
+
+	passage := &models.Passage{
+		Title:           input.Title,
+		OriginalContent: input.Content,
+		Summary:         input.Summary,
+		Author:          author,
+		Category:        input.Category,
+		Status:          "published",
+		FilePath:        filePath,
+		ShowTitle:       true,
+		HTMLPolicy:      policy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	fm.ApplyTo(passage)
+
+	if err := RenderPassage(passage); err != nil {
+		return nil, fmt.Errorf("Markdown转换失败: %w", err)
+	}
+
+	// 保存 Markdown 文件到磁盘，内容带有头部时按原格式回写
+	fm.Update(passage, ParseTagNames(input.Tags))
+	if err := UpdateMarkdownFileWithFrontMatter(filePath, fm, passage.Title, input.Content); err != nil {
+		return nil, fmt.Errorf("保存Markdown文件失败: %w", err)
+	}
+
+	repo := db.GetPassageRepository()
+	if err := repo.Create(passage); err != nil {
+		return nil, fmt.Errorf("保存到数据库失败: %w", err)
+	}
+
+	if _, err := NewRevisionService().Record(passage, author, models.RevisionSourceEditor); err != nil {
+		log.Printf("Warning: 记录修订失败: %v", err)
+	}
+	if err := UpdatePassageLinks(passage); err != nil {
+		log.Printf("Warning: 更新双链失败: %v", err)
+	}
+	if err := syncPassageSeries(passage.ID, fm); err != nil {
+		log.Printf("Warning: 设置文章系列失败: %v", err)
+	}
+	if err := syncPassageMeta(passage.ID, fm); err != nil {
+		log.Printf("Warning: 设置分享元数据失败: %v", err)
+	}
+	if input.Tags != "" {
+		if err := NewSyncService(repo).UpdatePassageTags(passage.ID, input.Tags); err != nil {
+			log.Printf("Warning: 创建标签关联失败: %v", err)
+		}
+	}
+
+	return passage, nil
+}
+
+// MarkdownPreview 编辑器预览的渲染结果
+type MarkdownPreview struct {
+	Title    string      `json:"title"`
+	HTML     string      `json:"html"`
+	TOC      []toc.Entry `json:"toc"`
+	Notes    []string    `json:"notes"`    // 按作者的过滤策略被移除或改写的内容
+	Warnings []string    `json:"warnings"` // 嵌入的音乐、附件或文章不存在
+}
+
+// PreviewMarkdown 渲染编辑器预览，不写入文件也不创建文章
+// 与 ConvertToHTMLWithOption 使用同一转换流程，并像 RenderPassage 一样过滤原始 HTML，
+// 头部元数据中的 show_title、toc_depth 同样生效，预览与发布后的文章一致
+// passage 为草稿对应的已有文章，为 nil 时按新文章的默认值渲染；policy 为按预览者角色确定的过滤策略，发布时使用同一策略
+func PreviewMarkdown(content string, passage *models.Passage, author, policy string) (*MarkdownPreview, error) {
+	fm, body, err := frontmatter.Parse([]byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("头部元数据解析失败: %w", err)
+	}
+
+	target := &models.Passage{Author: author, ShowTitle: true}
+	if passage != nil {
+		target.Title = passage.Title
+		target.Author = passage.Author
+		target.ShowTitle = passage.ShowTitle
+		target.TOCDepth = passage.TOCDepth
+	}
+	target.OriginalContent = string(body)
+	fm.ApplyTo(target)
+
+	pc := newPolicyContext(HTMLPolicy(policy))
+	html, entries, err := convertWithTOC(pc, []byte(target.OriginalContent), target.ShowTitle, target.TOCDepth)
+	if err != nil {
+		return nil, fmt.Errorf("Markdown转换失败: %w", err)
+	}
+	if entries == nil {
+		entries = []toc.Entry{}
+	}
+
+	return &MarkdownPreview{
+		Title:    target.Title,
+		HTML:     html,
+		TOC:      entries,
+		Notes:    htmlNotes(pc),
+		Warnings: EmbedErrors([]byte(target.OriginalContent)),
+	}, nil
+}
+
+// DraftService 编辑器草稿服务
+// 草稿按用户与文章保存，自动保存只写入 drafts 表，发布时才写入 markdown 文件并创建或更新文章
+type DraftService struct {
+	draftRepo   repositories.DraftRepository
+	passageRepo repositories.PassageRepository
+}
+
+// NewDraftService 创建编辑器草稿服务
+func NewDraftService() *DraftService {
+	return &DraftService{
+		draftRepo:   db.GetDraftRepository(),
+		passageRepo: db.GetPassageRepository(),
+	}
+}
+
+// Save 自动保存草稿，同一用户对同一文章的草稿会被覆盖
+func (s *DraftService) Save(draft *models.Draft) error {
+	if draft.UserID <= 0 {
+		return fmt.Errorf("无效的用户")
+	}
+	if draft.PassageID < 0 {
+		return fmt.Errorf("无效的文章ID")
+	}
+	if draft.PassageID > 0 {
+		passage, err := s.passageRepo.GetByID(draft.PassageID)
+		if err != nil {
+			return err
+		}
+		if passage == nil {
+			return fmt.Errorf("文章不存在")
+		}
+	}
+	return s.draftRepo.Save(draft)
+}
+
+// Get 获取用户对某篇文章的草稿，passageID 为 0 时获取新文章草稿，没有草稿时返回 nil
+func (s *DraftService) Get(userID, passageID int) (*models.Draft, error) {
+	return s.draftRepo.Get(userID, passageID)
+}
+
+// List 获取用户的所有草稿，最近修改的在前
+func (s *DraftService) List(userID int) ([]models.Draft, error) {
+	return s.draftRepo.GetByUserID(userID)
+}
+
+// Discard 丢弃草稿
+func (s *DraftService) Discard(userID, passageID int) error {
+	return s.draftRepo.Delete(userID, passageID)
+}
+
+// Publish 发布草稿：新文章草稿创建文章，已有文章的草稿覆盖文章内容，发布成功后删除草稿
+func (s *DraftService) Publish(userID, passageID int, author string) (*models.Passage, error) {
+	draft, err := s.draftRepo.Get(userID, passageID)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		return nil, fmt.Errorf("草稿不存在")
+	}
+
+	input := EditorPassage{
+		Title:    draft.Title,
+		Content:  draft.Content,
+		Category: draft.Category,
+		Tags:     draft.Tags,
+		Summary:  draft.Summary,
+	}
+	// 过滤策略由发布草稿的用户角色决定
+	policy := HTMLPolicyForUser(userID)
+	var passage *models.Passage
+	if draft.PassageID == 0 {
+		passage, err = CreateEditorPassage(input, author, policy)
+	} else {
+		passage, err = s.applyDraft(draft.PassageID, input, author, policy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Delete(userID, passageID); err != nil {
+		log.Printf("Warning: 删除已发布的草稿失败: %v", err)
+	}
+	return passage, nil
+}
+
+// applyDraft 用草稿内容覆盖已有文章，重写磁盘上的 markdown 文件并记录修订
+// 草稿中的标题、分类、摘要与标签为空时保留文章原有的值
+func (s *DraftService) applyDraft(passageID int, input EditorPassage, author, policy string) (*models.Passage, error) {
+	passage, err := s.passageRepo.GetByID(passageID)
+	if err != nil {
+		return nil, err
+	}
+	if passage == nil {
+		return nil, fmt.Errorf("文章不存在")
+	}
+
+	if input.Title == "" {
+		input.Title = passage.Title
+	}
+	fm, err := parseEditorContent(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	// 覆盖前确保当前内容也有记录，发布后仍可恢复
+	revisions := NewRevisionService()
+	if err := revisions.RecordBaseline(passage); err != nil {
+		log.Printf("Warning: 记录初始修订失败: %v", err)
+	}
+
+	passage.Title = input.Title
+	passage.OriginalContent = input.Content
+	if input.Category != "" {
+		passage.Category = input.Category
+	}
+	if input.Summary != "" {
+		passage.Summary = input.Summary
+	}
+	fm.ApplyTo(passage)
+	passage.HTMLPolicy = policy
+
+	if err := RenderPassage(passage); err != nil {
+		return nil, fmt.Errorf("Markdown转换失败: %w", err)
+	}
+
+	// 草稿或原文件带有头部元数据时按原格式回写
+	syncService := NewSyncService(s.passageRepo)
+	filePath := PassageMarkdownPath(passage.FilePath)
+	header := fm
+	if header == nil {
+		header = ReadFrontMatter(filePath)
+	}
+	tagNames := ParseTagNames(input.Tags)
+	if input.Tags == "" {
+		if tagNames, err = syncService.GetPassageTagNames(passage.ID); err != nil {
+			log.Printf("Warning: 获取文章标签失败: %v", err)
+		}
+	}
+	header.Update(passage, tagNames)
+	if err := UpdateMarkdownFileWithFrontMatter(filePath, header, passage.Title, passage.OriginalContent); err != nil {
+		return nil, fmt.Errorf("重写markdown文件失败: %w", err)
+	}
+
+	if err := s.passageRepo.Update(passage); err != nil {
+		return nil, fmt.Errorf("更新文章失败: %w", err)
+	}
+
+	if _, err := revisions.Record(passage, author, models.RevisionSourceEditor); err != nil {
+		log.Printf("Warning: 记录修订失败: %v", err)
+	}
+	if err := UpdatePassageLinks(passage); err != nil {
+		log.Printf("Warning: 更新双链失败: %v", err)
+	}
+	if err := syncPassageSeries(passage.ID, fm); err != nil {
+		log.Printf("Warning: 设置文章系列失败: %v", err)
+	}
+	if err := syncPassageMeta(passage.ID, fm); err != nil {
+		log.Printf("Warning: 设置分享元数据失败: %v", err)
+	}
+	if input.Tags != "" {
+		if err := syncService.UpdatePassageTags(passage.ID, input.Tags); err != nil {
+			log.Printf("Warning: 更新标签关联失败: %v", err)
+		}
+	}
+
+	return passage, nil
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+)
+
+// 嵌入类型
+const (
+	EmbedMusic      = "music"
+	EmbedAttachment = "attachment"
+	EmbedPassage    = "passage"
+)
+
+// embedNames 嵌入类型的中文名称，用于错误提示
+var embedNames = map[string]string{
+	EmbedMusic:      "音乐",
+	EmbedAttachment: "附件",
+	EmbedPassage:    "文章",
+}
+
+// EmbedNode 嵌入节点，由 ![](music:/12) 或 {{< music id=12 >}} 生成
+// 渲染结果只使用行内元素，可以安全地放在段落中，由 CSS 显示为卡片
+type EmbedNode struct {
+	ast.BaseInline
+	EmbedType string // music、attachment、passage
+	Ref       string // 音乐或附件的 ID，文章的别名、ID 或标题
+	Caption   string // 自定义标题，为空时使用被嵌入对象的名称
+	html      string // 解析后的 HTML
+	err       string // 解析失败的原因
+}
+
+// KindEmbed 嵌入节点类型
+var KindEmbed = ast.NewNodeKind("Embed")
+
+// Kind 实现 Node 接口
+func (n *EmbedNode) Kind() ast.NodeKind {
+	return KindEmbed
+}
+
+// Dump 实现 Node 接口
+func (n *EmbedNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"EmbedType": n.EmbedType, "Ref": n.Ref}, nil)
+}
+
+// embedErrorsKey 解析上下文中记录嵌入错误的键
+var embedErrorsKey = parser.NewContextKey()
+
+var (
+	// shortcodeRegex {{< music id=12 >}}、{{< passage my-slug >}}
+	shortcodeRegex = regexp.MustCompile(`^\{\{<\s*(music|attachment|passage)\s+(.*?)\s*>\}\}`)
+	// shortcodeArgRegex 短代码参数 key=value、key="value" 或单独的值
+	shortcodeArgRegex = regexp.MustCompile(`(?:([A-Za-z]+)=)?(?:"([^"]*)"|(\S+))`)
+)
+
+// shortcodeParser 解析 {{< type 参数 >}} 形式的嵌入短代码
+type shortcodeParser struct{}
+
+func (p *shortcodeParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *shortcodeParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	m := shortcodeRegex.FindSubmatch(line)
+	if m == nil {
+		return nil
+	}
+
+	node := &EmbedNode{EmbedType: string(m[1])}
+	for _, arg := range shortcodeArgRegex.FindAllSubmatch(m[2], -1) {
+		value := string(arg[2]) + string(arg[3])
+		switch string(arg[1]) {
+		case "", "id", "slug", "src":
+			if node.Ref == "" {
+				node.Ref = value
+			}
+		case "caption", "title":
+			node.Caption = value
+		}
+	}
+
+	block.Advance(len(m[0]))
+	return node
+}
+
+// EmbedASTTransformer 将 music:/、attachment:/、passage:/ 图片链接转换为嵌入节点，并在服务端解析所有嵌入
+type EmbedASTTransformer struct{}
+
+// Transform 转换 AST
+func (t *EmbedASTTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	// 先收集再替换，避免遍历过程中修改树
+	var images []*ast.Image
+	var embeds []*EmbedNode
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.Image:
+			images = append(images, v)
+		case *EmbedNode:
+			embeds = append(embeds, v)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, image := range images {
+		scheme, ref, ok := strings.Cut(string(image.Destination), ":")
+		if !ok || embedNames[scheme] == "" {
+			continue
+		}
+		embed := &EmbedNode{
+			EmbedType: scheme,
+			Ref:       strings.TrimLeft(ref, "/"),
+			Caption:   strings.TrimSpace(string(image.Text(source))),
+		}
+		image.Parent().ReplaceChild(image.Parent(), image, embed)
+		embeds = append(embeds, embed)
+	}
+
+	var errs []string
+	for _, embed := range embeds {
+		resolveEmbed(embed)
+		if embed.err != "" {
+			errs = append(errs, embed.err)
+		}
+	}
+	pc.Set(embedErrorsKey, errs)
+}
+
+// EmbedRenderer 嵌入渲染器
+type EmbedRenderer struct{}
+
+// RegisterFuncs 注册渲染函数
+func (r *EmbedRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindEmbed, r.renderEmbed)
+}
+
+// renderEmbed 渲染嵌入节点，解析失败时输出醒目的错误提示
+func (r *EmbedRenderer) renderEmbed(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*EmbedNode)
+	if n.err != "" {
+		fmt.Fprintf(w, `<span class="embed embed-error" role="alert">%s</span>`, html.EscapeString(n.err))
+	} else {
+		_, _ = w.WriteString(n.html)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// EmbedExtension 嵌入扩展
+type EmbedExtension struct{}
+
+// Extend 扩展 Goldmark
+func (e *EmbedExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithInlineParsers(util.Prioritized(&shortcodeParser{}, 100)),
+		parser.WithASTTransformers(util.Prioritized(&EmbedASTTransformer{}, 100)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&EmbedRenderer{}, 100)))
+}
+
+// EmbedErrors 检查 markdown 中引用的音乐、附件和文章是否存在，返回错误描述
+func EmbedErrors(markdownContent []byte) []string {
+	pc := parser.NewContext()
+	md.Parser().Parse(text.NewReader(markdownContent), parser.WithContext(pc))
+	errs, _ := pc.Get(embedErrorsKey).([]string)
+	return errs
+}
+
+// resolveEmbed 查询被嵌入的对象并生成 HTML
+func resolveEmbed(n *EmbedNode) {
+	name := embedNames[n.EmbedType]
+	if n.Ref == "" {
+		n.err = fmt.Sprintf("嵌入失败：缺少%s的引用", name)
+		return
+	}
+
+	var err error
+	switch n.EmbedType {
+	case EmbedMusic:
+		n.html, err = renderMusicEmbed(n)
+	case EmbedAttachment:
+		n.html, err = renderAttachmentEmbed(n)
+	case EmbedPassage:
+		n.html, err = renderPassageEmbed(n)
+	}
+	if err != nil {
+		n.err = fmt.Sprintf("嵌入失败：%s %s %v", name, n.Ref, err)
+	}
+}
+
+// errEmbedNotFound 被嵌入的对象不存在
+var errEmbedNotFound = errors.New("不存在")
+
+// renderMusicEmbed 音乐播放器卡片
+func renderMusicEmbed(n *EmbedNode) (string, error) {
+	id, err := strconv.Atoi(n.Ref)
+	if err != nil {
+		return "", errors.New("不是有效的 ID")
+	}
+	database := db.GetDB()
+	if database == nil {
+		return "", errEmbedNotFound
+	}
+
+	var title, artist, fileName, cover string
+	err = database.QueryRow(`SELECT title, artist, file_name, cover_image FROM music_tracks WHERE id = ?`, id).
+		Scan(&title, &artist, &fileName, &cover)
+	if err == sql.ErrNoRows {
+		return "", errEmbedNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if n.Caption != "" {
+		title = n.Caption
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<span class="embed embed-music">`)
+	if cover != "" {
+		fmt.Fprintf(&buf, `<img class="embed-cover" src="%s" alt="" loading="lazy" />`, html.EscapeString(cover))
+	}
+	fmt.Fprintf(&buf, `<span class="embed-body"><span class="embed-title">%s</span>`, html.EscapeString(title))
+	if artist != "" {
+		fmt.Fprintf(&buf, `<span class="embed-meta">%s</span>`, html.EscapeString(artist))
+	}
+	fmt.Fprintf(&buf, `<audio controls preload="none" src="%s"></audio></span></span>`, html.EscapeString("/music/"+fileName))
+	return buf.String(), nil
+}
+
+// renderAttachmentEmbed 附件下载卡片
+// 私密附件不显示文件名，受保护附件提示登录，实际的权限由下载接口校验
+func renderAttachmentEmbed(n *EmbedNode) (string, error) {
+	id, err := strconv.Atoi(n.Ref)
+	if err != nil {
+		return "", errors.New("不是有效的 ID")
+	}
+	repo := db.GetAttachmentRepository()
+	if repo == nil {
+		return "", errEmbedNotFound
+	}
+	attachment, err := repo.GetByID(id)
+	if err != nil {
+		return "", err
+	}
+	if attachment == nil {
+		return "", errEmbedNotFound
+	}
+
+	name := attachment.FileName
+	if n.Caption != "" {
+		name = n.Caption
+	}
+	meta := formatFileSize(attachment.FileSize)
+	switch attachment.Visibility {
+	case "private":
+		name = "私密附件"
+		meta = "仅管理员可下载"
+	case "protected":
+		meta += " · 登录后可下载"
+	}
+
+	href := "/api/attachments/download?id=" + strconv.Itoa(attachment.ID)
+	return fmt.Sprintf(`<a class="embed embed-attachment embed-%s" href="%s" download><span class="embed-title">%s</span><span class="embed-meta">%s</span></a>`,
+		html.EscapeString(visibilityOrPublic(attachment.Visibility)), html.EscapeString(href), html.EscapeString(name), html.EscapeString(meta)), nil
+}
+
+// renderPassageEmbed 文章预览卡片，只能嵌入已发布的公开或受密码保护的文章，受保护的文章不显示摘要
+func renderPassageEmbed(n *EmbedNode) (string, error) {
+	passage := findEmbeddedPassage(n.Ref)
+	if passage == nil {
+		return "", errEmbedNotFound
+	}
+
+	title := passage.Title
+	if n.Caption != "" {
+		title = n.Caption
+	}
+	meta := passage.CreatedAt.Format("2006-01-02")
+	if passage.Category != "" {
+		meta += " · " + passage.Category
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<a class="embed embed-passage" href="%s"><span class="embed-title">%s</span><span class="embed-meta">%s</span>`,
+		html.EscapeString(PassagePath(passage)), html.EscapeString(title), html.EscapeString(meta))
+	if visibilityOrPublic(passage.Visibility) == "public" && passage.Summary != "" {
+		fmt.Fprintf(&buf, `<span class="embed-summary">%s</span>`, html.EscapeString(passage.Summary))
+	}
+	buf.WriteString(`</a>`)
+	return buf.String(), nil
+}
+
+// findEmbeddedPassage 按 ID、别名或标题查找可以嵌入的文章
+// 私密与未发布的文章按不存在处理，避免卡片把它们的标题与摘要带到公开页面
+func findEmbeddedPassage(ref string) *models.Passage {
+	repo := db.GetPassageRepository()
+	if repo == nil {
+		return nil
+	}
+	if id, err := strconv.Atoi(ref); err == nil {
+		if passage, err := repo.GetByID(id); err == nil && embeddablePassage(passage) {
+			return passage
+		}
+	}
+	if passage := ResolveWikiLink(ref); embeddablePassage(passage) {
+		return passage
+	}
+	return nil
+}
+
+// embeddablePassage 判断文章是否可以嵌入：已发布的公开或受密码保护的文章
+func embeddablePassage(passage *models.Passage) bool {
+	return passage != nil && (IsPublicPassage(passage) || (passage.Status == "published" && IsProtectedPassage(passage)))
+}
+
+// visibilityOrPublic 未设置可见性的旧数据按公开处理
+func visibilityOrPublic(visibility string) string {
+	if visibility == "" {
+		return "public"
+	}
+	return visibility
+}
+
+// formatFileSize 格式化文件大小
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
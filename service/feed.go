@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/feed"
+	"myblog-gogogo/service/settings"
+)
+
+// maxFeedItems 订阅源条目数上限
+const maxFeedItems = 100
+
+// FeedService 订阅源服务，只包含已发布的公开文章
+type FeedService struct {
+	passageRepo    repositories.PassageRepository
+	tagRepo        repositories.TagRepository
+	passageTagRepo repositories.PassageTagRepository
+}
+
+// NewFeedService 创建订阅源服务
+func NewFeedService() *FeedService {
+	return &FeedService{
+		passageRepo:    db.GetPassageRepository(),
+		tagRepo:        db.GetTagRepository(),
+		passageTagRepo: db.GetPassageTagRepository(),
+	}
+}
+
+// FeedScope 订阅源范围，Category 与 Tag 都为空时为全站
+type FeedScope struct {
+	Category string
+	Tag      string
+}
+
+// Build 生成订阅源，baseURL 为不带结尾斜杠的站点根地址
+// 指定的标签不存在时返回 nil
+func (s *FeedService) Build(baseURL string, scope FeedScope) (*feed.Feed, error) {
+	template, err := settings.GetTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("获取模板设置失败: %w", err)
+	}
+
+	limit := template.FeedItemCount
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxFeedItems {
+		limit = maxFeedItems
+	}
+
+	title := template.Foodes
+	if title == "" {
+		title = template.Name
+	}
+
+	f := &feed.Feed{
+		Title:       title,
+		Description: template.Greting,
+		Link:        baseURL + "/",
+		Language:    "zh-CN",
+		Author:      title,
+	}
+
+	tagID := 0
+	switch {
+	case scope.Tag != "":
+		tag, err := s.findTag(scope.Tag)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil {
+			return nil, nil
+		}
+		tagID = tag.ID
+		f.Title = fmt.Sprintf("%s - 标签: %s", title, tag.Name)
+		f.Link = baseURL + "/tag/" + url.PathEscape(tag.Name)
+	case scope.Category != "":
+		f.Title = fmt.Sprintf("%s - 分类: %s", title, scope.Category)
+		f.Link = baseURL + "/category/" + url.PathEscape(scope.Category)
+	}
+
+	passages, err := s.passageRepo.GetPublic(scope.Category, tagID, limit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取文章失败: %w", err)
+	}
+
+	tagNames, err := s.tagNamesByPassage(passages)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range passages {
+		passage := &passages[i]
+		item := feed.Item{
+			Title:     passage.Title,
+			Link:      baseURL + PassagePath(passage),
+			Summary:   passage.Summary,
+			Author:    passage.Author,
+			Published: PassagePublishedAt(passage),
+			Updated:   passage.UpdatedAt,
+		}
+		// 链接可能随标题变化，使用基于ID的固定标识
+		item.ID = baseURL + "/passage?id=" + strconv.Itoa(passage.ID)
+		if passage.Category != "" {
+			item.Categories = append(item.Categories, passage.Category)
+		}
+		item.Categories = append(item.Categories, tagNames[passage.ID]...)
+		if template.FeedFullContent {
+			EnsureRendered(passage)
+			item.Content = passage.Content
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
+
+// findTag 按名称查找标签（不区分大小写）
+func (s *FeedService) findTag(name string) (*models.Tag, error) {
+	tags, err := s.tagRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("获取标签失败: %w", err)
+	}
+	for i := range tags {
+		if strings.EqualFold(tags[i].Name, name) {
+			return &tags[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// tagNamesByPassage 批量获取文章的标签名称
+func (s *FeedService) tagNamesByPassage(passages []models.Passage) (map[int][]string, error) {
+	ids := make([]int, len(passages))
+	for i, passage := range passages {
+		ids[i] = passage.ID
+	}
+	tagIDs, err := s.passageTagRepo.GetTagIDsByPassageIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("获取文章标签失败: %w", err)
+	}
+
+	tags, err := s.tagRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("获取标签失败: %w", err)
+	}
+	names := make(map[int]string, len(tags))
+	for _, tag := range tags {
+		names[tag.ID] = tag.Name
+	}
+
+	result := make(map[int][]string, len(tagIDs))
+	for passageID, ids := range tagIDs {
+		result[passageID] = resolveTagNames(ids, names)
+	}
+	return result, nil
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/pkg/imagevariant"
+)
+
+// imageSizes 图片在文章中的显示宽度，与 .article 的最大宽度一致
+const imageSizes = "(max-width: 900px) 100vw, 900px"
+
+// localImageRoots 本地图片的 URL 前缀与磁盘目录
+var localImageRoots = []string{"img", "attachments"}
+
+// PictureNode 包裹本地图片的 <picture>，提供 WebP 版本
+type PictureNode struct {
+	ast.BaseInline
+	WebPSrcset string
+}
+
+// KindPicture picture 节点类型
+var KindPicture = ast.NewNodeKind("Picture")
+
+// Kind 实现 Node 接口
+func (n *PictureNode) Kind() ast.NodeKind {
+	return KindPicture
+}
+
+// Dump 实现 Node 接口
+func (n *PictureNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"WebPSrcset": n.WebPSrcset}, nil)
+}
+
+// ResponsiveImageASTTransformer 为 /img/ 与 /attachments/ 下的图片补充宽高、懒加载与 srcset
+// 只使用已经生成的变体，变体尚未生成时仍输出原图
+type ResponsiveImageASTTransformer struct{}
+
+// Transform 转换 AST
+func (t *ResponsiveImageASTTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	var images []*ast.Image
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := n.(*ast.Image); ok && entering {
+			images = append(images, img)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, img := range images {
+		file, ok := localImagePath(string(img.Destination))
+		if !ok {
+			continue
+		}
+		width, height, variants, err := imagevariant.Available(file)
+		if err != nil {
+			continue
+		}
+
+		setDefaultAttribute(img, "width", strconv.Itoa(width))
+		setDefaultAttribute(img, "height", strconv.Itoa(height))
+		setDefaultAttribute(img, "loading", "lazy")
+		setDefaultAttribute(img, "decoding", "async")
+
+		// 与原图同格式的缩略图放在 <img srcset>，其余 WebP 版本放在 <source>
+		sourceWebP := strings.EqualFold(filepath.Ext(file), ".webp")
+		var srcset, webpSrcset []string
+		for _, v := range variants {
+			w := v.Width
+			if w == 0 {
+				w = width
+			}
+			candidate := fileURL(v.Path) + " " + strconv.Itoa(w) + "w"
+			if v.WebP == sourceWebP {
+				srcset = append(srcset, candidate)
+			} else {
+				webpSrcset = append(webpSrcset, candidate)
+			}
+		}
+		if len(srcset) > 0 {
+			srcset = append(srcset, fileURL(file)+" "+strconv.Itoa(width)+"w")
+			setDefaultAttribute(img, "srcset", strings.Join(srcset, ", "))
+			setDefaultAttribute(img, "sizes", imageSizes)
+		}
+		if len(webpSrcset) > 0 {
+			picture := &PictureNode{WebPSrcset: strings.Join(webpSrcset, ", ")}
+			img.Parent().ReplaceChild(img.Parent(), img, picture)
+			picture.AppendChild(picture, img)
+		}
+	}
+}
+
+// setDefaultAttribute 设置图片属性，markdown 中已经指定的属性保持不变
+func setDefaultAttribute(img *ast.Image, name, value string) {
+	if _, ok := img.AttributeString(name); !ok {
+		img.SetAttributeString(name, []byte(value))
+	}
+}
+
+// PictureRenderer picture 渲染器，内部的 <img> 由默认渲染器输出
+type PictureRenderer struct{}
+
+// RegisterFuncs 注册渲染函数
+func (r *PictureRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindPicture, r.renderPicture)
+}
+
+// renderPicture 渲染 picture 节点
+func (r *PictureRenderer) renderPicture(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</picture>")
+		return ast.WalkContinue, nil
+	}
+	n := node.(*PictureNode)
+	_, _ = w.WriteString(`<picture><source type="image/webp" srcset="`)
+	_, _ = w.Write(util.EscapeHTML([]byte(n.WebPSrcset)))
+	_, _ = w.WriteString(`" sizes="` + imageSizes + `" />`)
+	return ast.WalkContinue, nil
+}
+
+// ResponsiveImageExtension 响应式图片扩展
+type ResponsiveImageExtension struct{}
+
+// Extend 扩展 Goldmark
+func (e *ResponsiveImageExtension) Extend(m goldmark.Markdown) {
+	// 在嵌入扩展之后执行，music:/ 等嵌入图片已经被替换
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(&ResponsiveImageASTTransformer{}, 200)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&PictureRenderer{}, 100)))
+}
+
+// localImagePath 将 /img/、/attachments/ 开头的图片地址转换为磁盘路径
+func localImagePath(dest string) (string, bool) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return "", false
+	}
+	clean := path.Clean(u.Path)
+	for _, root := range localImageRoots {
+		if strings.HasPrefix(clean, "/"+root+"/") {
+			file := filepath.FromSlash(strings.TrimPrefix(clean, "/"))
+			return file, imagevariant.Supported(file) || isGIF(file)
+		}
+	}
+	return "", false
+}
+
+// isGIF GIF 不生成变体，但仍补充宽高
+func isGIF(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".gif")
+}
+
+// fileURL 磁盘路径对应的访问地址
+func fileURL(file string) string {
+	return (&url.URL{Path: "/" + filepath.ToSlash(file)}).EscapedPath()
+}
+
+// QueueImageVariants 在工作池中为上传的图片生成缩略图与 WebP 版本
+func QueueImageVariants(file string) {
+	if !imagevariant.Supported(file) {
+		return
+	}
+	pool := GetWorkerPool()
+	if pool == nil {
+		return
+	}
+	if err := pool.Submit(func() {
+		if _, err := imagevariant.Generate(file); err != nil {
+			log.Printf("Warning: 生成图片变体失败 %s: %v", file, err)
+		}
+	}); err != nil {
+		log.Printf("Warning: 提交图片变体任务失败 %s: %v", file, err)
+	}
+}
+
+// ImageBackfillStatus 补全图片变体任务的进度
+type ImageBackfillStatus struct {
+	Running   bool   `json:"running"`
+	Total     int    `json:"total"`
+	Done      int    `json:"done"`
+	Generated int    `json:"generated"` // 新生成的变体文件数
+	Failed    int    `json:"failed"`
+	Rendered  int    `json:"rendered"` // 重新渲染的文章数
+	LastError string `json:"last_error,omitempty"`
+}
+
+var (
+	imageBackfillMu     sync.Mutex
+	imageBackfillStatus ImageBackfillStatus
+)
+
+// ErrImageBackfillRunning 补全任务正在执行
+var ErrImageBackfillRunning = errors.New("图片变体补全任务正在执行")
+
+// GetImageBackfillStatus 获取补全任务的进度
+func GetImageBackfillStatus() ImageBackfillStatus {
+	imageBackfillMu.Lock()
+	defer imageBackfillMu.Unlock()
+	return imageBackfillStatus
+}
+
+// StartImageBackfill 为 img 与 attachments 目录中已有的图片补全变体
+// 所有图片处理完后重新渲染引用了本地图片的文章，使其带上 srcset
+func StartImageBackfill() (ImageBackfillStatus, error) {
+	pool := GetWorkerPool()
+	if pool == nil {
+		return ImageBackfillStatus{}, errors.New("工作池未初始化")
+	}
+
+	imageBackfillMu.Lock()
+	if imageBackfillStatus.Running {
+		status := imageBackfillStatus
+		imageBackfillMu.Unlock()
+		return status, ErrImageBackfillRunning
+	}
+	files := findLocalImages()
+	imageBackfillStatus = ImageBackfillStatus{Running: true, Total: len(files)}
+	status := imageBackfillStatus
+	imageBackfillMu.Unlock()
+
+	go runImageBackfill(pool, files)
+	return status, nil
+}
+
+// runImageBackfill 逐个提交补全任务
+// 同时只占用与 CPU 核数相同的队列位置，避免挤占工作池中的其他任务
+func runImageBackfill(pool WorkerPool, files []string) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, runtime.NumCPU())
+	for _, file := range files {
+		file := file
+		slots <- struct{}{}
+		wg.Add(1)
+		task := func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			n, err := imagevariant.Generate(file)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", file, err)
+			}
+			recordBackfill(n, err)
+		}
+		err := pool.Submit(task)
+		for errors.Is(err, ErrQueueFull) {
+			time.Sleep(100 * time.Millisecond)
+			err = pool.Submit(task)
+		}
+		if err != nil {
+			<-slots
+			wg.Done()
+			recordBackfill(0, fmt.Errorf("%s: %w", file, err))
+		}
+	}
+	wg.Wait()
+
+	rendered := rerenderPassagesWithImages()
+	imageBackfillMu.Lock()
+	imageBackfillStatus.Rendered = rendered
+	imageBackfillStatus.Running = false
+	final := imageBackfillStatus
+	imageBackfillMu.Unlock()
+	log.Printf("图片变体补全完成: %d 张图片，新生成 %d 个文件，失败 %d，重新渲染 %d 篇文章",
+		final.Total, final.Generated, final.Failed, final.Rendered)
+}
+
+// recordBackfill 记录一张图片的处理结果
+func recordBackfill(generated int, err error) {
+	imageBackfillMu.Lock()
+	defer imageBackfillMu.Unlock()
+	imageBackfillStatus.Done++
+	imageBackfillStatus.Generated += generated
+	if err != nil {
+		imageBackfillStatus.Failed++
+		imageBackfillStatus.LastError = err.Error()
+	}
+}
+
+// findLocalImages 列出本地图片目录中可以生成变体的原图
+func findLocalImages() []string {
+	var files []string
+	for _, root := range localImageRoots {
+		filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if d.Name() == imagevariant.Dir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if imagevariant.Supported(p) {
+				files = append(files, p)
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// rerenderPassagesWithImages 重新渲染引用了本地图片的文章，不改变更新时间
+func rerenderPassagesWithImages() int {
+	repo := db.GetPassageRepository()
+	if repo == nil {
+		return 0
+	}
+
+	const batch = 100
+	rendered := 0
+	for offset := 0; ; offset += batch {
+		passages, err := repo.GetAll(batch, offset)
+		if err != nil {
+			log.Printf("Warning: 获取文章列表失败: %v", err)
+			return rendered
+		}
+		for i := range passages {
+			p := &passages[i]
+			if !strings.Contains(p.OriginalContent, "/img/") && !strings.Contains(p.OriginalContent, "/attachments/") {
+				continue
+			}
+			if err := RenderPassage(p); err != nil {
+				log.Printf("Warning: 重新渲染文章 %d 失败: %v", p.ID, err)
+				continue
+			}
+			if err := repo.UpdateRendered(p); err != nil {
+				log.Printf("Warning: 更新文章 %d 失败: %v", p.ID, err)
+				continue
+			}
+			rendered++
+		}
+		if len(passages) < batch {
+			return rendered
+		}
+	}
+}
+
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxArchiveSize 上传压缩包解压后的总大小上限
+const maxArchiveSize = 1 << 30
+
+// ExtractArchive 将上传的站点目录压缩包（zip）或单个 WXR 文件放入 dir，返回可用于导入的选项
+// zip 内只有一个顶层目录时以该目录为站点根目录；WordPress 压缩包中的 uploads 目录作为上传目录
+func ExtractArchive(source Source, name string, r io.Reader, dir string) (Options, error) {
+	opts := Options{Source: source}
+	file := filepath.Join(dir, "upload"+strings.ToLower(filepath.Ext(name)))
+	if err := saveReader(file, r, maxArchiveSize); err != nil {
+		return opts, err
+	}
+
+	if !strings.EqualFold(filepath.Ext(name), ".zip") {
+		if source != SourceWordPress {
+			return opts, fmt.Errorf("%s 站点请上传 zip 压缩包", source)
+		}
+		opts.Path = file
+		return opts, nil
+	}
+
+	root := filepath.Join(dir, "site")
+	if err := unzip(file, root); err != nil {
+		return opts, err
+	}
+	if entries, err := os.ReadDir(root); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(root, entries[0].Name())
+	}
+	if source != SourceWordPress {
+		opts.Path = root
+		return opts, nil
+	}
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir() && d.Name() == "uploads" && opts.Uploads == "":
+			opts.Uploads = path
+			return filepath.SkipDir
+		case !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".xml") && opts.Path == "":
+			opts.Path = path
+		}
+		return nil
+	})
+	if err != nil {
+		return opts, err
+	}
+	if opts.Path == "" {
+		return opts, fmt.Errorf("压缩包中没有 WXR 文件")
+	}
+	return opts, nil
+}
+
+// unzip 解压 zip 文件到 dest，拒绝指向 dest 之外的条目
+func unzip(file, dest string) error {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return fmt.Errorf("打开压缩包失败: %w", err)
+	}
+	defer zr.Close()
+
+	var remaining int64 = maxArchiveSize
+	for _, f := range zr.File {
+		target := filepath.Join(dest, filepath.FromSlash(f.Name))
+		if !within(dest, target) {
+			return fmt.Errorf("压缩包中的路径不合法: %s", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = saveReader(target, rc, remaining)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(target); err == nil {
+			remaining -= info.Size()
+		}
+	}
+	return nil
+}
+
+// saveReader 将 r 写入 file，超过 limit 字节时返回错误
+func saveReader(file string, r io.Reader, limit int64) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	n, err := io.Copy(out, io.LimitReader(r, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("文件超过大小上限")
+	}
+	return nil
+}
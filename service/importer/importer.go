@@ -0,0 +1,344 @@
+// Package importer 从 Hugo、Hexo、Jekyll 的站点目录或 WordPress 导出的 WXR 文件导入文章
+// 文章写入 markdown/YYYY/MM/DD 后按普通 markdown 文件同步入库，
+// 正文引用的本地图片复制为附件并改写地址，WordPress 的评论导入 comments 表
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/frontmatter"
+	"myblog-gogogo/pkg/slug"
+	"myblog-gogogo/service"
+	"myblog-gogogo/service/attachment"
+)
+
+// Source 导入来源
+type Source string
+
+const (
+	SourceHugo      Source = "hugo"
+	SourceHexo      Source = "hexo"
+	SourceJekyll    Source = "jekyll"
+	SourceWordPress Source = "wordpress"
+)
+
+// ParseSource 解析来源名称
+func ParseSource(name string) (Source, error) {
+	switch s := Source(strings.ToLower(strings.TrimSpace(name))); s {
+	case SourceHugo, SourceHexo, SourceJekyll, SourceWordPress:
+		return s, nil
+	}
+	return "", fmt.Errorf("不支持的导入来源 %q，可选 hugo、hexo、jekyll、wordpress", name)
+}
+
+// Options 导入选项
+type Options struct {
+	Source Source
+	// Path Hugo、Hexo、Jekyll 为站点根目录，WordPress 为 WXR 文件
+	Path string
+	// Uploads WordPress 的 wp-content/uploads 目录副本，为空时文章中的图片保持原地址
+	Uploads string
+	// DryRun 只生成报告，不写入文件与数据库
+	DryRun bool
+	// Author 记录到修订历史中的操作者
+	Author string
+}
+
+// Post 从来源中读取的一篇文章
+type Post struct {
+	Origin     string // 来源文件或 WordPress 文章链接，用于报告
+	Title      string
+	Slug       string
+	Summary    string
+	Author     string
+	Category   string
+	Tags       []string
+	Date       time.Time
+	Status     string // published、draft、pending（定时发布），为空时按已发布处理
+	Visibility string
+	Scheduled  bool   // 定时发布，Date 为发布时间
+	Body       string // markdown 正文，WordPress 为 HTML
+	Comments   []models.Comment
+	Warnings   []string // 无法映射的元数据、短代码等
+
+	// resolve 将正文中的图片地址解析为本地文件，返回空路径表示外部图片
+	resolve func(ref string) (string, error)
+}
+
+// warn 记录一条警告，相同的警告只记录一次
+func (p *Post) warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	for _, w := range p.Warnings {
+		if w == msg {
+			return
+		}
+	}
+	p.Warnings = append(p.Warnings, msg)
+}
+
+// 报告中单篇文章的处理结果
+const (
+	ItemImported = "imported" // 已导入
+	ItemPlanned  = "planned"  // 预演模式下将被导入
+	ItemConflict = "conflict" // 与已有文章冲突，已跳过
+	ItemFailed   = "failed"   // 导入失败
+)
+
+// Item 报告中的一篇文章
+type Item struct {
+	Origin    string   `json:"origin"`
+	Title     string   `json:"title"`
+	File      string   `json:"file"`
+	Status    string   `json:"status"`
+	Message   string   `json:"message,omitempty"`
+	PassageID int      `json:"passage_id,omitempty"`
+	Images    int      `json:"images"`
+	Comments  int      `json:"comments"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// Report 导入报告
+type Report struct {
+	Source    Source `json:"source"`
+	DryRun    bool   `json:"dry_run"`
+	Items     []Item `json:"items"`
+	Imported  int    `json:"imported"`
+	Conflicts int    `json:"conflicts"`
+	Failed    int    `json:"failed"`
+	Images    int    `json:"images"`
+	Comments  int    `json:"comments"`
+	// Unsupported 整体跳过的内容，例如 Hugo 的章节页面、WordPress 的页面与菜单
+	Unsupported []string `json:"unsupported,omitempty"`
+}
+
+// Run 读取来源中的全部文章并逐篇导入，单篇失败不影响其他文章
+func Run(opts Options) (*Report, error) {
+	var posts []*Post
+	var unsupported []string
+	var err error
+	switch opts.Source {
+	case SourceHugo, SourceHexo, SourceJekyll:
+		posts, unsupported, err = readSite(opts.Source, opts.Path)
+	case SourceWordPress:
+		posts, unsupported, err = readWXR(opts.Path, opts.Uploads)
+	default:
+		_, err = ParseSource(string(opts.Source))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Source: opts.Source, DryRun: opts.DryRun, Items: []Item{}, Unsupported: unsupported}
+	planned := make(map[string]bool)
+	for _, post := range posts {
+		item := importPost(post, opts, planned)
+		switch item.Status {
+		case ItemImported, ItemPlanned:
+			report.Imported++
+			report.Images += item.Images
+			report.Comments += item.Comments
+		case ItemConflict:
+			report.Conflicts++
+		case ItemFailed:
+			report.Failed++
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report, nil
+}
+
+// importPost 导入一篇文章，planned 记录本次已占用的文件路径
+func importPost(post *Post, opts Options, planned map[string]bool) Item {
+	if post.Date.IsZero() {
+		post.Date = time.Now()
+		post.warn("没有日期，使用当前时间")
+	}
+	file, _ := service.GetMarkdownFilePath(post.Title, post.Date)
+	relative := strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(file), "markdown/"), ".md")
+	item := Item{Origin: post.Origin, Title: post.Title, File: filepath.ToSlash(file), Comments: len(post.Comments)}
+
+	repo := db.GetPassageRepository()
+	existing, err := repo.GetByFilePath(relative)
+	if err != nil {
+		return failed(item, post, fmt.Errorf("查询已有文章失败: %w", err))
+	}
+	if _, statErr := os.Stat(file); planned[relative] || existing != nil || statErr == nil {
+		item.Status = ItemConflict
+		item.Message = "同一日期已存在同名文章"
+		item.Warnings = post.Warnings
+		return item
+	}
+	planned[relative] = true
+
+	if post.Slug != "" {
+		if taken, err := repo.GetBySlug(slug.Normalize(post.Slug)); err == nil && taken != nil {
+			post.warn("别名 %s 已被文章「%s」使用，导入后会自动追加序号", post.Slug, taken.Title)
+		}
+	}
+
+	// 解析正文引用的本地图片
+	images := make(map[string]string)
+	forEachImage(post.Body, func(ref string) string {
+		if _, seen := images[ref]; seen || post.resolve == nil {
+			return ref
+		}
+		local, err := post.resolve(ref)
+		if err != nil {
+			post.warn("%v", err)
+		}
+		if local != "" && err == nil {
+			images[ref] = local
+		}
+		return ref
+	})
+	item.Images = len(images)
+
+	if opts.DryRun {
+		item.Status = ItemPlanned
+		item.Warnings = post.Warnings
+		return item
+	}
+
+	// 复制图片为附件，存放在文章日期对应的目录
+	attachments := attachment.NewService()
+	urls := make(map[string]string, len(images))
+	var attachmentIDs []int
+	for ref, local := range images {
+		content, err := os.ReadFile(local)
+		if err != nil {
+			post.warn("读取图片失败 %s: %v", ref, err)
+			continue
+		}
+		result, err := attachments.Save(filepath.Base(local), content, 0, post.Date)
+		if err != nil {
+			post.warn("保存图片失败 %s: %v", ref, err)
+			continue
+		}
+		urls[ref] = result.URL
+		attachmentIDs = append(attachmentIDs, result.ID)
+	}
+	item.Images = len(urls)
+	body := forEachImage(post.Body, func(ref string) string {
+		if u, ok := urls[ref]; ok {
+			return u
+		}
+		return ref
+	})
+
+	fm := &frontmatter.FrontMatter{
+		Format:     frontmatter.FormatYAML,
+		Title:      post.Title,
+		Slug:       post.Slug,
+		Summary:    post.Summary,
+		Author:     post.Author,
+		Category:   post.Category,
+		Tags:       post.Tags,
+		Status:     post.Status,
+		Visibility: post.Visibility,
+		Date:       &post.Date,
+	}
+	if post.Scheduled {
+		scheduled := true
+		fm.IsScheduled = &scheduled
+		fm.PublishedAt = &post.Date
+	}
+	if err := service.UpdateMarkdownFileWithFrontMatter(file, fm, post.Title, body); err != nil {
+		return failed(item, post, err)
+	}
+
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return failed(item, post, err)
+	}
+	syncService := service.NewSyncService(repo)
+	if err := syncService.SyncFileFrom(absPath, "", models.RevisionSourceImport, opts.Author); err != nil {
+		os.Remove(file)
+		return failed(item, post, err)
+	}
+	passage, err := repo.GetByFilePath(relative)
+	if err != nil || passage == nil {
+		return failed(item, post, fmt.Errorf("同步后未找到文章 %s", relative))
+	}
+	item.PassageID = passage.ID
+
+	for _, id := range attachmentIDs {
+		if err := attachments.SetPassage(id, passage.ID); err != nil {
+			post.warn("关联附件 %d 失败: %v", id, err)
+		}
+	}
+
+	commentRepo := db.GetCommentRepository()
+	item.Comments = 0
+	for _, comment := range post.Comments {
+		comment.PassageID = passage.ID
+		if err := commentRepo.Create(&comment); err != nil {
+			post.warn("导入评论失败: %v", err)
+			continue
+		}
+		item.Comments++
+	}
+
+	item.Status = ItemImported
+	item.Warnings = post.Warnings
+	return item
+}
+
+// failed 生成失败的报告项
+func failed(item Item, post *Post, err error) Item {
+	item.Status = ItemFailed
+	item.Message = err.Error()
+	item.Warnings = post.Warnings
+	return item
+}
+
+var (
+	// markdownImageRegex markdown 图片 ![alt](地址 "标题") 与 ![alt](<带空格的地址>)
+	markdownImageRegex = regexp.MustCompile(`!\[[^\]]*\]\(\s*(?:<([^>]+)>|([^)\s]+))`)
+	// htmlImageRegex HTML 图片 <img src="地址">
+	htmlImageRegex = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']([^"']+)["']`)
+)
+
+// forEachImage 对正文中的每个图片地址调用 fn，并用返回值替换原地址
+func forEachImage(body string, fn func(ref string) string) string {
+	for _, re := range []*regexp.Regexp{markdownImageRegex, htmlImageRegex} {
+		var b strings.Builder
+		last := 0
+		for _, m := range re.FindAllStringSubmatchIndex(body, -1) {
+			// 取实际匹配到的地址分组
+			start, end := m[2], m[3]
+			if start < 0 {
+				start, end = m[4], m[5]
+			}
+			b.WriteString(body[last:start])
+			b.WriteString(fn(body[start:end]))
+			last = end
+		}
+		b.WriteString(body[last:])
+		body = b.String()
+	}
+	return body
+}
+
+// within 判断 file 是否位于 root 目录内，防止正文中的 ../ 读取站点以外的文件
+func within(root, file string) bool {
+	rel, err := filepath.Rel(root, file)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// countNames 将名称计数整理为 "名称 ×n" 的有序列表
+func countNames(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name, n := range counts {
+		names = append(names, fmt.Sprintf("%s ×%d", name, n))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,252 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/service"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasWarning(p *Post, substr string) bool {
+	for _, w := range p.Warnings {
+		if strings.Contains(w, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReadSiteJekyll(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "_posts", "2020-01-02-hello-world.md"),
+		"---\nlayout: post\ntags: [a]\n---\n# Hello\n\n![x]({{ site.baseurl }}/assets/x.png)\n\n{% highlight go %}\nfmt.Println()\n{% endhighlight %}\n\n{% post_url other %}\n")
+	writeFile(t, filepath.Join(root, "_drafts", "idea.md"), "---\ntitle: Idea\n---\nbody\n")
+	writeFile(t, filepath.Join(root, "assets", "x.png"), "png")
+
+	posts, _, err := readSite(SourceJekyll, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want 2", len(posts))
+	}
+
+	post := posts[0]
+	if post.Title != "Hello" || post.Slug != "hello-world" {
+		t.Errorf("title/slug = %q/%q", post.Title, post.Slug)
+	}
+	if want := time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local); !post.Date.Equal(want) {
+		t.Errorf("date = %v, want %v", post.Date, want)
+	}
+	if !strings.Contains(post.Body, "![x](/assets/x.png)") || !strings.Contains(post.Body, "```go\nfmt.Println()\n```") {
+		t.Errorf("body not converted:\n%s", post.Body)
+	}
+	if !hasWarning(post, "post_url") || !hasWarning(post, "layout") {
+		t.Errorf("warnings = %v", post.Warnings)
+	}
+	if file, err := post.resolve("/assets/x.png"); err != nil || file != filepath.Join(root, "assets", "x.png") {
+		t.Errorf("resolve = %q, %v", file, err)
+	}
+	if _, err := post.resolve("../../etc/passwd"); err == nil {
+		t.Error("expected error for image outside the site")
+	}
+	if file, err := post.resolve("https://example.com/a.png"); err != nil || file != "" {
+		t.Errorf("external image resolved to %q, %v", file, err)
+	}
+
+	if posts[1].Status != "draft" {
+		t.Errorf("draft status = %q", posts[1].Status)
+	}
+}
+
+func TestReadSiteHexoAssetFolder(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "source", "_posts", "trip.md"),
+		"---\ntitle: Trip\ndate: 2021-06-07 08:09:10\ncategories: Travel\n---\n{% asset_img beach.jpg 海边 %}\n")
+	writeFile(t, filepath.Join(root, "source", "_posts", "trip", "beach.jpg"), "jpg")
+
+	posts, _, err := readSite(SourceHexo, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+	post := posts[0]
+	if post.Category != "Travel" || !strings.Contains(post.Body, "![海边](beach.jpg)") {
+		t.Errorf("category = %q, body = %q", post.Category, post.Body)
+	}
+	if file, err := post.resolve("beach.jpg"); err != nil || file != filepath.Join(root, "source", "_posts", "trip", "beach.jpg") {
+		t.Errorf("resolve = %q, %v", file, err)
+	}
+}
+
+const testWXR = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+<item>
+	<title>Hello</title>
+	<link>https://wp.example/hello</link>
+	<dc:creator>admin</dc:creator>
+	<content:encoded><![CDATA[[caption id="1"]<img src="https://wp.example/wp-content/uploads/2020/05/a.png" /> A[/caption]]]></content:encoded>
+	<excerpt:encoded><![CDATA[Summary]]></excerpt:encoded>
+	<wp:post_date>2020-05-01 08:00:00</wp:post_date>
+	<wp:post_name>hello-%e4%b8%96%e7%95%8c</wp:post_name>
+	<wp:status>future</wp:status>
+	<wp:post_type>post</wp:post_type>
+	<wp:post_password>secret</wp:post_password>
+	<category domain="category" nicename="news"><![CDATA[News]]></category>
+	<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+	<wp:comment>
+		<wp:comment_author>Bob</wp:comment_author>
+		<wp:comment_date>2020-05-02 09:00:00</wp:comment_date>
+		<wp:comment_content>Nice</wp:comment_content>
+		<wp:comment_approved>1</wp:comment_approved>
+		<wp:comment_type></wp:comment_type>
+		<wp:comment_parent>0</wp:comment_parent>
+	</wp:comment>
+	<wp:comment>
+		<wp:comment_author>Blog</wp:comment_author>
+		<wp:comment_content>ping</wp:comment_content>
+		<wp:comment_approved>1</wp:comment_approved>
+		<wp:comment_type>pingback</wp:comment_type>
+	</wp:comment>
+</item>
+<item><title>About</title><wp:post_type>page</wp:post_type><wp:status>publish</wp:status></item>
+</channel>
+</rss>`
+
+func TestReadWXR(t *testing.T) {
+	dir := t.TempDir()
+	uploads := filepath.Join(dir, "uploads")
+	writeFile(t, filepath.Join(dir, "export.xml"), testWXR)
+	writeFile(t, filepath.Join(uploads, "2020", "05", "a.png"), "png")
+
+	posts, unsupported, err := readWXR(filepath.Join(dir, "export.xml"), uploads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || len(unsupported) != 1 || unsupported[0] != "WordPress page ×1" {
+		t.Fatalf("posts = %d, unsupported = %v", len(posts), unsupported)
+	}
+
+	post := posts[0]
+	if post.Slug != "hello-世界" || post.Summary != "Summary" || post.Author != "admin" {
+		t.Errorf("slug/summary/author = %q/%q/%q", post.Slug, post.Summary, post.Author)
+	}
+	if !post.Scheduled || post.Visibility != "private" || post.Category != "News" || len(post.Tags) != 1 {
+		t.Errorf("scheduled/visibility/category/tags = %v/%q/%q/%v", post.Scheduled, post.Visibility, post.Category, post.Tags)
+	}
+	if strings.Contains(post.Body, "[caption") || !strings.Contains(post.Body, "<img") {
+		t.Errorf("body = %q", post.Body)
+	}
+	if len(post.Comments) != 1 || post.Comments[0].Username != "Bob" || post.Comments[0].CreatedAt.Day() != 2 {
+		t.Errorf("comments = %+v", post.Comments)
+	}
+	if !hasWarning(post, "pingback") {
+		t.Errorf("warnings = %v", post.Warnings)
+	}
+	file, err := post.resolve("https://wp.example/wp-content/uploads/2020/05/a.png")
+	if err != nil || file != filepath.Join(uploads, "2020", "05", "a.png") {
+		t.Errorf("resolve = %q, %v", file, err)
+	}
+}
+
+// TestImportScheduledWordPressPost 测试 WordPress 定时文章导入后等待调度器发布
+func TestImportScheduledWordPressPost(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := db.InitDB("sqlite3", filepath.Join(dir, "blog.db"), 1, 1, 5, 5); err != nil {
+		t.Skipf("sqlite unavailable: %v", err)
+	}
+	defer db.CloseDB()
+
+	publishAt := time.Now().Add(24 * time.Hour)
+	post := wxrPost(wxrItem{
+		Title:    "Future",
+		PostName: "future",
+		PostDate: publishAt.Format(wxrDateLayout),
+		Status:   "future",
+		Encoded:  []wxrEncoded{{XMLName: xml.Name{Space: "http://purl.org/rss/1.0/modules/content/", Local: "encoded"}, Value: "<p>soon</p>"}},
+	}, "")
+	item := importPost(post, Options{Source: SourceWordPress, Author: "admin"}, map[string]bool{})
+	if item.Status != ItemImported {
+		t.Fatalf("item = %+v", item)
+	}
+
+	repo := db.GetPassageRepository()
+	passage, err := repo.GetByID(item.PassageID)
+	if err != nil || passage == nil {
+		t.Fatalf("GetByID() = %v, %v", passage, err)
+	}
+	if passage.Status != "pending" || !passage.IsScheduled || passage.PublishedAt.Unix() != publishAt.Unix() {
+		t.Errorf("status/scheduled/published_at = %q/%v/%v", passage.Status, passage.IsScheduled, passage.PublishedAt)
+	}
+
+	pending, err := repo.GetPendingScheduled()
+	if err != nil || len(pending) != 1 || pending[0].ID != passage.ID {
+		t.Fatalf("GetPendingScheduled() = %d passages, %v", len(pending), err)
+	}
+	if public, _ := repo.GetPublic("", 0, 10, 0); len(public) != 0 {
+		t.Errorf("scheduled passage listed before publish time")
+	}
+
+	scheduler := service.NewPublishScheduler(repo, time.Minute)
+	if published, err := scheduler.PublishDue(publishAt.Add(time.Minute)); err != nil || len(published) != 1 {
+		t.Fatalf("PublishDue() = %d passages, %v", len(published), err)
+	}
+	if public, _ := repo.GetPublic("", 0, 10, 0); len(public) != 1 {
+		t.Errorf("published passage missing from public list")
+	}
+}
+
+func TestExtractArchiveRejectsZipSlip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("../evil.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("x"))
+	zw.Close()
+
+	dir := t.TempDir()
+	if _, err := ExtractArchive(SourceHugo, "site.zip", &buf, dir); err == nil {
+		t.Fatal("expected error for entry outside the archive root")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "evil.md")); err == nil {
+		t.Error("zip entry escaped the target directory")
+	}
+}
+
+func TestForEachImage(t *testing.T) {
+	body := `![a](a.png "t") <img class="x" src='b.png'> ![c](<c d.png>)`
+	var refs []string
+	got := forEachImage(body, func(ref string) string {
+		refs = append(refs, ref)
+		return "/x/" + ref
+	})
+	if strings.Join(refs, ",") != "a.png,c d.png,b.png" {
+		t.Errorf("refs = %v", refs)
+	}
+	if got != `![a](/x/a.png "t") <img class="x" src='/x/b.png'> ![c](</x/c d.png>)` {
+		t.Errorf("got %s", got)
+	}
+}
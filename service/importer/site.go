@@ -0,0 +1,284 @@
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"myblog-gogogo/pkg/frontmatter"
+)
+
+// siteLayout 静态博客生成器的目录结构
+type siteLayout struct {
+	posts  string // 文章目录
+	drafts string // 草稿目录，为空表示没有单独的草稿目录
+	static string // 以 / 开头的图片地址对应的目录
+}
+
+// siteLayouts 各生成器的默认目录结构，相对站点根目录
+var siteLayouts = map[Source]siteLayout{
+	SourceHugo:   {posts: "content", static: "static"},
+	SourceHexo:   {posts: "source/_posts", drafts: "source/_drafts", static: "source"},
+	SourceJekyll: {posts: "_posts", drafts: "_drafts", static: "."},
+}
+
+// jekyllNameRegex Jekyll 文章文件名 YYYY-MM-DD-name
+var jekyllNameRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+var (
+	// hugoShortcodeRegex Hugo 短代码 {{< name 参数 >}} 与 {{% name 参数 %}}
+	hugoShortcodeRegex = regexp.MustCompile(`\{\{[<%]\s*/?\s*([\w-]+)([^}]*?)\s*[>%]\}\}`)
+	// liquidTagRegex Hexo 标签插件与 Jekyll Liquid 标签 {% name 参数 %}
+	liquidTagRegex = regexp.MustCompile(`\{%-?\s*(\w+)([^%]*?)\s*-?%\}`)
+	// liquidOutputRegex Jekyll Liquid 输出 {{ site.baseurl }}、{{ "/a.png" | relative_url }}
+	liquidOutputRegex = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+	// shortcodeArgRegex 短代码参数 key="value"
+	shortcodeArgRegex = regexp.MustCompile(`(\w+)\s*=\s*(?:"([^"]*)"|'([^']*)'|(\S+))`)
+)
+
+// readSite 读取 Hugo、Hexo 或 Jekyll 站点中的全部文章
+func readSite(source Source, root string) ([]*Post, []string, error) {
+	layout := siteLayouts[source]
+	postsDir := filepath.Join(root, layout.posts)
+	if info, err := os.Stat(postsDir); err != nil || !info.IsDir() {
+		return nil, nil, fmt.Errorf("未找到文章目录 %s", postsDir)
+	}
+
+	var posts []*Post
+	skipped := make(map[string]int)
+	dirs := []string{postsDir}
+	if layout.drafts != "" {
+		dirs = append(dirs, filepath.Join(root, layout.drafts))
+	}
+	for i, dir := range dirs {
+		draft := i == 1
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == dir {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if d.IsDir() || !isMarkdown(path) {
+				return nil
+			}
+			if source == SourceHugo && strings.HasPrefix(d.Name(), "_index.") {
+				skipped["Hugo 章节页面"]++
+				return nil
+			}
+			post, err := readSitePost(source, layout, root, path, draft)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			posts = append(posts, post)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return posts, countNames(skipped), nil
+}
+
+// isMarkdown 判断是否为 markdown 文件
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// readSitePost 读取一篇文章并映射头部元数据
+func readSitePost(source Source, layout siteLayout, root, path string, draft bool) (*Post, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fm, body, err := frontmatter.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if fm == nil {
+		fm = &frontmatter.FrontMatter{}
+	}
+
+	rel, _ := filepath.Rel(root, path)
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	postDir := filepath.Dir(path)
+	// Hugo 页面包 post/index.md 以目录名作为别名
+	if source == SourceHugo && name == "index" {
+		name = filepath.Base(postDir)
+	}
+
+	post := &Post{
+		Origin:     filepath.ToSlash(rel),
+		Title:      fm.Title,
+		Slug:       fm.Slug,
+		Summary:    fm.Summary,
+		Author:     fm.Author,
+		Category:   fm.Category,
+		Tags:       fm.Tags,
+		Status:     fm.Status,
+		Visibility: fm.Visibility,
+	}
+	if fm.Date != nil {
+		post.Date = *fm.Date
+	}
+	if draft {
+		post.Status = "draft"
+	}
+
+	if source == SourceJekyll {
+		if m := jekyllNameRegex.FindStringSubmatch(name); m != nil {
+			name = m[2]
+			if post.Date.IsZero() {
+				post.Date, _ = time.ParseInLocation("2006-01-02", m[1], time.Local)
+			}
+		}
+	}
+	if post.Slug == "" {
+		post.Slug = name
+	}
+	if post.Title == "" {
+		post.Title = titleFromBody(string(body), name)
+	}
+	if post.Date.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			post.Date = info.ModTime()
+			post.warn("头部没有日期，使用文件修改时间")
+		}
+	}
+	if len(fm.Unknown) > 0 {
+		post.warn("未导入的头部字段: %s", strings.Join(fm.Unknown, ", "))
+	}
+
+	staticDir := filepath.Join(root, layout.static)
+	// Hexo 的文章资源目录与文章同名
+	assetDirs := []string{postDir}
+	if source == SourceHexo {
+		assetDirs = []string{filepath.Join(postDir, name), postDir}
+	}
+
+	switch source {
+	case SourceHugo:
+		post.Body = convertHugo(post, string(body))
+	case SourceHexo, SourceJekyll:
+		post.Body = convertLiquid(post, string(body))
+	}
+	post.resolve = func(ref string) (string, error) {
+		return resolveSiteImage(ref, root, staticDir, assetDirs)
+	}
+	return post, nil
+}
+
+// titleFromBody 取正文第一个一级标题，没有时使用文件名
+func titleFromBody(body, fallback string) string {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		}
+	}
+	return fallback
+}
+
+// resolveSiteImage 将图片地址解析为站点目录中的文件，外部图片返回空路径
+func resolveSiteImage(ref, root, staticDir string, assetDirs []string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", nil
+	}
+	p, err := url.PathUnescape(u.Path)
+	if err != nil {
+		p = u.Path
+	}
+
+	var candidates []string
+	if strings.HasPrefix(p, "/") {
+		candidates = append(candidates, filepath.Join(staticDir, filepath.FromSlash(p)))
+	} else {
+		for _, dir := range assetDirs {
+			candidates = append(candidates, filepath.Join(dir, filepath.FromSlash(p)))
+		}
+	}
+	for _, file := range candidates {
+		if !within(root, file) {
+			return "", fmt.Errorf("图片位于站点目录之外: %s", ref)
+		}
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("图片不存在: %s", ref)
+}
+
+// shortcodeArgs 解析短代码参数
+func shortcodeArgs(args string) map[string]string {
+	values := make(map[string]string)
+	for _, m := range shortcodeArgRegex.FindAllStringSubmatch(args, -1) {
+		values[strings.ToLower(m[1])] = m[2] + m[3] + m[4]
+	}
+	return values
+}
+
+// convertHugo 将 figure 短代码转换为 markdown 图片，其余短代码保留并记录警告
+func convertHugo(post *Post, body string) string {
+	return hugoShortcodeRegex.ReplaceAllStringFunc(body, func(m string) string {
+		parts := hugoShortcodeRegex.FindStringSubmatch(m)
+		if parts[1] == "figure" {
+			args := shortcodeArgs(parts[2])
+			alt := args["alt"]
+			if alt == "" {
+				alt = args["title"]
+			}
+			return fmt.Sprintf("![%s](%s)", alt, args["src"])
+		}
+		post.warn("不支持的 Hugo 短代码: %s", parts[1])
+		return m
+	})
+}
+
+// convertLiquid 转换 Hexo 标签插件与 Jekyll Liquid 语法
+// asset_img 转为 markdown 图片，highlight 转为代码块，site.baseurl 等地址变量去掉，其余保留并记录警告
+func convertLiquid(post *Post, body string) string {
+	body = liquidTagRegex.ReplaceAllStringFunc(body, func(m string) string {
+		parts := liquidTagRegex.FindStringSubmatch(m)
+		args := strings.Fields(parts[2])
+		switch parts[1] {
+		case "asset_img":
+			if len(args) == 0 {
+				break
+			}
+			return fmt.Sprintf("![%s](%s)", strings.Trim(strings.Join(args[1:], " "), `"'`), args[0])
+		case "highlight", "codeblock":
+			lang := ""
+			if len(args) > 0 && parts[1] == "highlight" {
+				lang = args[0]
+			}
+			return "```" + lang
+		case "endhighlight", "endcodeblock":
+			return "```"
+		case "raw", "endraw":
+			return ""
+		}
+		post.warn("不支持的标签: {%% %s %%}", parts[1])
+		return m
+	})
+	return liquidOutputRegex.ReplaceAllStringFunc(body, func(m string) string {
+		expr := liquidOutputRegex.FindStringSubmatch(m)[1]
+		switch {
+		case expr == "site.baseurl" || expr == "site.url":
+			return ""
+		case strings.Contains(expr, "relative_url") || strings.Contains(expr, "absolute_url"):
+			value, _, _ := strings.Cut(expr, "|")
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+		post.warn("不支持的 Liquid 变量: {{ %s }}", expr)
+		return m
+	})
+}
@@ -0,0 +1,252 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"myblog-gogogo/db/models"
+)
+
+// wxrItem WXR 导出文件中的一个条目，字段按本地名称匹配，兼容不同版本的命名空间
+type wxrItem struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	Creator    string        `xml:"creator"`
+	Encoded    []wxrEncoded  `xml:"encoded"`
+	PostID     string        `xml:"post_id"`
+	PostDate   string        `xml:"post_date"`
+	PostName   string        `xml:"post_name"`
+	Status     string        `xml:"status"`
+	PostType   string        `xml:"post_type"`
+	Password   string        `xml:"post_password"`
+	Categories []wxrCategory `xml:"category"`
+	Comments   []wxrComment  `xml:"comment"`
+}
+
+// wxrEncoded content:encoded 为正文，excerpt:encoded 为摘要
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// wxrCategory 分类或标签，domain 区分 category 与 post_tag
+type wxrCategory struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+// wxrComment 文章下的评论
+type wxrComment struct {
+	Author   string `xml:"comment_author"`
+	Date     string `xml:"comment_date"`
+	Content  string `xml:"comment_content"`
+	Approved string `xml:"comment_approved"`
+	Type     string `xml:"comment_type"`
+	Parent   string `xml:"comment_parent"`
+}
+
+// wxrDocument WXR 文件的根节点
+type wxrDocument struct {
+	Items []wxrItem `xml:"channel>item"`
+}
+
+// wxrDateLayout post_date 与 comment_date 的格式，使用站点本地时间
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+var (
+	// wpShortcodeRegex WordPress 短代码 [name 参数] 与 [/name]
+	wpShortcodeRegex = regexp.MustCompile(`\[(/?)(\w+)(?:\s[^\]]*)?\]`)
+	// wpUploadsPath 媒体库地址中的上传目录
+	wpUploadsPath = "/wp-content/uploads/"
+)
+
+// readWXR 读取 WordPress 导出的 WXR 文件，uploads 为 wp-content/uploads 目录副本
+func readWXR(path, uploads string) ([]*Post, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var doc wxrDocument
+	if err := xml.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("解析 WXR 文件失败: %w", err)
+	}
+
+	var posts []*Post
+	skipped := make(map[string]int)
+	for _, item := range doc.Items {
+		if item.PostType != "post" {
+			// 附件随文章中的引用导入，不单独作为文章
+			if item.PostType != "attachment" {
+				skipped[fmt.Sprintf("WordPress %s", item.PostType)]++
+			}
+			continue
+		}
+		if item.Status == "trash" || item.Status == "auto-draft" {
+			skipped["WordPress 回收站与自动草稿"]++
+			continue
+		}
+		posts = append(posts, wxrPost(item, uploads))
+	}
+	return posts, countNames(skipped), nil
+}
+
+// wxrPost 将一个文章条目映射为 Post
+func wxrPost(item wxrItem, uploads string) *Post {
+	post := &Post{
+		Origin: item.Link,
+		Title:  strings.TrimSpace(item.Title),
+		Author: item.Creator,
+	}
+	if post.Origin == "" {
+		post.Origin = "post_id " + item.PostID
+	}
+	if name, err := url.PathUnescape(item.PostName); err == nil {
+		post.Slug = name
+	} else {
+		post.Slug = item.PostName
+	}
+	if post.Title == "" {
+		post.Title = post.Slug
+	}
+	if date, err := time.ParseInLocation(wxrDateLayout, item.PostDate, time.Local); err == nil && date.Year() > 1 {
+		post.Date = date
+	}
+
+	for _, enc := range item.Encoded {
+		switch enc.XMLName.Local {
+		case "encoded":
+			if strings.Contains(enc.XMLName.Space, "excerpt") {
+				post.Summary = strings.TrimSpace(enc.Value)
+			} else {
+				post.Body = enc.Value
+			}
+		}
+	}
+
+	switch item.Status {
+	case "publish":
+		post.Status = "published"
+	case "private":
+		post.Status = "published"
+		post.Visibility = "private"
+	case "future":
+		// 等待调度器在发布时间发布，已过发布时间的文章会在下一次调度时发布
+		post.Status = "pending"
+		post.Scheduled = true
+	default:
+		post.Status = "draft"
+	}
+	if item.Password != "" {
+		post.Visibility = "private"
+		post.warn("文章设有访问密码，已导入为私密文章")
+	}
+
+	var categories []string
+	for _, c := range item.Categories {
+		name := strings.TrimSpace(c.Name)
+		switch c.Domain {
+		case "category":
+			categories = append(categories, name)
+		case "post_tag":
+			post.Tags = append(post.Tags, name)
+		}
+	}
+	if len(categories) > 0 {
+		post.Category = categories[0]
+		if len(categories) > 1 {
+			post.warn("只保留第一个分类，未导入: %s", strings.Join(categories[1:], ", "))
+		}
+	}
+
+	post.Body = convertWordPress(post, post.Body)
+	post.Comments = wxrComments(post, item.Comments)
+
+	post.resolve = func(ref string) (string, error) {
+		return resolveUpload(ref, uploads)
+	}
+	if uploads == "" && strings.Contains(post.Body, wpUploadsPath) {
+		post.warn("未指定上传目录，媒体库图片保持原地址")
+	}
+	return post
+}
+
+// convertWordPress 去掉 caption、embed 短代码的标记保留内容，其余短代码保留并记录警告
+func convertWordPress(post *Post, body string) string {
+	return wpShortcodeRegex.ReplaceAllStringFunc(body, func(m string) string {
+		name := wpShortcodeRegex.FindStringSubmatch(m)[2]
+		switch name {
+		case "caption", "wp_caption", "embed":
+			return ""
+		case "gallery", "video", "audio", "playlist", "contact-form", "contact-form-7":
+			post.warn("不支持的短代码: [%s]", name)
+		}
+		return m
+	})
+}
+
+// wxrComments 导入已审核的普通评论，pingback 与待审核评论跳过
+func wxrComments(post *Post, comments []wxrComment) []models.Comment {
+	var result []models.Comment
+	skipped, replies := 0, 0
+	for _, c := range comments {
+		if c.Approved != "1" || (c.Type != "" && c.Type != "comment") {
+			skipped++
+			continue
+		}
+		date, err := time.ParseInLocation(wxrDateLayout, c.Date, time.Local)
+		if err != nil {
+			date = post.Date
+		}
+		if c.Parent != "" && c.Parent != "0" {
+			replies++
+		}
+		result = append(result, models.Comment{
+			Username:  strings.TrimSpace(c.Author),
+			Content:   strings.TrimSpace(c.Content),
+			CreatedAt: date,
+		})
+	}
+	if skipped > 0 {
+		post.warn("跳过 %d 条未审核评论或 pingback", skipped)
+	}
+	if replies > 0 {
+		post.warn("%d 条回复评论的层级关系未保留", replies)
+	}
+	return result
+}
+
+// resolveUpload 将媒体库地址映射到 uploads 目录中的文件，其他地址视为外部图片
+func resolveUpload(ref, uploads string) (string, error) {
+	if uploads == "" {
+		return "", nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", nil
+	}
+	i := strings.Index(u.Path, wpUploadsPath)
+	if i < 0 {
+		return "", nil
+	}
+	p, err := url.PathUnescape(u.Path[i+len(wpUploadsPath):])
+	if err != nil {
+		p = u.Path[i+len(wpUploadsPath):]
+	}
+	file := filepath.Join(uploads, filepath.FromSlash(p))
+	if !within(uploads, file) {
+		return "", fmt.Errorf("图片位于上传目录之外: %s", ref)
+	}
+	if info, err := os.Stat(file); err != nil || info.IsDir() {
+		return "", fmt.Errorf("上传目录中没有图片: %s", ref)
+	}
+	return file, nil
+}
@@ -18,10 +18,20 @@ import (
 	"github.com/yuin/goldmark/renderer/html"
 	"github.com/yuin/goldmark/text"
 	"github.com/yuin/goldmark/util"
+
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/callout"
+	"myblog-gogogo/pkg/frontmatter"
+	"myblog-gogogo/pkg/mathml"
+	"myblog-gogogo/pkg/toc"
 )
 
 var md goldmark.Markdown
 
+// RendererVersion markdown 渲染管线的版本，增删 goldmark 扩展或修改渲染选项后需要加一
+// 文章中保存的版本低于此值时，读取时会自动重新渲染
+const RendererVersion = 5
+
 // VideoNode 视频节点
 type VideoNode struct {
 	ast.BaseInline
@@ -105,7 +115,7 @@ func (r *VideoRenderer) renderVideo(w util.BufWriter, source []byte, node ast.No
 		videoType = "video/ogg"
 	}
 
-	fmt.Fprintf(w, `<video controls style="max-width: 100%%; border-radius: 8px; box-shadow: 0 4px 12px rgba(0,0,0,0.1);"><source src="%s" type="%s">您的浏览器不支持视频播放。</video>`, src, videoType)
+	fmt.Fprintf(w, `<video controls style="max-width: 100%%; border-radius: 8px; box-shadow: 0 4px 12px rgba(0,0,0,0.1);"><source src="%s" type="%s">您的浏览器不支持视频播放。</video>`, util.EscapeHTML([]byte(src)), videoType)
 	return ast.WalkContinue, nil
 }
 
@@ -127,11 +137,18 @@ func init() {
 				highlighting.WithCSSWriter(nil),
 			),
 			&VideoExtension{}, // 添加视频扩展
+			callout.NewExtension(), // 提示块与折叠容器
+			&WikiLinkExtension{}, // [[标题]] 双链
+			&EmbedExtension{}, // 音乐、附件与文章嵌入
+			&ResponsiveImageExtension{}, // 本地图片的宽高、懒加载与 srcset
+			toc.NewExtension(), // 标题锚点与目录
+			mathml.NewExtension(), // 服务端公式渲染，不支持的宏交给前端 KaTeX
+			&SanitizeExtension{}, // 按保存者角色过滤原始 HTML
 		),
 		goldmark.WithRendererOptions(
 			html.WithHardWraps(),
 			html.WithXHTML(),
-			html.WithUnsafe(), // 允许原始 HTML，不转义实体
+			html.WithUnsafe(), // 允许原始 HTML，由 SanitizeExtension 按策略过滤
 		),
 	)
 }
@@ -143,22 +160,43 @@ type MarkdownDocument struct {
 	OriginalContent string
 	Path            string
 	CreatedAt       time.Time
+	FrontMatter     *frontmatter.FrontMatter // 文件头部元数据，没有头部时为 nil
+	TOC             []toc.Entry              // 文章目录，层数由头部的 toc_depth 决定
 }
 
-// ParseMarkdownFile 解析 markdown 文件
+// ParseMarkdownFile 解析 markdown 文件，原始 HTML 按 editor 策略过滤
 func ParseMarkdownFile(path string) (*MarkdownDocument, error) {
+	return ParseMarkdownFileAs(path, "")
+}
+
+// ParseMarkdownFileAs 解析 markdown 文件，原始 HTML 按名称为 policy 的策略过滤，头部元数据中的 author 不影响策略
+func ParseMarkdownFileAs(path, policy string) (*MarkdownDocument, error) {
 	// 读取文件内容
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// 提取标题（第一个 # 开头的行）
-	title := extractTitle(string(content))
+	// 解析头部元数据，正文不包含头部
+	fm, body, err := frontmatter.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse front matter: %w", err)
+	}
+
+	// 优先使用头部中的标题，否则取第一个 # 开头的行
+	title := extractTitle(string(body))
+	if fm != nil && fm.Title != "" {
+		title = fm.Title
+	}
 
-	// 转换 markdown 为 HTML
+	// 转换 markdown 为 HTML，同时提取目录
+	depth := 0
+	if fm != nil && fm.TOCDepth != nil {
+		depth = *fm.TOCDepth
+	}
 	var buf bytes.Buffer
-	if err := md.Convert(content, &buf); err != nil {
+	entries, err := toc.ConvertContext(md, newPolicyContext(HTMLPolicy(policy)), body, &buf, depth)
+	if err != nil {
 		return nil, fmt.Errorf("failed to convert markdown: %w", err)
 	}
 
@@ -171,9 +209,11 @@ func ParseMarkdownFile(path string) (*MarkdownDocument, error) {
 	return &MarkdownDocument{
 		Title:           title,
 		Content:         buf.String(),
-		OriginalContent: string(content),
+		OriginalContent: string(body),
 		Path:            path,
 		CreatedAt:       fileInfo.ModTime(),
+		FrontMatter:     fm,
+		TOC:             entries,
 	}, nil
 }
 
@@ -320,6 +360,26 @@ func ConvertToHTML(markdownContent []byte) (string, error) {
 
 // ConvertToHTMLWithOption 将 markdown 内容转换为 HTML，可选择是否移除第一行标题
 func ConvertToHTMLWithOption(markdownContent []byte, showTitle bool) (string, error) {
+	html, _, err := convertWithTOC(parser.NewContext(), markdownContent, showTitle, 0)
+	return html, err
+}
+
+// RenderPassage 根据文章的原始 markdown 重新生成 HTML 内容与目录，原始 HTML 按文章保存的策略过滤
+func RenderPassage(passage *models.Passage) error {
+	pc := newPolicyContext(HTMLPolicy(passage.HTMLPolicy))
+	html, entries, err := convertWithTOC(pc, []byte(passage.OriginalContent), passage.ShowTitle, passage.TOCDepth)
+	if err != nil {
+		return err
+	}
+	passage.Content = html
+	passage.TOC = toc.Marshal(entries)
+	passage.RendererVersion = RendererVersion
+	return nil
+}
+
+// convertWithTOC 将 markdown 内容转换为 HTML 并提取目录，可选择是否移除第一行标题
+// pc 中可以通过 newPolicyContext 指定原始 HTML 的过滤策略
+func convertWithTOC(pc parser.Context, markdownContent []byte, showTitle bool, depth int) (string, []toc.Entry, error) {
 	// 如果 showTitle 为 false，移除第一行标题
 	if !showTitle {
 		lines := bytes.Split(markdownContent, []byte("\n"))
@@ -334,14 +394,20 @@ func ConvertToHTMLWithOption(markdownContent []byte, showTitle bool) (string, er
 	}
 
 	var buf bytes.Buffer
-	if err := md.Convert(markdownContent, &buf); err != nil {
-		return "", err
+	entries, err := toc.ConvertContext(md, pc, markdownContent, &buf, depth)
+	if err != nil {
+		return "", nil, err
 	}
-	return buf.String(), nil
+	return buf.String(), entries, nil
 }
 
 // UpdateMarkdownFile 更新现有的 markdown 文件，如果文件不存在则创建
 func UpdateMarkdownFile(filePath, title, content string) error {
+	return UpdateMarkdownFileWithFrontMatter(filePath, nil, title, content)
+}
+
+// UpdateMarkdownFileWithFrontMatter 更新 markdown 文件并在开头写入头部元数据，fm 为 nil 时不写头部
+func UpdateMarkdownFileWithFrontMatter(filePath string, fm *frontmatter.FrontMatter, title, content string) error {
 	// 确保文件路径是绝对路径
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
@@ -354,8 +420,14 @@ func UpdateMarkdownFile(filePath, title, content string) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// 构建完整的markdown内容
+	// 构建完整的markdown内容，内容本身已以同名标题开头时不再重复添加
 	fullContent := fmt.Sprintf("# %s\n\n%s", title, content)
+	if firstLine, _, _ := strings.Cut(content, "\n"); strings.TrimSpace(firstLine) == "# "+title {
+		fullContent = content
+	}
+	if fm != nil {
+		fullContent = string(fm.Marshal()) + "\n" + fullContent
+	}
 
 	// 写入文件
 	if err := os.WriteFile(absPath, []byte(fullContent), 0644); err != nil {
@@ -365,6 +437,27 @@ func UpdateMarkdownFile(filePath, title, content string) error {
 	return nil
 }
 
+// PassageMarkdownPath 根据文章的 FilePath 字段获取 markdown 文件路径
+// FilePath 通常为不含 "markdown/" 前缀和 ".md" 后缀的相对路径，兼容已包含前后缀的旧数据
+func PassageMarkdownPath(filePath string) string {
+	filePath = strings.TrimPrefix(filepath.ToSlash(filePath), "markdown/")
+	filePath = strings.TrimSuffix(filePath, ".md")
+	return filepath.Join("markdown", filePath+".md")
+}
+
+// ReadFrontMatter 读取 markdown 文件现有的头部元数据，文件不存在或没有头部时返回 nil
+func ReadFrontMatter(filePath string) *frontmatter.FrontMatter {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+	fm, _, err := frontmatter.Parse(content)
+	if err != nil {
+		return nil
+	}
+	return fm
+}
+
 // GetMarkdownFilePath 根据文章ID和创建时间获取markdown文件路径
 func GetMarkdownFilePath(title string, createdAt time.Time) (string, error) {
 	// 清理标题作为文件名
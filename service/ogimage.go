@@ -0,0 +1,268 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	_ "image/gif"  // 解码 GIF 背景图片
+	_ "image/jpeg" // 解码 JPEG 背景图片
+	_ "image/png"  // 解码 PNG 背景图片
+
+	_ "golang.org/x/image/webp" // 解码 WebP 背景图片
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/ogimage"
+	"myblog-gogogo/service/settings"
+)
+
+// OGImagePrefix 生成的分享卡片的路径前缀
+const OGImagePrefix = "/og/"
+
+// ogImageVersion 卡片样式的版本，修改绘制方式后递增使旧缓存失效
+const ogImageVersion = 1
+
+// ogImageDir 分享卡片的缓存目录
+var ogImageDir = filepath.Join("data", "og")
+
+// ogFontDirs 查找卡片字体的目录，按顺序加载其中的 .ttf、.otf 与 .ttc 文件
+// data/fonts 用于放置自定义字体，之后使用编译时放入 pkg/ogimage/fonts 的中文字体子集
+var ogFontDirs = []string{
+	filepath.Join("data", "fonts"),
+	filepath.Join("img", "fonts"),
+}
+
+var (
+	ogRenderer     *ogimage.Renderer
+	ogRendererKey  string // 已加载字体的文件名与大小，字体变化后缓存失效
+	ogRendererErr  error
+	ogRendererOnce sync.Once
+	ogImageMu      sync.Mutex // 同一时间只生成一张卡片
+)
+
+// OGImagePath 文章分享卡片的路径
+func OGImagePath(passageID int) string {
+	return OGImagePrefix + strconv.Itoa(passageID) + ".png"
+}
+
+// OGImageAvailable 判断是否为文章生成分享卡片，只有已发布的公开或受密码保护的文章才生成，避免泄露未发布文章的标题
+// 已加载的字体无法绘制标题时（如没有放入中文字体）也不生成，避免标题显示为方框
+func OGImageAvailable(passage *models.Passage) bool {
+	if passage == nil || passage.Status != "published" {
+		return false
+	}
+	if !IsPublicPassage(passage) && !IsProtectedPassage(passage) {
+		return false
+	}
+	renderer, _, err := loadOGRenderer()
+	return err == nil && renderer.Covers(ogTitle(passage))
+}
+
+// GetOGImage 返回文章分享卡片的缓存文件路径，缓存不存在或已过期时重新生成
+// 文章不存在或不生成卡片时返回空字符串
+func GetOGImage(passageID int) (string, error) {
+	passage, err := db.GetPassageRepository().GetByID(passageID)
+	if err != nil {
+		return "", fmt.Errorf("获取文章失败: %w", err)
+	}
+	if !OGImageAvailable(passage) {
+		return "", nil
+	}
+
+	renderer, fontsKey, err := loadOGRenderer()
+	if err != nil {
+		return "", err
+	}
+
+	card, background := ogCard(renderer, passage)
+	key := ogImageKey(card, background, fontsKey)
+	path := filepath.Join(ogImageDir, fmt.Sprintf("%d-%s.png", passageID, key))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	ogImageMu.Lock()
+	defer ogImageMu.Unlock()
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if background != "" {
+		card.Background = loadOGBackground(background)
+	}
+	if err := os.MkdirAll(ogImageDir, 0755); err != nil {
+		return "", fmt.Errorf("创建分享卡片目录失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(ogImageDir, ".og-*.png")
+	if err != nil {
+		return "", fmt.Errorf("创建分享卡片失败: %w", err)
+	}
+	if err := renderer.Encode(tmp, card); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("生成分享卡片失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("写入分享卡片失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("写入分享卡片失败: %w", err)
+	}
+
+	// 删除标题或外观变化前生成的旧卡片
+	removeOGImages(passageID, path)
+	return path, nil
+}
+
+// RemoveOGImages 删除文章的全部分享卡片缓存
+func RemoveOGImages(passageID int) {
+	removeOGImages(passageID, "")
+}
+
+// removeOGImages 删除文章除 keep 以外的分享卡片缓存
+func removeOGImages(passageID int, keep string) {
+	matches, _ := filepath.Glob(filepath.Join(ogImageDir, strconv.Itoa(passageID)+"-*.png"))
+	for _, match := range matches {
+		if match != keep {
+			os.Remove(match)
+		}
+	}
+}
+
+// ogTitle 卡片上的标题，设置了分享标题时使用分享标题
+func ogTitle(passage *models.Passage) string {
+	if meta, err := GetPassageMeta(passage.ID); err == nil && meta.Title != "" {
+		return meta.Title
+	}
+	return passage.Title
+}
+
+// ogCard 根据文章与当前外观设置生成卡片内容，background 为背景图片的本地路径
+// 字体无法绘制的站点名称与标签不画在卡片上
+func ogCard(renderer *ogimage.Renderer, passage *models.Passage) (card ogimage.Card, background string) {
+	card.Title = ogTitle(passage)
+	if template, err := settings.GetTemplate(); err == nil {
+		if name := SiteMeta(template, "").Name; renderer.Covers(name) {
+			card.SiteName = name
+		}
+	}
+	if tags, err := NewSyncService(db.GetPassageRepository()).GetPassageTagNames(passage.ID); err == nil {
+		for _, tag := range tags {
+			if renderer.Covers(tag) {
+				card.Tags = append(card.Tags, tag)
+			}
+		}
+	}
+
+	appearance := GetAppearanceSettingsSafe()
+	card.Blur, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(appearance.BlurAmount), "px"))
+	if glass, ok := ogimage.ParseColor(appearance.CardGlassColor); ok {
+		card.GlassColor = glass
+	}
+	return card, localAssetPath(appearance.BackgroundImage)
+}
+
+// ogImageKey 根据卡片内容、背景图片文件与字体生成缓存键，任一变化后重新生成
+func ogImageKey(card ogimage.Card, background, fontsKey string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d\n%s\n%s\n%s\n%d\n%v\n%s\n", ogImageVersion, card.Title, card.SiteName,
+		strings.Join(card.Tags, ","), card.Blur, card.GlassColor, fontsKey)
+	if background != "" {
+		fmt.Fprintf(h, "%s\n", background)
+		if info, err := os.Stat(background); err == nil {
+			fmt.Fprintf(h, "%d %d\n", info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// localAssetPath 将 /img/ 与 /attachments/ 下的站内地址转换为本地文件路径，其他地址返回空字符串
+func localAssetPath(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if !strings.HasPrefix(ref, "/img/") && !strings.HasPrefix(ref, "/attachments/") {
+		return ""
+	}
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		ref = ref[:i]
+	}
+	path := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(ref, "/")))
+	if strings.HasPrefix(path, "..") {
+		return ""
+	}
+	return path
+}
+
+// loadOGBackground 读取背景图片，读取失败时返回 nil 使用渐变背景
+func loadOGBackground(path string) image.Image {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		log.Printf("Warning: 读取分享卡片背景图片失败: %v", err)
+		return nil
+	}
+	return img
+}
+
+// loadOGRenderer 加载字体并创建卡片渲染器，只在第一次调用时加载
+// 依次使用 ogFontDirs 中的字体与内置字体，都没有时只能绘制拉丁字母
+func loadOGRenderer() (*ogimage.Renderer, string, error) {
+	ogRendererOnce.Do(func() {
+		var fonts [][]byte
+		var names []string
+		for _, dir := range ogFontDirs {
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			var files []string
+			for _, entry := range entries {
+				switch strings.ToLower(filepath.Ext(entry.Name())) {
+				case ".ttf", ".otf", ".ttc":
+					files = append(files, filepath.Join(dir, entry.Name()))
+				}
+			}
+			sort.Strings(files)
+			for _, file := range files {
+				data, err := os.ReadFile(file)
+				if err == nil {
+					_, err = ogimage.NewRenderer(data)
+				}
+				if err != nil {
+					log.Printf("Warning: 读取字体 %s 失败: %v", file, err)
+					continue
+				}
+				fonts = append(fonts, data)
+				names = append(names, fmt.Sprintf("%s:%d", file, len(data)))
+			}
+		}
+		for _, embedded := range ogimage.EmbeddedFonts() {
+			if _, err := ogimage.NewRenderer(embedded.Data); err != nil {
+				log.Printf("Warning: 读取内置字体 %s 失败: %v", embedded.Name, err)
+				continue
+			}
+			fonts = append(fonts, embedded.Data)
+			names = append(names, fmt.Sprintf("embedded/%s:%d", embedded.Name, len(embedded.Data)))
+		}
+		ogRenderer, ogRendererErr = ogimage.NewRenderer(fonts...)
+		ogRendererKey = strings.Join(names, ",")
+		if ogRendererErr == nil && !ogRenderer.Covers("中文") {
+			log.Printf("Warning: 未找到中文字体，中文标题的文章不会生成分享卡片，请将中文字体放入 %s 目录", ogFontDirs[0])
+		}
+	})
+	return ogRenderer, ogRendererKey, ogRendererErr
+}
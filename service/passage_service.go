@@ -1,6 +1,7 @@
 package service
 
 import (
+	"log"
 	"time"
 
 	apperrors "myblog-gogogo/pkg/errors"
@@ -35,6 +36,15 @@ func (s *PassageService) CheckAccess(req *dto.PassageAccessRequest) (*dto.Passag
 	if passage == nil {
 		return nil, apperrors.ErrPassageNotFound
 	}
+	EnsureRendered(passage)
+
+	// 有效的预览链接可以阅读未发布、私密或受密码保护的文章
+	if ValidatePreviewLink(passage.ID, req.PreviewToken) != nil {
+		return &dto.PassageAccessResponse{
+			Allowed: true,
+			Passage: s.toDTO(passage),
+		}, nil
+	}
 
 	// 检查文章状态
 	if passage.Status != "published" {
@@ -64,6 +74,15 @@ func (s *PassageService) CheckAccess(req *dto.PassageAccessRequest) (*dto.Passag
 		}
 	}
 
+	// 受密码保护的文章需要有效的解锁凭证
+	if passage.Visibility == VisibilityProtected && req.UserRole != "admin" && !IsPassageUnlocked(passage.ID, req.UnlockToken) {
+		return &dto.PassageAccessResponse{
+			Allowed:    false,
+			Reason:     apperrors.ErrPassageProtected.Message(),
+			Visibility: passage.Visibility,
+		}, nil
+	}
+
 	// 允许访问
 	return &dto.PassageAccessResponse{
 		Allowed: true,
@@ -91,12 +110,6 @@ func (s *PassageService) GetPassageByID(id int, userRole string) (*dto.PassageDT
 
 // CreatePassage 创建文章
 func (s *PassageService) CreatePassage(req *dto.CreatePassageRequest) (*dto.PassageDTO, error) {
-	// 转换Markdown为HTML
-	htmlContent, err := ConvertToHTMLWithOption([]byte(req.Content), req.ShowTitle)
-	if err != nil {
-		return nil, apperrors.Wrap(err, "MARKDOWN_ERROR", "Markdown转换失败")
-	}
-
 	// 处理 PublishedAt
 	var publishedAt time.Time
 	if req.PublishedAt != nil {
@@ -106,15 +119,19 @@ func (s *PassageService) CreatePassage(req *dto.CreatePassageRequest) (*dto.Pass
 	// 创建文章
 	passage := &models.Passage{
 		Title:           req.Title,
-		Content:         htmlContent,
 		OriginalContent: req.Content,
 		Status:          req.Status,
 		Visibility:      req.Visibility,
-		ShowTitle:       req.ShowTitle,
+		ShowTitle:       req.ShowTitle == nil || *req.ShowTitle,
 		IsScheduled:     req.IsScheduled,
 		PublishedAt:     publishedAt,
 	}
 
+	// 转换Markdown为HTML并生成目录
+	if err := RenderPassage(passage); err != nil {
+		return nil, apperrors.Wrap(err, "MARKDOWN_ERROR", "Markdown转换失败")
+	}
+
 	// 设置默认值
 	if passage.Status == "" {
 		passage.Status = "draft"
@@ -128,6 +145,9 @@ func (s *PassageService) CreatePassage(req *dto.CreatePassageRequest) (*dto.Pass
 	if err := s.passageRepo.Create(passage); err != nil {
 		return nil, apperrors.Wrap(err, "DB_ERROR", "创建文章失败")
 	}
+	if err := UpdatePassageLinks(passage); err != nil {
+		log.Printf("Warning: 更新双链失败: %v", err)
+	}
 
 	// 重新获取完整数据
 	passage, _ = s.passageRepo.GetByID(passage.ID)
@@ -153,14 +173,11 @@ func (s *PassageService) UpdatePassage(id int, req *dto.UpdatePassageRequest) (*
 	}
 
 	if req.Content != nil {
-		// 转换Markdown为HTML
-		htmlContent, err := ConvertToHTMLWithOption([]byte(*req.Content), existingPassage.ShowTitle)
-		if err != nil {
+		// 转换Markdown为HTML并生成目录
+		existingPassage.OriginalContent = *req.Content
+		if err := RenderPassage(existingPassage); err != nil {
 			return nil, apperrors.Wrap(err, "MARKDOWN_ERROR", "Markdown转换失败")
 		}
-
-		existingPassage.Content = htmlContent
-		existingPassage.OriginalContent = *req.Content
 	}
 
 	if req.Status != nil {
@@ -187,6 +204,9 @@ func (s *PassageService) UpdatePassage(id int, req *dto.UpdatePassageRequest) (*
 	if err := s.passageRepo.Update(existingPassage); err != nil {
 		return nil, apperrors.Wrap(err, "DB_ERROR", "更新文章失败")
 	}
+	if err := UpdatePassageLinks(existingPassage); err != nil {
+		log.Printf("Warning: 更新双链失败: %v", err)
+	}
 
 	// 重新获取完整数据
 	passage, err := s.passageRepo.GetByID(id)
@@ -247,6 +267,9 @@ func (s *PassageService) PartialUpdatePassage(req *dto.PassageUpdateRequest) err
 	if err := s.passageRepo.Update(passage); err != nil {
 		return apperrors.Wrap(err, "DB_ERROR", "更新文章失败")
 	}
+	if err := UpdatePassageLinks(passage); err != nil {
+		log.Printf("Warning: 更新双链失败: %v", err)
+	}
 
 	return nil
 }
@@ -267,6 +290,9 @@ func (s *PassageService) DeletePassage(id int) error {
 	if err := s.passageRepo.Delete(id); err != nil {
 		return apperrors.Wrap(err, "DB_ERROR", "删除文章失败")
 	}
+	if err := RemovePassageLinks(passage); err != nil {
+		log.Printf("Warning: 更新双链失败: %v", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/slug"
+)
+
+// PassagePath 返回文章页面的站内路径（已转义）
+// 有别名时使用 /passage/{yyyy}/{mm}/{dd}/{slug}，否则退回文件路径或 ID
+func PassagePath(passage *models.Passage) string {
+	if passage.Slug != "" {
+		return escapePath(slug.Path(passage.CreatedAt, passage.Slug))
+	}
+
+	filePath := strings.TrimSuffix(strings.TrimPrefix(passage.FilePath, "markdown/"), ".md")
+	if filePath == "" {
+		return "/passage?id=" + strconv.Itoa(passage.ID)
+	}
+	return escapePath(slug.PathPrefix + filePath)
+}
+
+// escapePath 逐段转义路径
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// PassageLookup 文章地址解析结果
+type PassageLookup struct {
+	Passage *models.Passage
+	// Redirect 非空时表示请求的不是当前永久链接，应 301 跳转到该地址
+	Redirect string
+}
+
+// ResolvePassageURL 根据 /passage/... 路径（未转义）查找文章
+// 依次匹配当前别名、文件路径与旧链接记录；都不匹配时返回 nil
+func ResolvePassageURL(path string) (*PassageLookup, error) {
+	path = strings.TrimSuffix(path, "/")
+	rest := strings.TrimPrefix(path, slug.PathPrefix)
+	parts := strings.Split(rest, "/")
+	if rest == path || len(parts) < 4 {
+		return nil, nil
+	}
+
+	repo := db.GetPassageRepository()
+
+	passage, err := repo.GetBySlug(strings.Join(parts[3:], "/"))
+	if err != nil {
+		return nil, err
+	}
+	if passage == nil {
+		if passage, err = repo.GetByFilePath(rest); err != nil {
+			return nil, err
+		}
+	}
+	if passage == nil {
+		redirect, err := db.GetPassageRedirectRepository().GetByPath(path)
+		if err != nil || redirect == nil {
+			return nil, err
+		}
+		if passage, err = repo.GetByID(redirect.PassageID); err != nil || passage == nil {
+			return nil, err
+		}
+	}
+
+	lookup := &PassageLookup{Passage: passage}
+	if passage.Slug != "" && path != slug.Path(passage.CreatedAt, passage.Slug) {
+		lookup.Redirect = PassagePath(passage)
+	}
+	return lookup, nil
+}
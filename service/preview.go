@@ -0,0 +1,124 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+)
+
+// 预览链接有效期
+const (
+	DefaultPreviewLinkTTL = 7 * 24 * time.Hour
+	MaxPreviewLinkTTL     = 30 * 24 * time.Hour
+)
+
+// previewViewLogLimit 查看单个预览链接时返回的阅读记录数量
+const previewViewLogLimit = 100
+
+// generatePreviewToken 生成预览链接的随机令牌
+func generatePreviewToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// PreviewURL 预览链接的地址，在文章永久链接后附带令牌
+func PreviewURL(passage *models.Passage, token string) string {
+	return PassagePath(passage) + "?preview=" + token
+}
+
+// CreatePreviewLink 为文章生成预览链接，ttl 超出范围时使用默认值或最大值
+func CreatePreviewLink(passageID int, ttl time.Duration, createdBy string) (*models.PreviewLink, error) {
+	passage, err := db.GetPassageRepository().GetByID(passageID)
+	if err != nil {
+		return nil, err
+	}
+	if passage == nil {
+		return nil, fmt.Errorf("文章不存在")
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultPreviewLinkTTL
+	}
+	if ttl > MaxPreviewLinkTTL {
+		ttl = MaxPreviewLinkTTL
+	}
+
+	token, err := generatePreviewToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成预览令牌失败: %w", err)
+	}
+
+	link := &models.PreviewLink{
+		PassageID: passageID,
+		Token:     token,
+		CreatedBy: createdBy,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := db.GetPreviewLinkRepository().Create(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// ListPreviewLinks 获取文章仍然有效的预览链接
+func ListPreviewLinks(passageID int) ([]models.PreviewLink, error) {
+	return db.GetPreviewLinkRepository().GetActiveByPassageID(passageID, time.Now())
+}
+
+// GetPreviewLink 获取预览链接及其最近的阅读记录，链接不存在时返回 nil
+func GetPreviewLink(id int) (*models.PreviewLink, []models.PreviewLinkView, error) {
+	repo := db.GetPreviewLinkRepository()
+	link, err := repo.GetByID(id)
+	if err != nil || link == nil {
+		return nil, nil, err
+	}
+	if link.ViewCount, err = repo.CountViews(id); err != nil {
+		return nil, nil, err
+	}
+	views, err := repo.GetViews(id, previewViewLogLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return link, views, nil
+}
+
+// RevokePreviewLink 撤销预览链接，撤销后链接立即失效
+func RevokePreviewLink(id int) error {
+	return db.GetPreviewLinkRepository().Revoke(id)
+}
+
+// ValidatePreviewLink 校验令牌是否为该文章有效的预览链接，无效时返回 nil
+func ValidatePreviewLink(passageID int, token string) *models.PreviewLink {
+	if token == "" {
+		return nil
+	}
+	link, err := db.GetPreviewLinkRepository().GetByToken(token)
+	if err != nil {
+		log.Printf("Warning: 查询预览链接失败: %v", err)
+		return nil
+	}
+	if link == nil || link.PassageID != passageID || !link.Active(time.Now()) {
+		return nil
+	}
+	return link
+}
+
+// RecordPreviewView 记录一次通过预览链接的阅读
+func RecordPreviewView(link *models.PreviewLink, ip, userAgent string) {
+	view := &models.PreviewLinkView{
+		LinkID:    link.ID,
+		IP:        ip,
+		UserAgent: userAgent,
+	}
+	if err := db.GetPreviewLinkRepository().RecordView(view); err != nil {
+		log.Printf("Warning: 记录预览链接阅读失败: %v", err)
+	}
+}
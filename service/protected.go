@@ -0,0 +1,125 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"myblog-gogogo/auth"
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	apperrors "myblog-gogogo/pkg/errors"
+)
+
+// VisibilityProtected 受密码保护的文章，非管理员输入密码后才能阅读正文
+const VisibilityProtected = "protected"
+
+// PassageUnlockTTL 输入密码后解锁凭证的有效期
+const PassageUnlockTTL = 2 * time.Hour
+
+// IsProtectedPassage 判断文章是否受密码保护
+func IsProtectedPassage(passage *models.Passage) bool {
+	return passage != nil && passage.Visibility == VisibilityProtected
+}
+
+// PassageUnlockCookie 文章解锁凭证的 cookie 名称，每篇文章使用单独的 cookie
+func PassageUnlockCookie(passageID int) string {
+	return fmt.Sprintf("passage_unlock_%d", passageID)
+}
+
+// SetPassagePassword 设置文章的访问密码，使用 Argon2id 哈希保存，修改后已发放的解锁凭证失效
+func SetPassagePassword(passageID int, password string) error {
+	if strings.TrimSpace(password) == "" {
+		return fmt.Errorf("文章密码不能为空")
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return db.GetPassagePasswordRepository().Set(passageID, hash)
+}
+
+// ClearPassagePassword 删除文章的访问密码
+func ClearPassagePassword(passageID int) error {
+	return db.GetPassagePasswordRepository().Delete(passageID)
+}
+
+// HasPassagePassword 判断文章是否已设置访问密码
+func HasPassagePassword(passageID int) (bool, error) {
+	password, err := db.GetPassagePasswordRepository().Get(passageID)
+	return password != nil, err
+}
+
+// CheckProtectedVisibility 保存文章前检查：受密码保护的文章必须提交密码或已有密码
+func CheckProtectedVisibility(passageID int, visibility, password string) error {
+	if visibility != VisibilityProtected || strings.TrimSpace(password) != "" {
+		return nil
+	}
+	if passageID > 0 {
+		has, err := HasPassagePassword(passageID)
+		if err != nil || has {
+			return err
+		}
+	}
+	return fmt.Errorf("受密码保护的文章需要设置访问密码")
+}
+
+// SavePassagePassword 文章保存后更新访问密码：受保护时按提交的密码更新，密码为空时保留原密码；不再受保护时删除密码
+func SavePassagePassword(passage *models.Passage, password string) error {
+	if !IsProtectedPassage(passage) {
+		return ClearPassagePassword(passage.ID)
+	}
+	if strings.TrimSpace(password) == "" {
+		return nil
+	}
+	return SetPassagePassword(passage.ID, password)
+}
+
+// passwordFingerprint 密码哈希的指纹，写入解锁凭证，用于在修改密码后让旧凭证失效
+func passwordFingerprint(hash string) string {
+	sum := sha256.Sum256([]byte(hash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// UnlockPassage 校验文章密码，正确时返回解锁凭证
+func UnlockPassage(passageID int, password string) (string, error) {
+	passage, err := db.GetPassageRepository().GetByID(passageID)
+	if err != nil {
+		return "", apperrors.Wrap(err, "DB_ERROR", "数据库查询失败")
+	}
+	if passage == nil || passage.Status != "published" || !IsProtectedPassage(passage) {
+		return "", apperrors.ErrPassageNotFound
+	}
+
+	stored, err := db.GetPassagePasswordRepository().Get(passageID)
+	if err != nil {
+		return "", apperrors.Wrap(err, "DB_ERROR", "数据库查询失败")
+	}
+	if stored == nil {
+		return "", apperrors.ErrPassagePasswordIncorrect
+	}
+	ok, err := auth.VerifyPassword(password, stored.PasswordHash)
+	if err != nil || !ok {
+		return "", apperrors.ErrPassagePasswordIncorrect
+	}
+
+	token, err := auth.GeneratePassageToken(passageID, passwordFingerprint(stored.PasswordHash), PassageUnlockTTL)
+	if err != nil {
+		return "", apperrors.Wrap(err, "TOKEN_ERROR", "生成解锁凭证失败")
+	}
+	return token, nil
+}
+
+// IsPassageUnlocked 判断解锁凭证对文章当前的密码是否有效
+func IsPassageUnlocked(passageID int, token string) bool {
+	if token == "" {
+		return false
+	}
+	stored, err := db.GetPassagePasswordRepository().Get(passageID)
+	if err != nil || stored == nil {
+		return false
+	}
+	return auth.ValidatePassageToken(token, passageID, passwordFingerprint(stored.PasswordHash)) == nil
+}
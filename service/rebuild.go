@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+)
+
+// rebuildBatchSize 每批提交给 ConcurrentService 的文章数，不超过工作池队列长度
+const rebuildBatchSize = 100
+
+// RebuildProgress 重新渲染全部文章的进度
+type RebuildProgress struct {
+	Running    bool      `json:"running"`
+	Total      int       `json:"total"`
+	Done       int       `json:"done"`
+	Failed     int       `json:"failed"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+var (
+	rebuildMu       sync.Mutex
+	rebuildProgress RebuildProgress
+)
+
+// ErrRebuildRunning 重新渲染任务正在执行
+var ErrRebuildRunning = errors.New("文章重新渲染任务正在执行")
+
+// GetRebuildProgress 获取重新渲染任务的进度
+func GetRebuildProgress() RebuildProgress {
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+	return rebuildProgress
+}
+
+// StartRebuild 在后台重新渲染全部文章，进度通过 GetRebuildProgress 查询
+func StartRebuild() (RebuildProgress, error) {
+	progress, err := beginRebuild()
+	if err != nil {
+		return progress, err
+	}
+	go func() {
+		final := runRebuild(context.Background(), nil)
+		log.Printf("文章重新渲染完成: %d 篇，失败 %d 篇，耗时 %v",
+			final.Total, final.Failed, final.FinishedAt.Sub(final.StartedAt).Round(time.Millisecond))
+	}()
+	return progress, nil
+}
+
+// RebuildPassages 从 OriginalContent 重新渲染全部文章，完成后返回
+// 每处理完一批调用一次 onProgress
+func RebuildPassages(ctx context.Context, onProgress func(RebuildProgress)) (RebuildProgress, error) {
+	if progress, err := beginRebuild(); err != nil {
+		return progress, err
+	}
+	return runRebuild(ctx, onProgress), nil
+}
+
+// beginRebuild 标记任务开始，同一时间只允许一个任务
+func beginRebuild() (RebuildProgress, error) {
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+	if rebuildProgress.Running {
+		return rebuildProgress, ErrRebuildRunning
+	}
+	rebuildProgress = RebuildProgress{Running: true, StartedAt: time.Now()}
+	return rebuildProgress, nil
+}
+
+// runRebuild 分批并发渲染，所有批次结束后标记任务完成
+func runRebuild(ctx context.Context, onProgress func(RebuildProgress)) RebuildProgress {
+	ids, err := db.GetPassageRepository().GetAllIDs()
+	if err != nil {
+		return finishRebuild(fmt.Errorf("获取文章列表失败: %w", err))
+	}
+	rebuildMu.Lock()
+	rebuildProgress.Total = len(ids)
+	rebuildMu.Unlock()
+
+	concurrent := NewConcurrentService(rebuildBatchSize)
+	for start := 0; start < len(ids); start += rebuildBatchSize {
+		if ctx.Err() != nil {
+			return finishRebuild(ctx.Err())
+		}
+		end := min(start+rebuildBatchSize, len(ids))
+
+		tasks := make([]func(context.Context) error, 0, end-start)
+		for _, id := range ids[start:end] {
+			tasks = append(tasks, func(ctx context.Context) error {
+				err := rerenderPassage(id)
+				recordRebuild(err)
+				return err
+			})
+		}
+		// 单篇失败已记录在进度中，这里只需要等待整批结束
+		_ = concurrent.ProcessConcurrentTasks(ctx, tasks)
+
+		if onProgress != nil {
+			onProgress(GetRebuildProgress())
+		}
+	}
+	return finishRebuild(nil)
+}
+
+// rerenderPassage 重新渲染一篇文章并保存
+func rerenderPassage(id int) error {
+	repo := db.GetPassageRepository()
+	passage, err := repo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("文章 %d: %w", id, err)
+	}
+	if passage == nil {
+		return nil
+	}
+	if err := RenderPassage(passage); err != nil {
+		return fmt.Errorf("文章 %d 渲染失败: %w", id, err)
+	}
+	if err := repo.UpdateRendered(passage); err != nil {
+		return fmt.Errorf("文章 %d 保存失败: %w", id, err)
+	}
+	return nil
+}
+
+// recordRebuild 记录一篇文章的处理结果
+func recordRebuild(err error) {
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+	rebuildProgress.Done++
+	if err != nil {
+		rebuildProgress.Failed++
+		rebuildProgress.LastError = err.Error()
+	}
+}
+
+// finishRebuild 标记任务结束，未能提交到工作池的文章计为失败
+func finishRebuild(err error) RebuildProgress {
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+	if missed := rebuildProgress.Total - rebuildProgress.Done; missed > 0 {
+		rebuildProgress.Failed += missed
+		rebuildProgress.Done = rebuildProgress.Total
+	}
+	if err != nil {
+		rebuildProgress.LastError = err.Error()
+	}
+	rebuildProgress.Running = false
+	rebuildProgress.FinishedAt = time.Now()
+	return rebuildProgress
+}
+
+// EnsureRendered 文章的渲染器版本低于当前版本时重新渲染并保存
+// 失败时保留原有内容，下次读取时再试
+func EnsureRendered(passage *models.Passage) {
+	if passage == nil || passage.RendererVersion >= RendererVersion {
+		return
+	}
+	stale := *passage
+	if err := RenderPassage(passage); err != nil {
+		log.Printf("Warning: 重新渲染文章 %d 失败: %v", passage.ID, err)
+		*passage = stale
+		return
+	}
+	if err := db.GetPassageRepository().UpdateRendered(passage); err != nil {
+		log.Printf("Warning: 保存文章 %d 的渲染结果失败: %v", passage.ID, err)
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/search"
+)
+
+// 相关文章的计算参数
+const (
+	relatedStoredCount = 10               // 每篇文章保存的相关文章数，展示数量由设置决定
+	relatedDebounce    = 10 * time.Second // 文章变化后等待的时间，连续编辑只触发一次计算
+)
+
+// RelatedJob 相关文章计算任务
+// 得分依赖全部文章的词频，任意文章变化都需要整体重新计算，
+// 因此仓库的变更通知只标记数据已过期，由后台循环合并一段时间内的变化后统一计算并写入 passage_related
+type RelatedJob struct {
+	passageRepo repositories.PassageRepository
+	tagRepo     repositories.TagRepository
+	passageTags repositories.PassageTagRepository
+	relatedRepo repositories.PassageRelatedRepository
+	debounce    time.Duration
+
+	quit chan struct{}
+	wake chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+var (
+	relatedJob     *RelatedJob
+	relatedJobOnce sync.Once
+)
+
+// NewRelatedJob 创建相关文章计算任务
+func NewRelatedJob(passageRepo repositories.PassageRepository, tagRepo repositories.TagRepository,
+	passageTags repositories.PassageTagRepository, relatedRepo repositories.PassageRelatedRepository, debounce time.Duration) *RelatedJob {
+	return &RelatedJob{
+		passageRepo: passageRepo,
+		tagRepo:     tagRepo,
+		passageTags: passageTags,
+		relatedRepo: relatedRepo,
+		debounce:    debounce,
+		quit:        make(chan struct{}),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// InitRelatedJob 初始化并启动全局相关文章计算任务，启动后立即计算一次
+func InitRelatedJob() *RelatedJob {
+	relatedJobOnce.Do(func() {
+		relatedJob = NewRelatedJob(db.GetPassageRepository(), db.GetTagRepository(),
+			db.GetPassageTagRepository(), db.GetPassageRelatedRepository(), relatedDebounce)
+		repositories.OnPassageChange(func(int) { relatedJob.MarkDirty() })
+		relatedJob.Start()
+	})
+	return relatedJob
+}
+
+// StopRelatedJob 停止全局相关文章计算任务
+func StopRelatedJob() {
+	if relatedJob != nil {
+		relatedJob.Stop()
+	}
+}
+
+// Start 启动后台循环
+func (j *RelatedJob) Start() {
+	j.wg.Add(1)
+	go j.loop()
+}
+
+// Stop 停止后台循环并等待正在进行的计算完成
+func (j *RelatedJob) Stop() {
+	j.once.Do(func() {
+		close(j.quit)
+	})
+	j.wg.Wait()
+}
+
+// MarkDirty 标记相关文章已过期，在等待时间后重新计算
+func (j *RelatedJob) MarkDirty() {
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop 后台主循环
+func (j *RelatedJob) loop() {
+	defer j.wg.Done()
+
+	j.run()
+	for {
+		select {
+		case <-j.quit:
+			return
+		case <-j.wake:
+		}
+
+		// 等待期间的其他变化合并到同一次计算
+		timer := time.NewTimer(j.debounce)
+		select {
+		case <-j.quit:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		select {
+		case <-j.wake:
+		default:
+		}
+		j.run()
+	}
+}
+
+// run 执行一次计算并记录结果
+func (j *RelatedJob) run() {
+	start := time.Now()
+	count, err := j.Recompute()
+	if err != nil {
+		log.Printf("[Related] 计算相关文章失败: %v", err)
+		return
+	}
+	log.Printf("[Related] 相关文章计算完成: %d 篇文章，耗时 %v", count, time.Since(start).Round(time.Millisecond))
+}
+
+// Recompute 重新计算所有已发布公开文章的相关文章，返回参与计算的文章数
+// 私密、未发布与已删除的文章既不计算也不会被推荐
+func (j *RelatedJob) Recompute() (int, error) {
+	total, err := j.passageRepo.Count()
+	if err != nil {
+		return 0, fmt.Errorf("统计文章数量失败: %w", err)
+	}
+	all, err := j.passageRepo.GetAll(total, 0)
+	if err != nil {
+		return 0, fmt.Errorf("获取文章失败: %w", err)
+	}
+	var passages []models.Passage
+	for i := range all {
+		if IsPublicPassage(&all[i]) {
+			passages = append(passages, all[i])
+		}
+	}
+
+	tags, err := j.tagRepo.GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("获取标签失败: %w", err)
+	}
+	tagNames := make(map[int]string, len(tags))
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+	}
+
+	publicIDs := make([]int, len(passages))
+	for i := range passages {
+		publicIDs[i] = passages[i].ID
+	}
+	tagIDsByPassage, err := j.passageTags.GetTagIDsByPassageIDs(publicIDs)
+	if err != nil {
+		return 0, fmt.Errorf("获取文章标签失败: %w", err)
+	}
+
+	docs := make([]*search.Document, len(passages))
+	for i := range passages {
+		docs[i] = searchDocument(&passages[i], resolveTagNames(tagIDsByPassage[passages[i].ID], tagNames))
+	}
+
+	var rows []models.PassageRelated
+	for passageID, scores := range search.Related(docs, relatedStoredCount) {
+		for _, s := range scores {
+			rows = append(rows, models.PassageRelated{PassageID: passageID, RelatedID: s.ID, Score: s.Score})
+		}
+	}
+	if err := j.relatedRepo.ReplaceAll(rows); err != nil {
+		return 0, fmt.Errorf("保存相关文章失败: %w", err)
+	}
+	return len(docs), nil
+}
+
+// RelatedEntry 文章详情中的一篇相关文章
+type RelatedEntry struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Summary string `json:"summary"`
+}
+
+// GetRelatedPassages 获取文章预先计算好的相关文章，最多 limit 篇
+func GetRelatedPassages(passageID, limit int) ([]RelatedEntry, error) {
+	entries := []RelatedEntry{}
+	if limit <= 0 {
+		return entries, nil
+	}
+	passages, err := db.GetPassageRelatedRepository().GetRelated(passageID, limit)
+	if err != nil {
+		return entries, err
+	}
+	for i := range passages {
+		entries = append(entries, RelatedEntry{
+			ID:      passages[i].ID,
+			Title:   passages[i].Title,
+			URL:     PassagePath(&passages[i]),
+			Summary: passages[i].Summary,
+		})
+	}
+	return entries, nil
+}
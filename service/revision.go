@@ -0,0 +1,221 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/diff"
+)
+
+// RevisionService 文章修订记录服务
+type RevisionService struct {
+	revisionRepo repositories.PassageRevisionRepository
+	passageRepo  repositories.PassageRepository
+}
+
+// NewRevisionService 创建文章修订记录服务
+func NewRevisionService() *RevisionService {
+	return &RevisionService{
+		revisionRepo: db.GetPassageRevisionRepository(),
+		passageRepo:  db.GetPassageRepository(),
+	}
+}
+
+// RevisionDiff 两个修订之间的差异
+type RevisionDiff struct {
+	From    *models.PassageRevision `json:"from"`
+	To      *models.PassageRevision `json:"to"`
+	Diff    string                  `json:"diff"`
+	Added   int                     `json:"added"`
+	Removed int                     `json:"removed"`
+}
+
+// RecordBaseline 文章还没有任何修订时，把修改前的内容记录为初始修订
+// 在覆盖文章内容之前调用，保证启用修订功能前的文章在第一次修改后也能恢复
+func (s *RevisionService) RecordBaseline(passage *models.Passage) error {
+	if passage == nil || passage.ID == 0 {
+		return nil
+	}
+
+	count, err := s.revisionRepo.CountByPassageID(passage.ID)
+	if err != nil {
+		return fmt.Errorf("统计修订记录失败: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return s.revisionRepo.Create(&models.PassageRevision{
+		PassageID: passage.ID,
+		Title:     passage.Title,
+		Content:   passage.OriginalContent,
+		Summary:   passage.Summary,
+		Category:  passage.Category,
+		Author:    passage.Author,
+		Source:    models.RevisionSourceInitial,
+		CreatedAt: passage.UpdatedAt,
+	})
+}
+
+// Record 记录文章当前状态的快照，与最新修订相同时跳过
+func (s *RevisionService) Record(passage *models.Passage, author, source string) (*models.PassageRevision, error) {
+	if passage == nil || passage.ID == 0 {
+		return nil, nil
+	}
+
+	latest, err := s.revisionRepo.GetLatest(passage.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新修订失败: %w", err)
+	}
+	if latest != nil && latest.Title == passage.Title && latest.Content == passage.OriginalContent &&
+		latest.Summary == passage.Summary && latest.Category == passage.Category {
+		return latest, nil
+	}
+
+	if author == "" {
+		author = passage.Author
+	}
+
+	revision := &models.PassageRevision{
+		PassageID: passage.ID,
+		Title:     passage.Title,
+		Content:   passage.OriginalContent,
+		Summary:   passage.Summary,
+		Category:  passage.Category,
+		Author:    author,
+		Source:    source,
+	}
+	if err := s.revisionRepo.Create(revision); err != nil {
+		return nil, fmt.Errorf("保存修订记录失败: %w", err)
+	}
+	return revision, nil
+}
+
+// ListRevisions 按时间倒序列出文章的修订记录
+func (s *RevisionService) ListRevisions(passageID, limit, offset int) ([]models.PassageRevision, int, error) {
+	revisions, err := s.revisionRepo.GetByPassageID(passageID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.revisionRepo.CountByPassageID(passageID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+// GetRevision 获取单个修订记录
+func (s *RevisionService) GetRevision(id int) (*models.PassageRevision, error) {
+	return s.revisionRepo.GetByID(id)
+}
+
+// Diff 生成两个修订之间的 unified diff（from 为旧版本，to 为新版本）
+func (s *RevisionService) Diff(fromID, toID int) (*RevisionDiff, error) {
+	from, err := s.revisionRepo.GetByID(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.revisionRepo.GetByID(toID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("修订记录不存在")
+	}
+	if from.PassageID != to.PassageID {
+		return nil, fmt.Errorf("只能比较同一篇文章的修订")
+	}
+
+	fromText := revisionText(from)
+	toText := revisionText(to)
+	stats := diff.LineStats(fromText, toText)
+
+	return &RevisionDiff{
+		From: from,
+		To:   to,
+		Diff: diff.Unified(
+			fmt.Sprintf("revision/%d\t%s", from.ID, from.CreatedAt.Format("2006-01-02 15:04:05")),
+			fmt.Sprintf("revision/%d\t%s", to.ID, to.CreatedAt.Format("2006-01-02 15:04:05")),
+			fromText, toText, diff.DefaultContext,
+		),
+		Added:   stats.Added,
+		Removed: stats.Removed,
+	}, nil
+}
+
+// revisionText 生成用于比较的文本，标题作为第一行参与比较
+func revisionText(revision *models.PassageRevision) string {
+	heading := "# " + revision.Title
+	if firstLine, _, _ := strings.Cut(revision.Content, "\n"); strings.TrimSpace(firstLine) == heading {
+		return revision.Content
+	}
+	return heading + "\n\n" + revision.Content
+}
+
+// Restore 将文章恢复到指定修订，同时重写磁盘上的 markdown 文件，并记录一条恢复修订
+// policy 为按恢复者角色确定的过滤策略
+func (s *RevisionService) Restore(revisionID int, author, policy string) (*models.Passage, error) {
+	revision, err := s.revisionRepo.GetByID(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, fmt.Errorf("修订记录不存在")
+	}
+
+	passage, err := s.passageRepo.GetByID(revision.PassageID)
+	if err != nil {
+		return nil, err
+	}
+	if passage == nil {
+		return nil, fmt.Errorf("文章不存在")
+	}
+
+	// 恢复前确保当前内容也有记录，恢复操作本身可以再被撤销
+	if err := s.RecordBaseline(passage); err != nil {
+		log.Printf("Warning: 记录初始修订失败: %v", err)
+	}
+
+	passage.Title = revision.Title
+	passage.OriginalContent = revision.Content
+	passage.Summary = revision.Summary
+	if revision.Category != "" {
+		passage.Category = revision.Category
+	}
+	passage.HTMLPolicy = policy
+
+	if err := RenderPassage(passage); err != nil {
+		return nil, fmt.Errorf("Markdown转换失败: %w", err)
+	}
+
+	// 重写磁盘上的文件，原文件带有头部元数据时按原格式回写
+	filePath := PassageMarkdownPath(passage.FilePath)
+	header := ReadFrontMatter(filePath)
+	if header != nil {
+		tagNames, err := NewSyncService(s.passageRepo).GetPassageTagNames(passage.ID)
+		if err != nil {
+			log.Printf("Warning: 获取文章标签失败: %v", err)
+		}
+		header.Update(passage, tagNames)
+	}
+	if err := UpdateMarkdownFileWithFrontMatter(filePath, header, passage.Title, passage.OriginalContent); err != nil {
+		return nil, fmt.Errorf("重写markdown文件失败: %w", err)
+	}
+
+	if err := s.passageRepo.Update(passage); err != nil {
+		return nil, fmt.Errorf("更新文章失败: %w", err)
+	}
+
+	if _, err := s.Record(passage, author, models.RevisionSourceRestore); err != nil {
+		log.Printf("Warning: 记录恢复修订失败: %v", err)
+	}
+	if err := UpdatePassageLinks(passage); err != nil {
+		log.Printf("Warning: 更新双链失败: %v", err)
+	}
+
+	return passage, nil
+}
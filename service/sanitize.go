@@ -0,0 +1,289 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/sanitize"
+	"myblog-gogogo/service/settings"
+)
+
+// 解析上下文中传递过滤策略与取回过滤说明的键
+var (
+	htmlPolicyKey = parser.NewContextKey()
+	htmlNotesKey  = parser.NewContextKey()
+)
+
+// HTMLPolicy 按策略名称创建文章 HTML 的过滤策略，只有 trusted 使用管理员策略，空值与未知的名称使用 editor 策略
+func HTMLPolicy(name string) *sanitize.Policy {
+	htmlSettings, _ := settings.GetHTML()
+	if name == sanitize.NameTrusted {
+		return sanitize.Trusted(htmlSettings.ScriptWhitelist)
+	}
+	return sanitize.Editor(htmlSettings.IframeWhitelist)
+}
+
+// HTMLPolicyForRole 根据保存文章的用户角色确定过滤策略名称，管理员使用 trusted 策略，其他角色使用 editor 策略
+// 策略随文章保存，不由作者名称或头部元数据中的 author 决定
+func HTMLPolicyForRole(role string) string {
+	if role == "admin" {
+		return sanitize.NameTrusted
+	}
+	return sanitize.NameEditor
+}
+
+// HTMLPolicyForUser 根据保存文章的用户 ID 确定过滤策略名称，用户不存在时使用 editor 策略
+func HTMLPolicyForUser(userID int) string {
+	user, err := db.GetUserRepository().GetByID(userID)
+	if err != nil || user == nil {
+		return sanitize.NameEditor
+	}
+	return HTMLPolicyForRole(user.Role)
+}
+
+// CommentHTML 按评论策略将评论内容转换为 HTML，只保留少量行内标签，换行转换为 <br>
+func CommentHTML(content string) string {
+	return sanitize.Comment().Sanitize(content)
+}
+
+// SanitizeReportItem 过滤前后输出不同的文章
+type SanitizeReportItem struct {
+	ID     int      `json:"id"`
+	Title  string   `json:"title"`
+	Author string   `json:"author"`
+	Policy string   `json:"policy"`
+	Notes  []string `json:"notes"` // 被移除或改写的内容
+}
+
+// SanitizeReport 按各自保存的策略重新渲染所有文章，列出输出被过滤改变的文章
+func SanitizeReport() ([]SanitizeReportItem, error) {
+	passages, err := db.GetPassageRepository().GetAll(100000, 0)
+	if err != nil {
+		return nil, err
+	}
+	items := []SanitizeReportItem{}
+	for i := range passages {
+		passage := &passages[i]
+		policy := HTMLPolicy(passage.HTMLPolicy)
+		_, notes, err := renderPassageWithPolicy(passage, policy)
+		if err != nil {
+			return nil, fmt.Errorf("渲染文章 %d 失败: %w", passage.ID, err)
+		}
+		if len(notes) == 0 {
+			continue
+		}
+		items = append(items, SanitizeReportItem{
+			ID:     passage.ID,
+			Title:  passage.Title,
+			Author: passage.Author,
+			Policy: policy.Name,
+			Notes:  notes,
+		})
+	}
+	return items, nil
+}
+
+// renderPassageWithPolicy 使用指定策略渲染文章，返回 HTML 与过滤说明，不修改文章
+func renderPassageWithPolicy(passage *models.Passage, policy *sanitize.Policy) (string, []string, error) {
+	pc := newPolicyContext(policy)
+	html, _, err := convertWithTOC(pc, []byte(passage.OriginalContent), passage.ShowTitle, passage.TOCDepth)
+	if err != nil {
+		return "", nil, err
+	}
+	return html, htmlNotes(pc), nil
+}
+
+// newPolicyContext 创建带有过滤策略的解析上下文
+func newPolicyContext(policy *sanitize.Policy) parser.Context {
+	pc := parser.NewContext()
+	pc.Set(htmlPolicyKey, policy)
+	return pc
+}
+
+// htmlNotes 取回渲染时记录的过滤说明，已去重并排序
+func htmlNotes(pc parser.Context) []string {
+	notes, _ := pc.Get(htmlNotesKey).(map[string]bool)
+	list := make([]string, 0, len(notes))
+	for n := range notes {
+		list = append(list, n)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// SanitizedHTMLBlock 过滤后的 HTML 块
+type SanitizedHTMLBlock struct {
+	ast.BaseBlock
+	HTML string
+}
+
+// KindSanitizedHTMLBlock 过滤后的 HTML 块节点类型
+var KindSanitizedHTMLBlock = ast.NewNodeKind("SanitizedHTMLBlock")
+
+// Kind 实现 Node 接口
+func (n *SanitizedHTMLBlock) Kind() ast.NodeKind {
+	return KindSanitizedHTMLBlock
+}
+
+// IsRaw 实现 Node 接口
+func (n *SanitizedHTMLBlock) IsRaw() bool {
+	return true
+}
+
+// Dump 实现 Node 接口
+func (n *SanitizedHTMLBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"HTML": n.HTML}, nil)
+}
+
+// SanitizedRawHTML 过滤后的行内 HTML
+type SanitizedRawHTML struct {
+	ast.BaseInline
+	HTML string
+}
+
+// KindSanitizedRawHTML 过滤后的行内 HTML 节点类型
+var KindSanitizedRawHTML = ast.NewNodeKind("SanitizedRawHTML")
+
+// Kind 实现 Node 接口
+func (n *SanitizedRawHTML) Kind() ast.NodeKind {
+	return KindSanitizedRawHTML
+}
+
+// Dump 实现 Node 接口
+func (n *SanitizedRawHTML) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"HTML": n.HTML}, nil)
+}
+
+// SanitizeASTTransformer 按解析上下文中的策略过滤原始 HTML 与链接地址
+// 上下文中没有策略时使用 editor 策略
+type SanitizeASTTransformer struct{}
+
+// Transform 转换 AST
+func (t *SanitizeASTTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	policy, _ := pc.Get(htmlPolicyKey).(*sanitize.Policy)
+	if policy == nil {
+		policy = HTMLPolicy("")
+	}
+	source := reader.Source()
+
+	// 遍历时不修改树，先收集需要处理的节点
+	var nodes []ast.Node
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindHTMLBlock, ast.KindRawHTML, ast.KindLink, ast.KindImage, ast.KindAutoLink:
+			nodes = append(nodes, n)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	notes, _ := pc.Get(htmlNotesKey).(map[string]bool)
+	if notes == nil {
+		notes = map[string]bool{}
+	}
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case *ast.HTMLBlock:
+			var buf bytes.Buffer
+			for i := 0; i < n.Lines().Len(); i++ {
+				line := n.Lines().At(i)
+				buf.Write(line.Value(source))
+			}
+			if n.HasClosure() {
+				buf.Write(n.ClosureLine.Value(source))
+			}
+			html, changes := policy.Clean(buf.String())
+			addNotes(notes, changes)
+			n.Parent().ReplaceChild(n.Parent(), n, &SanitizedHTMLBlock{HTML: html})
+		case *ast.RawHTML:
+			var buf bytes.Buffer
+			for i := 0; i < n.Segments.Len(); i++ {
+				segment := n.Segments.At(i)
+				buf.Write(segment.Value(source))
+			}
+			html, changes := policy.Clean(buf.String())
+			addNotes(notes, changes)
+			n.Parent().ReplaceChild(n.Parent(), n, &SanitizedRawHTML{HTML: html})
+		case *ast.Link:
+			if !policy.AllowURL(string(n.Destination), false) {
+				notes[sanitize.Scheme(string(n.Destination))+" 链接"] = true
+				unwrap(n)
+			}
+		case *ast.Image:
+			if !policy.AllowURL(string(n.Destination), true) {
+				notes[sanitize.Scheme(string(n.Destination))+" 图片"] = true
+				unwrap(n)
+			}
+		case *ast.AutoLink:
+			if url := string(n.URL(source)); !policy.AllowURL(url, false) {
+				notes[sanitize.Scheme(url)+" 链接"] = true
+				n.Parent().ReplaceChild(n.Parent(), n, ast.NewString(n.Label(source)))
+			}
+		}
+	}
+	pc.Set(htmlNotesKey, notes)
+}
+
+// addNotes 合并过滤说明
+func addNotes(notes map[string]bool, changes []string) {
+	for _, c := range changes {
+		notes[c] = true
+	}
+}
+
+// unwrap 移除链接或图片节点，保留其中的文字
+func unwrap(n ast.Node) {
+	parent := n.Parent()
+	for c := n.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		parent.InsertBefore(parent, n, c)
+		c = next
+	}
+	parent.RemoveChild(parent, n)
+}
+
+// SanitizeRenderer 过滤后的 HTML 渲染器
+type SanitizeRenderer struct{}
+
+// RegisterFuncs 注册渲染函数
+func (r *SanitizeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindSanitizedHTMLBlock, r.renderHTMLBlock)
+	reg.Register(KindSanitizedRawHTML, r.renderRawHTML)
+}
+
+// renderHTMLBlock 原样输出过滤后的 HTML 块
+func (r *SanitizeRenderer) renderHTMLBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString(node.(*SanitizedHTMLBlock).HTML)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// renderRawHTML 原样输出过滤后的行内 HTML
+func (r *SanitizeRenderer) renderRawHTML(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString(node.(*SanitizedRawHTML).HTML)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// SanitizeExtension 原始 HTML 过滤扩展，在其他扩展转换完成后运行
+type SanitizeExtension struct{}
+
+// Extend 扩展 Goldmark
+func (e *SanitizeExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(&SanitizeASTTransformer{}, 900)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&SanitizeRenderer{}, 100)))
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/service/kafka"
+)
+
+// PublishScheduler 定时发布调度器
+// 启动时先补发停机期间错过的文章，之后按检查间隔轮询，
+// 若下一篇文章的发布时间早于下次轮询则提前唤醒，保证准时发布
+type PublishScheduler struct {
+	repo     repositories.PassageRepository
+	interval time.Duration
+	quit     chan struct{}
+	wake     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+var (
+	publishScheduler     *PublishScheduler
+	publishSchedulerOnce sync.Once
+)
+
+// NewPublishScheduler 创建定时发布调度器
+func NewPublishScheduler(repo repositories.PassageRepository, interval time.Duration) *PublishScheduler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &PublishScheduler{
+		repo:     repo,
+		interval: interval,
+		quit:     make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// InitPublishScheduler 初始化并启动全局定时发布调度器
+func InitPublishScheduler(repo repositories.PassageRepository, interval time.Duration) *PublishScheduler {
+	publishSchedulerOnce.Do(func() {
+		publishScheduler = NewPublishScheduler(repo, interval)
+		publishScheduler.Start()
+	})
+	return publishScheduler
+}
+
+// GetPublishScheduler 获取全局定时发布调度器，未初始化时返回 nil
+func GetPublishScheduler() *PublishScheduler {
+	return publishScheduler
+}
+
+// StopPublishScheduler 停止全局定时发布调度器
+func StopPublishScheduler() {
+	if publishScheduler != nil {
+		publishScheduler.Stop()
+	}
+}
+
+// Start 启动调度循环
+func (s *PublishScheduler) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop 停止调度循环并等待当前批次完成
+func (s *PublishScheduler) Stop() {
+	s.once.Do(func() {
+		close(s.quit)
+	})
+	s.wg.Wait()
+}
+
+// Notify 通知调度器重新计算下一次发布时间（文章的定时设置变化后调用）
+func (s *PublishScheduler) Notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop 调度主循环
+func (s *PublishScheduler) loop() {
+	defer s.wg.Done()
+
+	// 启动时立即执行一次，补发停机期间到期的文章
+	next := s.runAndPlan()
+
+	timer := time.NewTimer(next)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-s.wake:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		case <-timer.C:
+		}
+
+		timer.Reset(s.runAndPlan())
+	}
+}
+
+// runAndPlan 发布所有到期文章，并返回距离下一次检查的等待时间
+func (s *PublishScheduler) runAndPlan() time.Duration {
+	now := time.Now()
+	if _, err := s.PublishDue(now); err != nil {
+		log.Printf("[Scheduler] 定时发布失败: %v", err)
+		return s.interval
+	}
+
+	pending, err := s.repo.GetPendingScheduled()
+	if err != nil {
+		log.Printf("[Scheduler] 获取定时文章失败: %v", err)
+		return s.interval
+	}
+
+	wait := s.interval
+	for _, passage := range pending {
+		if passage.PublishedAt.IsZero() {
+			continue
+		}
+		if until := time.Until(passage.PublishedAt); until < wait {
+			wait = until
+		}
+	}
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return wait
+}
+
+// PublishDue 发布所有发布时间不晚于 now 的定时文章，返回实际发布的文章
+func (s *PublishScheduler) PublishDue(now time.Time) ([]models.Passage, error) {
+	pending, err := s.repo.GetPendingScheduled()
+	if err != nil {
+		return nil, err
+	}
+
+	due := make(map[int]models.Passage)
+	ids := make([]int, 0, len(pending))
+	for _, passage := range pending {
+		if passage.PublishedAt.IsZero() || passage.PublishedAt.After(now) {
+			continue
+		}
+		due[passage.ID] = passage
+		ids = append(ids, passage.ID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	publishedIDs, err := s.repo.PublishScheduled(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	published := make([]models.Passage, 0, len(publishedIDs))
+	syncService := NewSyncService(s.repo)
+	for _, id := range publishedIDs {
+		passage := due[id]
+		passage.Status = "published"
+		passage.IsScheduled = false
+		published = append(published, passage)
+
+		late := now.Sub(passage.PublishedAt).Round(time.Second)
+		log.Printf("[Scheduler] 已发布定时文章: %s (ID: %d, 计划时间: %s, 延迟: %s)",
+			passage.Title, passage.ID, passage.PublishedAt.Format("2006-01-02 15:04:05"), late)
+
+		// 文件带有头部元数据时同步发布状态
+		if err := syncService.RewriteFrontMatter(&passage); err != nil {
+			log.Printf("[Scheduler] 更新文件头部元数据失败 (ID: %d): %v", passage.ID, err)
+		}
+
+		// 指向该文章的双链随发布生效
+		if err := UpdatePassageLinks(&passage); err != nil {
+			log.Printf("[Scheduler] 更新双链失败 (ID: %d): %v", passage.ID, err)
+		}
+
+		s.emitPublished(passage)
+	}
+
+	return published, nil
+}
+
+// emitPublished 配置了 Kafka 时发送 article.published 事件
+func (s *PublishScheduler) emitPublished(passage models.Passage) {
+	if kafka.GetAsyncProducer() == nil {
+		return
+	}
+	if err := kafka.PublishArticleEventAsync(context.Background(), "article.published", passage.ID, passage.Title); err != nil {
+		log.Printf("[Scheduler] 发送 article.published 事件失败 (ID: %d): %v", passage.ID, err)
+	}
+}
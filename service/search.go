@@ -0,0 +1,235 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"sync"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/search"
+)
+
+// SearchService 文章全文检索服务
+// 启动时从数据库构建内存索引，之后通过仓库的变更通知记录变化的文章，
+// 在下一次检索前增量刷新，避免在写操作路径上做额外的查询
+type SearchService struct {
+	index       *search.Index
+	passageRepo repositories.PassageRepository
+	tagRepo     repositories.TagRepository
+	passageTags repositories.PassageTagRepository
+
+	mu      sync.Mutex   // 保护 dirty 与 rebuild
+	dirty   map[int]bool // 待刷新的文章ID
+	rebuild bool         // 是否需要整体重建
+
+	refreshMu sync.Mutex // 保证同一时间只有一个刷新
+}
+
+var (
+	searchService     *SearchService
+	searchServiceOnce sync.Once
+)
+
+// InitSearchService 初始化全局检索服务并构建索引
+func InitSearchService() (*SearchService, error) {
+	var err error
+	searchServiceOnce.Do(func() {
+		searchService = NewSearchService(db.GetPassageRepository(), db.GetTagRepository(), db.GetPassageTagRepository())
+		repositories.OnPassageChange(searchService.MarkDirty)
+		err = searchService.Rebuild()
+	})
+	return searchService, err
+}
+
+// GetSearchService 获取全局检索服务，未初始化时返回 nil
+func GetSearchService() *SearchService {
+	return searchService
+}
+
+// NewSearchService 创建检索服务
+func NewSearchService(passageRepo repositories.PassageRepository, tagRepo repositories.TagRepository,
+	passageTags repositories.PassageTagRepository) *SearchService {
+	return &SearchService{
+		index:       search.NewIndex(),
+		passageRepo: passageRepo,
+		tagRepo:     tagRepo,
+		passageTags: passageTags,
+		dirty:       make(map[int]bool),
+	}
+}
+
+// Len 返回已索引的文章数
+func (s *SearchService) Len() int {
+	s.mu.Lock()
+	index := s.index
+	s.mu.Unlock()
+	return index.Len()
+}
+
+// MarkDirty 标记文章需要重新索引，passageID 为 0 时在下一次检索前整体重建
+func (s *SearchService) MarkDirty(passageID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if passageID == 0 {
+		s.rebuild = true
+		return
+	}
+	s.dirty[passageID] = true
+}
+
+// Rebuild 从数据库重建整个索引
+func (s *SearchService) Rebuild() error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	// 先清空待刷新列表，重建期间发生的变化会在下一次刷新时处理
+	s.mu.Lock()
+	s.dirty = make(map[int]bool)
+	s.rebuild = false
+	s.mu.Unlock()
+
+	total, err := s.passageRepo.Count()
+	if err != nil {
+		return fmt.Errorf("统计文章数量失败: %w", err)
+	}
+	passages, err := s.passageRepo.GetAll(total, 0)
+	if err != nil {
+		return fmt.Errorf("获取文章失败: %w", err)
+	}
+
+	tagNames, err := s.tagNames()
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, len(passages))
+	for i, passage := range passages {
+		ids[i] = passage.ID
+	}
+	tagIDsByPassage, err := s.passageTags.GetTagIDsByPassageIDs(ids)
+	if err != nil {
+		return fmt.Errorf("获取文章标签失败: %w", err)
+	}
+
+	index := search.NewIndex()
+	for i := range passages {
+		if passages[i].Status == "deleted" {
+			continue
+		}
+		index.Add(searchDocument(&passages[i], resolveTagNames(tagIDsByPassage[passages[i].ID], tagNames)))
+	}
+
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+	return nil
+}
+
+// refresh 重新索引所有被标记的文章
+func (s *SearchService) refresh() error {
+	s.mu.Lock()
+	rebuild := s.rebuild
+	dirty := s.dirty
+	if !rebuild && len(dirty) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	if rebuild {
+		return s.Rebuild()
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	s.mu.Lock()
+	dirty = s.dirty
+	s.dirty = make(map[int]bool)
+	index := s.index
+	s.mu.Unlock()
+
+	if len(dirty) == 0 {
+		return nil
+	}
+
+	tagNames, err := s.tagNames()
+	if err != nil {
+		return err
+	}
+
+	for id := range dirty {
+		passage, err := s.passageRepo.GetByID(id)
+		if err != nil {
+			// 刷新失败的文章留到下一次重试
+			s.MarkDirty(id)
+			return fmt.Errorf("获取文章失败 (ID: %d): %w", id, err)
+		}
+		if passage == nil || passage.Status == "deleted" {
+			index.Remove(id)
+			continue
+		}
+
+		tagIDs, err := s.passageTags.GetTagIDsByPassageID(id)
+		if err != nil {
+			s.MarkDirty(id)
+			return fmt.Errorf("获取文章标签失败 (ID: %d): %w", id, err)
+		}
+		index.Add(searchDocument(passage, resolveTagNames(tagIDs, tagNames)))
+	}
+	return nil
+}
+
+// Search 执行检索，检索前先刷新变化的文章
+func (s *SearchService) Search(q search.Query) ([]search.Hit, int) {
+	if err := s.refresh(); err != nil {
+		log.Printf("Warning: 刷新搜索索引失败: %v", err)
+	}
+
+	s.mu.Lock()
+	index := s.index
+	s.mu.Unlock()
+	return index.Search(q)
+}
+
+// tagNames 获取标签ID到名称的映射
+func (s *SearchService) tagNames() (map[int]string, error) {
+	tags, err := s.tagRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("获取标签失败: %w", err)
+	}
+	names := make(map[int]string, len(tags))
+	for _, tag := range tags {
+		names[tag.ID] = tag.Name
+	}
+	return names, nil
+}
+
+// resolveTagNames 将标签ID转换为名称，忽略不存在的标签
+func resolveTagNames(tagIDs []int, names map[int]string) []string {
+	result := make([]string, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		if name, ok := names[id]; ok {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// searchDocument 将文章转换为索引文档，正文使用渲染后 HTML 的纯文本
+func searchDocument(passage *models.Passage, tags []string) *search.Document {
+	return &search.Document{
+		ID:         passage.ID,
+		Title:      passage.Title,
+		Summary:    passage.Summary,
+		Body:       html.UnescapeString(removeHTMLTags(passage.Content)),
+		Category:   passage.Category,
+		Tags:       tags,
+		Status:     passage.Status,
+		Visibility: passage.Visibility,
+		CreatedAt:  passage.CreatedAt,
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/frontmatter"
+	"myblog-gogogo/pkg/ogimage"
+	"myblog-gogogo/pkg/seo"
+	"myblog-gogogo/service/settings"
+)
+
+// GetPassageMeta 获取文章的分享元数据，未设置时返回空的元数据
+func GetPassageMeta(passageID int) (*models.PassageMeta, error) {
+	meta, err := db.GetPassageMetaRepository().Get(passageID)
+	if err != nil {
+		return nil, fmt.Errorf("获取文章分享元数据失败: %w", err)
+	}
+	if meta == nil {
+		meta = &models.PassageMeta{PassageID: passageID}
+	}
+	return meta, nil
+}
+
+// SetPassageMeta 保存文章的分享元数据，字段全部为空时删除记录
+func SetPassageMeta(passageID int, title, description, image string) error {
+	meta := &models.PassageMeta{
+		PassageID:   passageID,
+		Title:       strings.TrimSpace(title),
+		Description: strings.TrimSpace(description),
+		Image:       strings.TrimSpace(image),
+	}
+	repo := db.GetPassageMetaRepository()
+	if meta.Title == "" && meta.Description == "" && meta.Image == "" {
+		return repo.Delete(passageID)
+	}
+	return repo.Set(meta)
+}
+
+// syncPassageMeta 按头部元数据设置文章的分享元数据，头部没有相关字段时保留后台设置的值
+func syncPassageMeta(passageID int, fm *frontmatter.FrontMatter) error {
+	if fm == nil || (fm.MetaTitle == "" && fm.MetaDescription == "" && fm.Image == "") {
+		return nil
+	}
+	return SetPassageMeta(passageID, fm.MetaTitle, fm.MetaDescription, fm.Image)
+}
+
+// SiteMeta 按模板设置生成站点级别的分享元数据，baseURL 为不带结尾斜杠的站点根地址
+func SiteMeta(template *settings.TemplateSettings, baseURL string) seo.Site {
+	name := template.Foodes
+	if name == "" {
+		name = template.Name
+	}
+	description := template.SiteDescription
+	if description == "" {
+		description = template.Greting
+	}
+	image := template.SiteImage
+	if image == "" {
+		image = template.GlobalAvatar
+	}
+	return seo.Site{
+		Name:        name,
+		Description: seo.Description(description, seo.DescriptionLength),
+		BaseURL:     baseURL,
+		Image:       image,
+		TwitterSite: template.TwitterSite,
+		Locale:      "zh_CN",
+	}
+}
+
+// PassagePublishedAt 文章的发布时间，未记录发布时间的旧文章使用创建时间
+func PassagePublishedAt(passage *models.Passage) time.Time {
+	if !passage.PublishedAt.IsZero() {
+		return passage.PublishedAt
+	}
+	return passage.CreatedAt
+}
+
+// PassagePageMeta 生成文章页面的分享元数据
+// 后台或头部设置的标题、描述与图片优先，其次使用文章标题、摘要与正文第一张图片，都没有时使用生成的分享卡片；
+// hidden 为 true 时（受密码保护且未解锁）不使用摘要与正文，避免通过分享卡片泄露内容。
+// 未发布、私密与受保护的文章不允许搜索引擎收录
+func PassagePageMeta(passage *models.Passage, title, content string, hidden bool) seo.Page {
+	page := seo.Page{
+		Type:  seo.TypeArticle,
+		Title: title,
+	}
+	if !hidden {
+		page.Description = seo.Description(content, seo.DescriptionLength)
+		page.Image = seo.FirstImage(content)
+	}
+	if passage == nil {
+		page.Breadcrumbs = []seo.Crumb{{Name: "首页", URL: "/"}, {Name: title}}
+		return page
+	}
+
+	if passage.Title != "" {
+		page.Title = passage.Title
+	}
+	if !hidden && passage.Summary != "" {
+		page.Description = seo.Description(passage.Summary, seo.DescriptionLength)
+	}
+	page.Path = PassagePath(passage)
+	page.Author = passage.Author
+	page.Section = passage.Category
+	page.PublishedAt = PassagePublishedAt(passage)
+	page.ModifiedAt = passage.UpdatedAt
+	page.NoIndex = !IsPublicPassage(passage)
+	if tags, err := NewSyncService(db.GetPassageRepository()).GetPassageTagNames(passage.ID); err == nil {
+		page.Tags = tags
+	}
+
+	if meta, err := GetPassageMeta(passage.ID); err == nil {
+		if meta.Title != "" {
+			page.Title = meta.Title
+		}
+		if meta.Description != "" {
+			page.Description = meta.Description
+		}
+		if meta.Image != "" {
+			page.Image = meta.Image
+		}
+	}
+
+	// 没有封面与正文图片时使用生成的分享卡片
+	if page.Image == "" && OGImageAvailable(passage) {
+		page.Image = OGImagePath(passage.ID)
+		page.ImageWidth, page.ImageHeight = ogimage.Width, ogimage.Height
+	}
+
+	page.Breadcrumbs = []seo.Crumb{{Name: "首页", URL: "/"}}
+	if passage.Category != "" {
+		page.Breadcrumbs = append(page.Breadcrumbs, seo.Crumb{
+			Name: passage.Category,
+			URL:  "/category/" + url.PathEscape(passage.Category),
+		})
+	}
+	page.Breadcrumbs = append(page.Breadcrumbs, seo.Crumb{Name: page.Title})
+	return page
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/slug"
+)
+
+// SeriesPathPrefix 系列页面前缀
+const SeriesPathPrefix = "/series/"
+
+// SeriesEntry 系列目录中的一篇文章
+type SeriesEntry struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Part    int    `json:"part"` // 在系列中的序号，从 1 开始
+	Current bool   `json:"current,omitempty"`
+}
+
+// SeriesNav 文章所在系列的目录与上一篇、下一篇
+type SeriesNav struct {
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Slug        string        `json:"slug"`
+	Description string        `json:"description"`
+	URL         string        `json:"url"`
+	Part        int           `json:"part"`  // 当前文章的序号
+	Total       int           `json:"total"` // 系列中可见的文章数
+	Passages    []SeriesEntry `json:"passages"`
+	Prev        *SeriesEntry  `json:"prev"`
+	Next        *SeriesEntry  `json:"next"`
+}
+
+// SeriesPath 系列页面的路径
+func SeriesPath(series *models.Series) string {
+	return SeriesPathPrefix + url.PathEscape(series.Slug)
+}
+
+// IsPublicPassage 判断文章是否已发布且公开，与系列、反向链接中的可见条件一致
+func IsPublicPassage(passage *models.Passage) bool {
+	if passage.Status != "published" {
+		return false
+	}
+	return passage.Visibility == "" || passage.Visibility == "public"
+}
+
+// SeriesSlug 为系列名称生成别名，无法完整转写为 ASCII 时保留原文字母
+func SeriesSlug(name string) string {
+	s, lossy := slug.Make(name)
+	if s == "" || lossy {
+		s = slug.Normalize(name)
+	}
+	return s
+}
+
+// uniqueSeriesSlug 别名已被其他系列使用时追加序号
+func uniqueSeriesSlug(s string, exceptID int) (string, error) {
+	if s == "" {
+		s = "series"
+	}
+	repo := db.GetSeriesRepository()
+	candidate := s
+	for n := 2; ; n++ {
+		existing, err := repo.GetBySlug(candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil || existing.ID == exceptID {
+			return candidate, nil
+		}
+		candidate = slug.WithSuffix(s, n)
+	}
+}
+
+// CreateSeries 创建系列，别名为空时根据名称生成，与已有系列冲突时追加序号
+func CreateSeries(series *models.Series) error {
+	series.Name = strings.TrimSpace(series.Name)
+	if series.Name == "" {
+		return fmt.Errorf("系列名称不能为空")
+	}
+	s := slug.Normalize(series.Slug)
+	if s == "" {
+		s = SeriesSlug(series.Name)
+	}
+	s, err := uniqueSeriesSlug(s, 0)
+	if err != nil {
+		return err
+	}
+	series.Slug = s
+	return db.GetSeriesRepository().Create(series)
+}
+
+// UpdateSeries 更新系列的名称、别名与简介，别名为空时保留原值
+func UpdateSeries(series *models.Series) error {
+	series.Name = strings.TrimSpace(series.Name)
+	if series.Name == "" {
+		return fmt.Errorf("系列名称不能为空")
+	}
+	if series.Slug != "" {
+		s, err := uniqueSeriesSlug(slug.Normalize(series.Slug), series.ID)
+		if err != nil {
+			return err
+		}
+		series.Slug = s
+	}
+	return db.GetSeriesRepository().Update(series)
+}
+
+// findOrCreateSeries 按名称或别名查找系列，不存在时创建
+func findOrCreateSeries(name string) (*models.Series, error) {
+	repo := db.GetSeriesRepository()
+	series, err := repo.GetByName(name)
+	if err != nil || series != nil {
+		return series, err
+	}
+	if series, err = repo.GetBySlug(name); err != nil || series != nil {
+		return series, err
+	}
+	series = &models.Series{Name: name}
+	if err := CreateSeries(series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// SetPassageSeries 设置文章所属的系列，name 为空时将文章移出系列，系列不存在时自动创建
+// part 为文章在系列中的顺序，<= 0 时保留原有顺序，新加入的文章排在最后
+func SetPassageSeries(passageID int, name string, part int) error {
+	repo := db.GetSeriesRepository()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return repo.RemoveMembership(passageID)
+	}
+
+	series, err := findOrCreateSeries(name)
+	if err != nil {
+		return fmt.Errorf("查找系列失败: %w", err)
+	}
+
+	current, err := repo.GetMembership(passageID)
+	if err != nil {
+		return err
+	}
+	if part <= 0 {
+		if current != nil && current.SeriesID == series.ID {
+			return nil
+		}
+		max, err := repo.MaxPosition(series.ID)
+		if err != nil {
+			return err
+		}
+		part = max + 1
+	}
+	return repo.SetMembership(&models.SeriesPassage{SeriesID: series.ID, PassageID: passageID, Position: part})
+}
+
+// GetPassageSeries 获取文章所属的系列与成员信息，不属于任何系列时返回 nil
+func GetPassageSeries(passageID int) (*models.Series, *models.SeriesPassage, error) {
+	repo := db.GetSeriesRepository()
+	member, err := repo.GetMembership(passageID)
+	if err != nil || member == nil {
+		return nil, nil, err
+	}
+	series, err := repo.GetByID(member.SeriesID)
+	if err != nil || series == nil {
+		return nil, nil, err
+	}
+	return series, member, nil
+}
+
+// SeriesEntries 系列中可见的文章，按顺序编号
+// currentID 对应的文章即使未公开也会列出并标记为当前文章，为 0 时只列出公开文章
+func SeriesEntries(series *models.Series, currentID int) ([]SeriesEntry, error) {
+	passages, err := db.GetSeriesRepository().GetPassages(series.ID, currentID == 0)
+	if err != nil {
+		return nil, err
+	}
+	entries := []SeriesEntry{}
+	for i := range passages {
+		passage := &passages[i]
+		if passage.ID != currentID && !IsPublicPassage(passage) {
+			continue
+		}
+		entries = append(entries, SeriesEntry{
+			ID:      passage.ID,
+			Title:   passage.Title,
+			URL:     PassagePath(passage),
+			Part:    len(entries) + 1,
+			Current: passage.ID == currentID,
+		})
+	}
+	return entries, nil
+}
+
+// GetSeriesNav 获取文章所在系列的目录与上一篇、下一篇，文章不属于任何系列时返回 nil
+func GetSeriesNav(passageID int) (*SeriesNav, error) {
+	series, _, err := GetPassageSeries(passageID)
+	if err != nil || series == nil {
+		return nil, err
+	}
+	entries, err := SeriesEntries(series, passageID)
+	if err != nil {
+		return nil, err
+	}
+
+	nav := &SeriesNav{
+		ID:          series.ID,
+		Name:        series.Name,
+		Slug:        series.Slug,
+		Description: series.Description,
+		URL:         SeriesPath(series),
+		Total:       len(entries),
+		Passages:    entries,
+	}
+	for i := range entries {
+		if !entries[i].Current {
+			continue
+		}
+		nav.Part = entries[i].Part
+		if i > 0 {
+			nav.Prev = &entries[i-1]
+		}
+		if i+1 < len(entries) {
+			nav.Next = &entries[i+1]
+		}
+	}
+	return nav, nil
+}
+
+// SeriesSummary 系列列表中的一项
+type SeriesSummary struct {
+	models.Series
+	URL   string `json:"url"`
+	Count int    `json:"count"` // 系列中的文章数，公开列表中只计算公开文章
+}
+
+// ListSeries 获取系列列表，publicOnly 为 true 时只计算公开文章并跳过没有公开文章的系列
+func ListSeries(publicOnly bool) ([]SeriesSummary, error) {
+	repo := db.GetSeriesRepository()
+	all, err := repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	counts, err := repo.CountPassages(publicOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []SeriesSummary{}
+	for i := range all {
+		count := counts[all[i].ID]
+		if publicOnly && count == 0 {
+			continue
+		}
+		list = append(list, SeriesSummary{Series: all[i], URL: SeriesPath(&all[i]), Count: count})
+	}
+	return list, nil
+}
+
+// SeriesDetail 系列及其公开文章
+type SeriesDetail struct {
+	models.Series
+	URL      string        `json:"url"`
+	Passages []SeriesEntry `json:"passages"`
+}
+
+// GetSeriesDetail 按别名获取系列及其公开文章，系列不存在或没有公开文章时返回 nil
+func GetSeriesDetail(seriesSlug string) (*SeriesDetail, error) {
+	series, err := db.GetSeriesRepository().GetBySlug(seriesSlug)
+	if err != nil || series == nil {
+		return nil, err
+	}
+	entries, err := SeriesEntries(series, 0)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &SeriesDetail{Series: *series, URL: SeriesPath(series), Passages: entries}, nil
+}
@@ -0,0 +1,61 @@
+package settings
+
+import (
+	"log"
+	"strings"
+
+	"myblog-gogogo/db"
+)
+
+// DefaultIframeWhitelist 默认允许编辑嵌入的 iframe 地址前缀
+var DefaultIframeWhitelist = []string{
+	"https://player.bilibili.com/",
+	"https://www.youtube.com/embed/",
+	"https://www.youtube-nocookie.com/embed/",
+}
+
+// HTMLSettings 文章原始 HTML 的过滤设置
+type HTMLSettings struct {
+	ScriptWhitelist []string `json:"script_whitelist"` // 管理员文章允许的外部脚本地址前缀
+	IframeWhitelist []string `json:"iframe_whitelist"` // 编辑文章允许的 iframe 地址前缀
+}
+
+// GetHTML 获取文章 HTML 过滤设置
+func GetHTML() (*HTMLSettings, error) {
+	settings := HTMLSettings{
+		ScriptWhitelist: []string{},
+		IframeWhitelist: DefaultIframeWhitelist,
+	}
+	if db.GetDB() == nil {
+		return &settings, nil
+	}
+
+	settingsMap, err := db.GetSettingRepository().GetByKeys([]string{"html_script_whitelist", "html_iframe_whitelist"})
+	if err != nil {
+		log.Printf("Failed to get html settings: %v", err)
+		return &settings, nil
+	}
+	if setting := settingsMap["html_script_whitelist"]; setting != nil {
+		settings.ScriptWhitelist = prefixList(setting.Value)
+	}
+	if setting := settingsMap["html_iframe_whitelist"]; setting != nil {
+		settings.IframeWhitelist = prefixList(setting.Value)
+	}
+	return &settings, nil
+}
+
+// prefixList 解析地址前缀列表，去掉空项，不完整的前缀（没有路径）补上 /，避免 https://a.com 匹配 https://a.com.evil.test
+func prefixList(value string) []string {
+	var list []string
+	for _, prefix := range stringToStringArray(value) {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			continue
+		}
+		if i := strings.Index(prefix, "://"); i >= 0 && !strings.Contains(prefix[i+3:], "/") {
+			prefix += "/"
+		}
+		list = append(list, prefix)
+	}
+	return list
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
 )
 
 // TemplateSettings 模板设置结构
@@ -42,6 +43,14 @@ type TemplateSettings struct {
 	AttachmentDefaultVisibility string `json:"attachment_default_visibility"`
 	AttachmentMaxSize          int64  `json:"attachment_max_size"`
 	AttachmentAllowedTypes     string `json:"attachment_allowed_types"`
+	SiteURL                    string `json:"site_url"`          // 站点根地址，用于生成订阅源等处的绝对链接
+	FeedFullContent            bool   `json:"feed_full_content"` // 订阅源输出全文还是摘要
+	FeedItemCount              int    `json:"feed_item_count"`   // 订阅源条目数
+	RelatedPassageCount        int    `json:"related_passage_count"` // 文章末尾显示的相关文章数，0 表示不显示
+	RobotsTxt                  string `json:"robots_txt"`        // robots.txt 内容
+	SiteDescription            string `json:"site_description"`  // 分享卡片与搜索引擎使用的站点描述，为空时使用欢迎语
+	SiteImage                  string `json:"site_image"`        // 默认分享图片，为空时使用全局头像
+	TwitterSite                string `json:"twitter_site"`      // 站点的 Twitter 账号，如 @example
 }
 
 // GetTemplate 获取模板设置
@@ -81,6 +90,14 @@ func GetTemplate() (*TemplateSettings, error) {
 		AttachmentDefaultVisibility: "public",
 		AttachmentMaxSize:          500 * 1024 * 1024,
 		AttachmentAllowedTypes:     "jpg,jpeg,png,gif,mp4,mp3,pdf,doc,docx,xls,xlsx,ppt,pptx,zip,rar,7z,tar,gz",
+		SiteURL:                    "",
+		FeedFullContent:            false,
+		FeedItemCount:              20,
+		RelatedPassageCount:        5,
+		RobotsTxt:                  models.DefaultRobotsTxt,
+		SiteDescription:            "",
+		SiteImage:                  "",
+		TwitterSite:                "",
 	}
 
 	keys := []string{
@@ -97,6 +114,9 @@ func GetTemplate() (*TemplateSettings, error) {
 		"sponsor_description", "sponsor_button_text",
 		"global_avatar",
 		"attachment_default_visibility", "attachment_max_size", "attachment_allowed_types",
+		"site_url", "feed_full_content", "feed_item_count", "robots_txt",
+		"related_passage_count",
+		"site_description", "site_image", "twitter_site",
 	}
 
 	// 使用批量查询
@@ -173,6 +193,26 @@ func GetTemplate() (*TemplateSettings, error) {
 				settings.AttachmentMaxSize = stringToInt(setting.Value)
 			case "attachment_allowed_types":
 				settings.AttachmentAllowedTypes = setting.Value
+			case "site_url":
+				settings.SiteURL = setting.Value
+			case "feed_full_content":
+				settings.FeedFullContent = stringToBool(setting.Value)
+			case "feed_item_count":
+				if count := int(stringToInt(setting.Value)); count > 0 {
+					settings.FeedItemCount = count
+				}
+			case "robots_txt":
+				settings.RobotsTxt = setting.Value
+			case "related_passage_count":
+				if count := int(stringToInt(setting.Value)); count >= 0 {
+					settings.RelatedPassageCount = count
+				}
+			case "site_description":
+				settings.SiteDescription = setting.Value
+			case "site_image":
+				settings.SiteImage = setting.Value
+			case "twitter_site":
+				settings.TwitterSite = setting.Value
 			}
 		}
 	}
@@ -217,6 +257,14 @@ func UpdateTemplate(settings *TemplateSettings) error {
 		"attachment_default_visibility": settings.AttachmentDefaultVisibility,
 		"attachment_max_size":           strconv.FormatInt(settings.AttachmentMaxSize, 10),
 		"attachment_allowed_types":      settings.AttachmentAllowedTypes,
+		"site_url":                      settings.SiteURL,
+		"feed_full_content":             boolToString(settings.FeedFullContent),
+		"feed_item_count":               strconv.Itoa(settings.FeedItemCount),
+		"robots_txt":                    settings.RobotsTxt,
+		"related_passage_count":         strconv.Itoa(settings.RelatedPassageCount),
+		"site_description":              settings.SiteDescription,
+		"site_image":                    settings.SiteImage,
+		"twitter_site":                  settings.TwitterSite,
 	}
 
 	for key, value := range updates {
@@ -262,6 +310,14 @@ func UpdateTemplatePartial(updates map[string]interface{}) error {
 		"sponsor_description":       "sponsor_description",
 		"sponsor_button_text":       "sponsor_button_text",
 		"global_avatar":             "global_avatar",
+		"site_url":                  "site_url",
+		"feed_full_content":         "feed_full_content",
+		"feed_item_count":           "feed_item_count",
+		"robots_txt":                "robots_txt",
+		"related_passage_count":     "related_passage_count",
+		"site_description":          "site_description",
+		"site_image":                "site_image",
+		"twitter_site":              "twitter_site",
 	}
 
 	for jsonField, value := range updates {
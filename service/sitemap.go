@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/sitemap"
+)
+
+// sitemapBatchSize 分批读取文章的批大小
+const sitemapBatchSize = 500
+
+// SitemapService sitemap 生成服务，只收录已发布的公开文章及其分类、标签页面
+type SitemapService struct {
+	passageRepo    repositories.PassageRepository
+	tagRepo        repositories.TagRepository
+	passageTagRepo repositories.PassageTagRepository
+}
+
+// NewSitemapService 创建 sitemap 生成服务
+func NewSitemapService() *SitemapService {
+	return &SitemapService{
+		passageRepo:    db.GetPassageRepository(),
+		tagRepo:        db.GetTagRepository(),
+		passageTagRepo: db.GetPassageTagRepository(),
+	}
+}
+
+// URLs 生成全站需要收录的 URL，baseURL 为不带结尾斜杠的站点根地址
+// 分类和标签页面的 lastmod 取其下文章的最新更新时间
+func (s *SitemapService) URLs(baseURL string) ([]sitemap.URL, error) {
+	var passages []models.Passage
+	for offset := 0; ; offset += sitemapBatchSize {
+		batch, err := s.passageRepo.GetPublic("", 0, sitemapBatchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("获取文章失败: %w", err)
+		}
+		passages = append(passages, batch...)
+		if len(batch) < sitemapBatchSize {
+			break
+		}
+	}
+
+	tags, err := s.tagRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("获取标签失败: %w", err)
+	}
+	tagNames := make(map[int]string, len(tags))
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+	}
+
+	var latest time.Time
+	categories := make(map[string]time.Time)
+	tagged := make(map[string]time.Time)
+	passageURLs := make([]sitemap.URL, 0, len(passages))
+
+	for start := 0; start < len(passages); start += sitemapBatchSize {
+		end := start + sitemapBatchSize
+		if end > len(passages) {
+			end = len(passages)
+		}
+		batch := passages[start:end]
+
+		ids := make([]int, len(batch))
+		for i, passage := range batch {
+			ids[i] = passage.ID
+		}
+		tagIDs, err := s.passageTagRepo.GetTagIDsByPassageIDs(ids)
+		if err != nil {
+			return nil, fmt.Errorf("获取文章标签失败: %w", err)
+		}
+
+		for i := range batch {
+			passage := &batch[i]
+			updated := passage.UpdatedAt
+			passageURLs = append(passageURLs, sitemap.URL{Loc: baseURL + PassagePath(passage), LastMod: updated})
+
+			if updated.After(latest) {
+				latest = updated
+			}
+			if passage.Category != "" && updated.After(categories[passage.Category]) {
+				categories[passage.Category] = updated
+			}
+			for _, name := range resolveTagNames(tagIDs[passage.ID], tagNames) {
+				if updated.After(tagged[name]) {
+					tagged[name] = updated
+				}
+			}
+		}
+	}
+
+	urls := []sitemap.URL{
+		{Loc: baseURL + "/", LastMod: latest},
+		{Loc: baseURL + "/collect", LastMod: latest},
+		{Loc: baseURL + "/about"},
+	}
+	urls = append(urls, passageURLs...)
+	urls = append(urls, taxonomyURLs(baseURL+"/category/", categories)...)
+	urls = append(urls, taxonomyURLs(baseURL+"/tag/", tagged)...)
+
+	// 有公开文章的系列
+	series, err := ListSeries(true)
+	if err != nil {
+		return nil, fmt.Errorf("获取系列失败: %w", err)
+	}
+	if len(series) > 0 {
+		urls = append(urls, sitemap.URL{Loc: baseURL + SeriesPathPrefix, LastMod: latest})
+	}
+	for i := range series {
+		urls = append(urls, sitemap.URL{Loc: baseURL + series[i].URL, LastMod: series[i].UpdatedAt})
+	}
+	return urls, nil
+}
+
+// taxonomyURLs 按名称排序生成分类或标签页面的 URL
+func taxonomyURLs(prefix string, lastMods map[string]time.Time) []sitemap.URL {
+	names := make([]string, 0, len(lastMods))
+	for name := range lastMods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	urls := make([]sitemap.URL, len(names))
+	for i, name := range names {
+		urls[i] = sitemap.URL{Loc: prefix + url.PathEscape(name), LastMod: lastMods[name]}
+	}
+	return urls
+}
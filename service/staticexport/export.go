@@ -0,0 +1,451 @@
+// Package staticexport 将站点导出为可以部署到任意静态托管的 HTML 镜像
+// 页面通过站点自身的路由在进程内渲染，前端依赖的 API 以 JSON 快照保存，
+// 由注入的 js/static-export.js 在浏览器中把 /api 请求映射到快照文件
+package staticexport
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/imagevariant"
+	"myblog-gogogo/service"
+)
+
+// shimScript 浏览器端的 API 快照映射脚本，导出到 js/static-export.js
+//
+//go:embed static-export.js
+var shimScript []byte
+
+// shimFile 映射脚本在镜像中的位置
+const shimFile = "js/static-export.js"
+
+// downloadsFile 附件 ID 与镜像中文件路径的对应关系，供映射脚本处理 /api/attachments/download 链接
+const downloadsFile = "api/attachments/download/index.json"
+
+// batchSize 分批读取文章与附件的批大小
+const batchSize = 500
+
+// feedFiles 站点与每个分类、标签导出的订阅源
+var feedFiles = []string{"feed.xml", "atom.xml", "feed.json"}
+
+// Options 导出选项
+type Options struct {
+	OutDir string // 输出目录
+	// BaseURL 订阅源与 sitemap 中使用的站点地址，模板设置中填写了站点地址时以设置为准
+	BaseURL string
+	// Assets 嵌入的模板文件系统，css 与 js 从其中的 template/css、template/js 复制
+	Assets fs.FS
+}
+
+// Result 导出结果
+type Result struct {
+	Pages    int      // 页面、订阅源与 sitemap 的数量
+	APIs     int      // API 快照数量
+	Assets   int      // 复制的静态资源数量
+	Warnings []string // 跳过的页面与资源
+}
+
+// Exporter 静态镜像导出器
+type Exporter struct {
+	handler http.Handler
+	opts    Options
+	host    string
+	scheme  string
+
+	// files 已知页面的路径（未转义）与其在镜像中的文件
+	files map[string]string
+	// downloads 公开附件的 ID 与其在镜像中的文件
+	downloads map[string]string
+	result    Result
+}
+
+// New 创建导出器，handler 为站点的路由（含认证中间件），以匿名访客的身份请求
+func New(handler http.Handler, opts Options) *Exporter {
+	e := &Exporter{
+		handler:   handler,
+		opts:      opts,
+		host:      "localhost",
+		files:     make(map[string]string),
+		downloads: make(map[string]string),
+	}
+	if u, err := url.Parse(strings.TrimRight(opts.BaseURL, "/")); err == nil && u.Host != "" {
+		e.host = u.Host
+		e.scheme = u.Scheme
+	}
+	return e
+}
+
+// Run 执行导出，单个页面或资源失败时记录警告并继续
+func (e *Exporter) Run() (*Result, error) {
+	if e.opts.OutDir == "" {
+		return nil, fmt.Errorf("未指定输出目录")
+	}
+	if err := os.MkdirAll(e.opts.OutDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	passages, err := publicPassages()
+	if err != nil {
+		return nil, err
+	}
+	pages, feeds, err := e.collectPages()
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := publicAttachments()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attachments {
+		e.downloads[strconv.Itoa(a.ID)] = filepath.ToSlash(filepath.Clean(a.FilePath))
+	}
+
+	// 先登记全部页面，渲染时才能知道哪些站内链接可以改写
+	for _, p := range pages {
+		e.files[p] = htmlFile(p)
+	}
+	for _, p := range feeds {
+		e.files[p] = strings.TrimPrefix(p, "/")
+	}
+
+	for _, p := range pages {
+		e.exportPage(p, true)
+	}
+	for _, p := range feeds {
+		e.exportPage(p, false)
+	}
+
+	e.exportAPIs(passages)
+	if err := e.writeJSON(downloadsFile, map[string]interface{}{"success": true, "data": e.downloads}); err != nil {
+		return nil, err
+	}
+
+	if err := e.copyAssets(attachments); err != nil {
+		return nil, err
+	}
+	if err := e.write(shimFile, shimScript); err != nil {
+		return nil, err
+	}
+	return &e.result, nil
+}
+
+// collectPages 列出需要导出的 HTML 页面与订阅源、sitemap 等其他文件
+// 页面与 sitemap 收录的范围一致：首页、归档、关于、公开文章及其分类、标签与系列
+func (e *Exporter) collectPages() (pages, others []string, err error) {
+	urls, err := service.NewSitemapService().URLs("")
+	if err != nil {
+		return nil, nil, err
+	}
+	pages = append(pages, "/passage")
+	for _, u := range urls {
+		p, err := url.PathUnescape(u.Loc)
+		if err != nil {
+			continue
+		}
+		pages = append(pages, p)
+		if strings.HasPrefix(p, "/category/") || strings.HasPrefix(p, "/tag/") {
+			for _, name := range feedFiles {
+				others = append(others, p+"/"+name)
+			}
+		}
+	}
+	for _, name := range feedFiles {
+		others = append(others, "/"+name)
+	}
+	others = append(others, "/sitemap.xml", "/robots.txt", "/favicon.ico")
+	return pages, others, nil
+}
+
+// exportPage 渲染一个页面并写入镜像，HTML 页面改写站内链接并注入映射脚本
+func (e *Exporter) exportPage(p string, isHTML bool) {
+	status, body := e.get(escapePath(p))
+	if status != http.StatusOK {
+		e.warn("页面 %s 返回 %d，已跳过", p, status)
+		return
+	}
+	file := e.files[p]
+	if isHTML {
+		body = []byte(e.rewriteHTML(file, string(body)))
+	}
+	if err := e.write(file, body); err != nil {
+		e.warn("写入 %s 失败: %v", file, err)
+		return
+	}
+	e.result.Pages++
+}
+
+// exportAPIs 保存页面脚本会请求的 API 响应
+func (e *Exporter) exportAPIs(passages []models.Passage) {
+	targets := []string{
+		"/api/settings/appearance",
+		"/api/settings/music",
+		"/api/music/playlist",
+		"/api/passages?limit=1000&offset=0",
+		"/api/archive",
+		"/api/tags",
+		"/api/categories",
+		"/api/about/main-cards",
+	}
+
+	categories := make(map[string]bool)
+	for _, passage := range passages {
+		id := strconv.Itoa(passage.ID)
+		targets = append(targets,
+			"/api/passages/"+id,
+			"/api/attachments?passage_id="+id,
+			"/api/comments?passage_id="+id,
+		)
+		if passage.Category != "" {
+			categories[passage.Category] = true
+		}
+	}
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		targets = append(targets, "/api/passages?"+url.Values{
+			"category": {name}, "limit": {"1000"}, "offset": {"0"},
+		}.Encode())
+	}
+
+	if series, err := service.ListSeries(true); err == nil && len(series) > 0 {
+		targets = append(targets, "/api/series")
+		for i := range series {
+			targets = append(targets, "/api/series/"+url.PathEscape(series[i].Slug))
+		}
+	}
+
+	for _, target := range targets {
+		body := e.exportAPI(target)
+		if target == "/api/about/main-cards" && body != nil {
+			for _, id := range dataIDs(body) {
+				e.exportAPI("/api/about/sub-cards?main_card_id=" + id)
+			}
+		}
+	}
+}
+
+// exportAPI 请求一个 API 并保存为快照，返回响应内容
+func (e *Exporter) exportAPI(target string) []byte {
+	status, body := e.get(target)
+	if status != http.StatusOK {
+		e.warn("接口 %s 返回 %d，已跳过", target, status)
+		return nil
+	}
+	file, err := SnapshotFile(target)
+	if err != nil {
+		e.warn("接口 %s: %v", target, err)
+		return nil
+	}
+	if err := e.write(file, body); err != nil {
+		e.warn("写入 %s 失败: %v", file, err)
+		return nil
+	}
+	e.result.APIs++
+	return body
+}
+
+// dataIDs 读取列表响应中各项的 ID，响应可以是数组或 {"data": [...]}
+func dataIDs(body []byte) []string {
+	var items []struct {
+		ID json.Number `json:"id"`
+	}
+	if err := json.Unmarshal(body, &items); err != nil {
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil || len(resp.Data) == 0 {
+			return nil
+		}
+		return dataIDs(resp.Data)
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.ID != "" {
+			ids = append(ids, item.ID.String())
+		}
+	}
+	return ids
+}
+
+// get 以匿名访客身份在进程内请求站点
+func (e *Exporter) get(target string) (int, []byte) {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Host = e.host
+	if e.scheme != "" {
+		req.Header.Set("X-Forwarded-Proto", e.scheme)
+	}
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.Bytes()
+}
+
+// copyAssets 复制样式、脚本、图片、音乐与公开附件
+// 私密与受保护的附件不会出现在镜像中
+func (e *Exporter) copyAssets(attachments []*models.Attachment) error {
+	if e.opts.Assets != nil {
+		for _, dir := range []string{"css", "js"} {
+			sub, err := fs.Sub(e.opts.Assets, "template/"+dir)
+			if err != nil {
+				return err
+			}
+			if err := e.copyTree(sub, dir); err != nil {
+				return fmt.Errorf("复制 %s 失败: %w", dir, err)
+			}
+		}
+	}
+	for _, dir := range []string{"img", "music"} {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := e.copyTree(os.DirFS(dir), dir); err != nil {
+			return fmt.Errorf("复制 %s 失败: %w", dir, err)
+		}
+	}
+
+	for _, a := range attachments {
+		files := []string{a.FilePath}
+		if imagevariant.Supported(a.FilePath) {
+			if _, _, variants, err := imagevariant.Available(a.FilePath); err == nil {
+				for _, v := range variants {
+					files = append(files, v.Path)
+				}
+			}
+		}
+		for _, file := range files {
+			name := filepath.ToSlash(filepath.Clean(file))
+			if err := e.copyFile(os.DirFS("."), name, name); err != nil {
+				e.warn("复制附件 %s 失败: %v", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// copyTree 复制目录下的全部文件，样式表中的绝对地址改写为相对地址
+func (e *Exporter) copyTree(src fs.FS, dst string) error {
+	return fs.WalkDir(src, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		return e.copyFile(src, p, path.Join(dst, p))
+	})
+}
+
+// copyFile 复制单个文件
+func (e *Exporter) copyFile(src fs.FS, name, dst string) error {
+	if strings.EqualFold(path.Ext(name), ".css") {
+		data, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		if err := e.write(dst, []byte(e.rewriteCSS(dst, string(data)))); err != nil {
+			return err
+		}
+		e.result.Assets++
+		return nil
+	}
+
+	in, err := src.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	target := filepath.Join(e.opts.OutDir, filepath.FromSlash(dst))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	e.result.Assets++
+	return nil
+}
+
+// write 写入镜像中的文件，name 使用 / 分隔
+func (e *Exporter) write(name string, data []byte) error {
+	target := filepath.Join(e.opts.OutDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, 0644)
+}
+
+// writeJSON 以 JSON 写入镜像中的文件
+func (e *Exporter) writeJSON(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return e.write(name, data)
+}
+
+// warn 记录警告
+func (e *Exporter) warn(format string, args ...interface{}) {
+	e.result.Warnings = append(e.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// publicPassages 获取全部已发布的公开文章
+func publicPassages() ([]models.Passage, error) {
+	repo := db.GetPassageRepository()
+	var passages []models.Passage
+	for offset := 0; ; offset += batchSize {
+		batch, err := repo.GetPublic("", 0, batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("获取文章失败: %w", err)
+		}
+		passages = append(passages, batch...)
+		if len(batch) < batchSize {
+			return passages, nil
+		}
+	}
+}
+
+// publicAttachments 获取全部公开附件，只包含位于 attachments 目录、可以通过 /attachments/ 访问的文件
+func publicAttachments() ([]*models.Attachment, error) {
+	repo := db.GetAttachmentRepository()
+	var attachments []*models.Attachment
+	for offset := 0; ; offset += batchSize {
+		batch, _, err := repo.GetAll(batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("获取附件失败: %w", err)
+		}
+		for _, a := range batch {
+			if a.Visibility != "" && a.Visibility != "public" {
+				continue
+			}
+			if !strings.HasPrefix(filepath.ToSlash(filepath.Clean(a.FilePath)), "attachments/") {
+				continue
+			}
+			attachments = append(attachments, a)
+		}
+		if len(batch) < batchSize {
+			return attachments, nil
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package staticexport
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// assetPrefixes 原样复制到镜像中的静态资源目录
+var assetPrefixes = []string{"/css/", "/js/", "/img/", "/music/", "/attachments/"}
+
+var (
+	// scriptRegex 脚本块，块内的字符串不是链接，只改写开始标签中的 src
+	scriptRegex = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script>`)
+	// attrRegex 含有地址的 HTML 属性
+	attrRegex = regexp.MustCompile(`(?i)(\s(?:href|src|action|poster)=)("[^"]*"|'[^']*')`)
+	// srcsetRegex 响应式图片的候选地址列表
+	srcsetRegex = regexp.MustCompile(`(?i)(\ssrcset=)("[^"]*"|'[^']*')`)
+	// cssURLRegex 样式中的 url(...)
+	cssURLRegex = regexp.MustCompile(`url\((\s*["']?)([^"')]+)(["']?\s*)\)`)
+	// headRegex 注入映射脚本的位置
+	headRegex = regexp.MustCompile(`(?i)<head\b[^>]*>`)
+)
+
+// htmlFile 页面路径对应的镜像文件，例如 /passage/a 对应 passage/a/index.html
+func htmlFile(p string) string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return "index.html"
+	}
+	return p + "/index.html"
+}
+
+// escapePath 逐段转义路径
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// SnapshotFile API 地址对应的快照文件，需要与 static-export.js 中的 snapshotPath 保持一致
+// 不带参数时为 api/xxx/index.json，带参数时为 api/xxx/q-<参数的 FNV-1a 哈希>.json
+// 参数按名称、值排序后以解码后的 k=v&k=v 计算哈希
+func SnapshotFile(target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	dir := strings.Trim(path.Clean(u.Path), "/")
+	if !strings.HasPrefix(dir, "api/") {
+		return "", fmt.Errorf("不是 API 地址")
+	}
+
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", err
+	}
+	var pairs []string
+	for key, vs := range values {
+		for _, v := range vs {
+			pairs = append(pairs, key+"="+v)
+		}
+	}
+	if len(pairs) == 0 {
+		return dir + "/index.json", nil
+	}
+	sort.Strings(pairs)
+	h := fnv.New32a()
+	h.Write([]byte(strings.Join(pairs, "&")))
+	return fmt.Sprintf("%s/q-%08x.json", dir, h.Sum32()), nil
+}
+
+// rewriteHTML 将页面中的站内绝对地址改写为相对 from 的地址，并在 <head> 开头注入映射脚本
+func (e *Exporter) rewriteHTML(from, doc string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range scriptRegex.FindAllStringIndex(doc, -1) {
+		b.WriteString(e.rewriteMarkup(from, doc[last:loc[0]]))
+		script := doc[loc[0]:loc[1]]
+		end := strings.IndexByte(script, '>') + 1
+		b.WriteString(e.rewriteMarkup(from, script[:end]))
+		b.WriteString(script[end:])
+		last = loc[1]
+	}
+	b.WriteString(e.rewriteMarkup(from, doc[last:]))
+
+	root := rootOf(from)
+	shim := fmt.Sprintf(`<script>window.__STATIC_ROOT__ = %q;</script><script src="%s"></script>`, root, root+shimFile)
+	out := b.String()
+	if loc := headRegex.FindStringIndex(out); loc != nil {
+		return out[:loc[1]] + shim + out[loc[1]:]
+	}
+	return shim + out
+}
+
+// rewriteMarkup 改写 HTML 片段中属性与内联样式里的地址
+func (e *Exporter) rewriteMarkup(from, markup string) string {
+	markup = attrRegex.ReplaceAllStringFunc(markup, func(m string) string {
+		parts := attrRegex.FindStringSubmatch(m)
+		quote, value := parts[2][:1], parts[2][1:len(parts[2])-1]
+		return parts[1] + quote + e.relative(from, value) + quote
+	})
+	markup = srcsetRegex.ReplaceAllStringFunc(markup, func(m string) string {
+		parts := srcsetRegex.FindStringSubmatch(m)
+		quote, value := parts[2][:1], parts[2][1:len(parts[2])-1]
+		candidates := strings.Split(value, ",")
+		for i, c := range candidates {
+			fields := strings.Fields(c)
+			if len(fields) > 0 {
+				fields[0] = e.relative(from, fields[0])
+				candidates[i] = strings.Join(fields, " ")
+			}
+		}
+		return parts[1] + quote + strings.Join(candidates, ", ") + quote
+	})
+	return e.rewriteCSS(from, markup)
+}
+
+// rewriteCSS 改写样式中 url(...) 的地址
+func (e *Exporter) rewriteCSS(from, css string) string {
+	return cssURLRegex.ReplaceAllStringFunc(css, func(m string) string {
+		parts := cssURLRegex.FindStringSubmatch(m)
+		return "url(" + parts[1] + e.relative(from, parts[2]) + parts[3] + ")"
+	})
+}
+
+// relative 将站内绝对地址改写为相对 from 的地址
+// 只改写镜像中存在的页面、静态资源与公开附件的下载链接，其他地址原样保留
+func (e *Exporter) relative(from, target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+
+	suffix := ""
+	if u.RawQuery != "" {
+		suffix = "?" + u.RawQuery
+	}
+	file, ok := e.files[strings.TrimSuffix(u.Path, "/")]
+	if u.Path == "/" {
+		file, ok = e.files["/"]
+	}
+	if !ok {
+		for _, prefix := range assetPrefixes {
+			if strings.HasPrefix(u.Path, prefix) {
+				file, ok = strings.TrimPrefix(u.Path, "/"), true
+				break
+			}
+		}
+	}
+	if !ok && u.Path == "/api/attachments/download" {
+		file, ok = e.downloads[u.Query().Get("id")]
+		suffix = ""
+	}
+	if !ok {
+		return target
+	}
+	if u.Fragment != "" {
+		suffix += "#" + u.EscapedFragment()
+	}
+	return rootOf(from) + escapePath(file) + suffix
+}
+
+// rootOf 从镜像中的文件回到镜像根目录的相对前缀
+func rootOf(file string) string {
+	depth := strings.Count(file, "/")
+	if depth == 0 {
+		return "./"
+	}
+	return strings.Repeat("../", depth)
+}
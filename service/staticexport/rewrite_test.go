@@ -0,0 +1,76 @@
+package staticexport
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSnapshotFile(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/api/archive", "api/archive/index.json"},
+		{"/api/passages/12", "api/passages/12/index.json"},
+		// 参数顺序与编码方式不影响文件名
+		{"/api/passages?limit=1000&offset=0&category=%E6%8A%80%E6%9C%AF", "api/passages/q-d5936c14.json"},
+		{"/api/passages?category=技术&offset=0&limit=1000", "api/passages/q-d5936c14.json"},
+	}
+	for _, tt := range tests {
+		got, err := SnapshotFile(tt.target)
+		if err != nil {
+			t.Fatalf("SnapshotFile(%q): %v", tt.target, err)
+		}
+		if got != tt.want {
+			t.Errorf("SnapshotFile(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+
+	if _, err := SnapshotFile("/passage/a"); err == nil {
+		t.Error("expected error for non-API path")
+	}
+}
+
+func TestRewriteHTML(t *testing.T) {
+	e := New(nil, Options{})
+	e.files["/"] = "index.html"
+	e.files["/about"] = "about/index.html"
+	e.files["/tag/博客"] = "tag/博客/index.html"
+	e.downloads["3"] = "attachments/a b.pdf"
+
+	doc := `<html><head><title>t</title></head><body>` +
+		`<a href="/about#team">关于</a>` +
+		`<a href="/tag/%E5%8D%9A%E5%AE%A2">标签</a>` +
+		`<a href="/admin">后台</a>` +
+		`<a href="https://example.com/about">外链</a>` +
+		`<a href="/api/attachments/download?id=3">附件</a>` +
+		`<img src="/img/a.png" srcset="/img/_variants/a.png.480w.png 480w, /img/a.png 800w">` +
+		`<div style="background: url('/img/bg.webp')"></div>` +
+		`<script src="/js/app.js"></script>` +
+		`<script>el.innerHTML = '<a href="/about">x</a>';</script>` +
+		`</body></html>`
+
+	got := e.rewriteHTML("passage/2026/01/02/a/index.html", doc)
+
+	for _, want := range []string{
+		`<head><script>window.__STATIC_ROOT__ = "../../../../../";</script><script src="../../../../../js/static-export.js"></script>`,
+		`href="../../../../../about/index.html#team"`,
+		`href="../../../../../tag/%E5%8D%9A%E5%AE%A2/index.html"`,
+		`href="/admin"`,
+		`href="https://example.com/about"`,
+		`href="../../../../../attachments/a%20b.pdf"`,
+		`src="../../../../../img/a.png"`,
+		`srcset="../../../../../img/_variants/a.png.480w.png 480w, ../../../../../img/a.png 800w"`,
+		`url('../../../../../img/bg.webp')`,
+		`<script src="../../../../../js/app.js">`,
+		`el.innerHTML = '<a href="/about">x</a>';`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rewritten HTML missing %s\n%s", want, got)
+		}
+	}
+
+	if got := e.relative("index.html", "/"); got != "./index.html" {
+		t.Errorf("relative(index.html, /) = %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+// 静态镜像运行时：把页面脚本发出的 /api 请求映射到导出时保存的 JSON 快照
+// 快照文件名的计算方式需要与 staticexport.SnapshotFile 保持一致
+(function () {
+  'use strict';
+
+  var root = window.__STATIC_ROOT__ || './';
+  var originalFetch = window.fetch.bind(window);
+  var readOnlyMessage = '静态镜像为只读页面，不支持此功能';
+
+  // FNV-1a 32 位哈希，按 UTF-8 字节计算
+  function fnv1a(str) {
+    var bytes = new TextEncoder().encode(str);
+    var hash = 0x811c9dc5;
+    for (var i = 0; i < bytes.length; i++) {
+      hash ^= bytes[i];
+      hash = Math.imul(hash, 0x01000193) >>> 0;
+    }
+    return ('0000000' + hash.toString(16)).slice(-8);
+  }
+
+  function snapshotPath(url) {
+    var dir = url.pathname.replace(/^\/+|\/+$/g, '');
+    var pairs = [];
+    url.searchParams.forEach(function (value, key) {
+      pairs.push(key + '=' + value);
+    });
+    if (pairs.length === 0) {
+      return root + dir + '/index.json';
+    }
+    pairs.sort();
+    return root + dir + '/q-' + fnv1a(pairs.join('&')) + '.json';
+  }
+
+  function readOnlyResponse() {
+    return new Response(JSON.stringify({ success: false, message: readOnlyMessage }), {
+      status: 200,
+      headers: { 'Content-Type': 'application/json' }
+    });
+  }
+
+  function apiURL(input) {
+    var raw = typeof input === 'string' ? input : (input && input.url) || String(input);
+    var url = new URL(raw, window.location.href);
+    if (url.origin !== window.location.origin || url.pathname.indexOf('/api/') !== 0) {
+      return null;
+    }
+    return url;
+  }
+
+  window.fetch = function (input, init) {
+    var url = apiURL(input);
+    if (!url) {
+      return originalFetch(input, init);
+    }
+    var method = ((init && init.method) || (input && input.method) || 'GET').toUpperCase();
+    if (method !== 'GET') {
+      return Promise.resolve(readOnlyResponse());
+    }
+    return originalFetch(snapshotPath(url)).then(function (res) {
+      return res.ok ? res : readOnlyResponse();
+    }, readOnlyResponse);
+  };
+
+  // 页面脚本生成的站内绝对链接在点击时改写为镜像中的相对地址
+  function pagePath(href) {
+    var url = new URL(href, window.location.origin);
+    var path = url.pathname.replace(/^\/+/, '');
+    var last = path.split('/').pop();
+    if (path !== '' && last.indexOf('.') < 0) {
+      path = path.replace(/\/?$/, '/index.html');
+    } else if (path === '') {
+      path = 'index.html';
+    }
+    return root + path + url.hash;
+  }
+
+  function openDownload(id) {
+    originalFetch(root + 'api/attachments/download/index.json')
+      .then(function (res) { return res.json(); })
+      .then(function (result) {
+        var file = result.data && result.data[id];
+        if (file) {
+          window.location.href = root + file.split('/').map(encodeURIComponent).join('/');
+        } else {
+          alert('静态镜像中不包含该附件');
+        }
+      })
+      .catch(function () { alert('静态镜像中不包含该附件'); });
+  }
+
+  document.addEventListener('click', function (event) {
+    var link = event.target.closest && event.target.closest('a[href^="/"]');
+    if (!link || event.defaultPrevented || link.getAttribute('href').indexOf('//') === 0) {
+      return;
+    }
+    var href = link.getAttribute('href');
+    event.preventDefault();
+    var url = new URL(href, window.location.origin);
+    if (url.pathname === '/api/attachments/download') {
+      openDownload(url.searchParams.get('id'));
+      return;
+    }
+    if (link.target === '_blank') {
+      window.open(pagePath(href), '_blank', 'noopener');
+    } else {
+      window.location.href = pagePath(href);
+    }
+  });
+})();
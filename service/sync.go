@@ -14,6 +14,9 @@ import (
 	"myblog-gogogo/db"
 	"myblog-gogogo/db/models"
 	"myblog-gogogo/db/repositories"
+	"myblog-gogogo/pkg/frontmatter"
+	"myblog-gogogo/pkg/sanitize"
+	"myblog-gogogo/pkg/toc"
 )
 
 // SyncService 同步服务
@@ -47,12 +50,11 @@ func (s *SyncService) SyncAll() error {
 
 // SyncFile 同步单个 markdown 文件到数据库
 func (s *SyncService) SyncFile(filePath string, tagsParam string) error {
-	// 解析 markdown 文件
-	doc, err := ParseMarkdownFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to parse markdown file: %w", err)
-	}
+	return s.SyncFileFrom(filePath, tagsParam, models.RevisionSourceSync, "")
+}
 
+// SyncFileFrom 同步单个 markdown 文件到数据库，source 和 author 记录到文章修订历史中
+func (s *SyncService) SyncFileFrom(filePath string, tagsParam string, source, author string) error {
 	// 从文件路径提取信息
 	// 获取当前工作目录
 	workingDir, err := os.Getwd()
@@ -63,6 +65,23 @@ func (s *SyncService) SyncFile(filePath string, tagsParam string) error {
 	relativePath := strings.TrimPrefix(filePath, markdownDir+string(filepath.Separator))
 	relativePath = strings.TrimSuffix(relativePath, ".md")
 
+	// 检查是否已存在（通过文件路径）
+	existingPassage, err := s.findPassageByFilePath(relativePath)
+	if err != nil {
+		return fmt.Errorf("failed to find existing passage: %w", err)
+	}
+
+	// 解析 markdown 文件，文件内容不能决定过滤策略：新文章以及上传、导入的文件使用 editor 策略，
+	// 同步已有文章时沿用后台保存时按用户角色确定的策略
+	policy := sanitize.NameEditor
+	if existingPassage != nil && source != models.RevisionSourceUpload && source != models.RevisionSourceImport {
+		policy = existingPassage.HTMLPolicy
+	}
+	doc, err := ParseMarkdownFileAs(filePath, policy)
+	if err != nil {
+		return fmt.Errorf("failed to parse markdown file: %w", err)
+	}
+
 	// 提取日期
 	parts := strings.Split(relativePath, "/")
 	var year, month, day string
@@ -88,67 +107,123 @@ func (s *SyncService) SyncFile(filePath string, tagsParam string) error {
 	// 生成摘要
 	summary := s.extractSummary(doc.Content)
 
-	// 使用传入的标签参数，如果没有则从路径提取
+	// 使用传入的标签参数，其次是头部元数据中的标签，最后从路径提取
 	tags := tagsParam
+	if tags == "" {
+		tags = doc.FrontMatter.TagString()
+	}
 	if tags == "" {
 		tags = s.extractTags(relativePath)
 	}
 
-	// 检查是否已存在（通过文件路径）
-	existingPassage, err := s.findPassageByFilePath(relativePath)
-	if err != nil {
-		return fmt.Errorf("failed to find existing passage: %w", err)
-	}
+	revisionService := NewRevisionService()
 
 	if existingPassage != nil {
+		// 覆盖前保存修订功能启用前的原始内容
+		if err := revisionService.RecordBaseline(existingPassage); err != nil {
+			log.Printf("Warning: 记录初始修订失败: %v\n", err)
+		}
+
 		// 更新现有文章
 		existingPassage.Title = doc.Title
 		existingPassage.Content = doc.Content
+		existingPassage.TOC = toc.Marshal(doc.TOC)
+		existingPassage.RendererVersion = RendererVersion
 		existingPassage.OriginalContent = doc.OriginalContent
 		existingPassage.Summary = summary
 		existingPassage.Status = "published"
 		existingPassage.FilePath = relativePath
+		existingPassage.HTMLPolicy = policy
 		existingPassage.UpdatedAt = time.Now()
+		// 头部元数据覆盖默认值
+		doc.FrontMatter.ApplyTo(existingPassage)
 
 		if err := s.repo.Update(existingPassage); err != nil {
 			return fmt.Errorf("failed to update passage: %w", err)
 		}
 
+		if _, err := revisionService.Record(existingPassage, author, source); err != nil {
+			log.Printf("Warning: 记录修订失败: %v\n", err)
+		}
+
 		// 更新标签关联（统一使用关联表）
 		if err := s.UpdatePassageTags(existingPassage.ID, tags); err != nil {
 			log.Printf("Warning: 更新标签关联失败: %v\n", err)
 		}
 
-		fmt.Printf("Updated passage: %s (from %s)\n", doc.Title, relativePath)
+		if err := UpdatePassageLinks(existingPassage); err != nil {
+			log.Printf("Warning: 更新双链失败: %v\n", err)
+		}
+
+		if err := syncPassageSeries(existingPassage.ID, doc.FrontMatter); err != nil {
+			log.Printf("Warning: 更新系列失败: %v\n", err)
+		}
+		if err := syncPassageMeta(existingPassage.ID, doc.FrontMatter); err != nil {
+			log.Printf("Warning: 设置分享元数据失败: %v\n", err)
+		}
+
+		fmt.Printf("Updated passage: %s (from %s)\n", existingPassage.Title, relativePath)
 	} else {
 		// 创建新文章
 		passage := &models.Passage{
 			Title:           doc.Title,
 			Content:         doc.Content,
 			OriginalContent: doc.OriginalContent,
+			TOC:             toc.Marshal(doc.TOC),
+			RendererVersion: RendererVersion,
 			Summary:         summary,
 			Author:          "Admin",
 			Status:          "published",
 			FilePath:        relativePath,
+			ShowTitle:       true,
+			HTMLPolicy:      policy,
 			CreatedAt:       createdAt,
 			UpdatedAt:       time.Now(),
 		}
+		doc.FrontMatter.ApplyTo(passage)
 
 		if err := s.repo.Create(passage); err != nil {
 			return fmt.Errorf("failed to create passage: %w", err)
 		}
 
+		if _, err := revisionService.Record(passage, author, source); err != nil {
+			log.Printf("Warning: 记录修订失败: %v\n", err)
+		}
+
 		// 创建标签关联（统一使用关联表）
 		if err := s.UpdatePassageTags(passage.ID, tags); err != nil {
 			log.Printf("Warning: 创建标签关联失败: %v\n", err)
 		}
 
-		fmt.Printf("Created passage: %s (from %s)\n", doc.Title, relativePath)
+		if err := UpdatePassageLinks(passage); err != nil {
+			log.Printf("Warning: 更新双链失败: %v\n", err)
+		}
+
+		if err := syncPassageSeries(passage.ID, doc.FrontMatter); err != nil {
+			log.Printf("Warning: 创建系列失败: %v\n", err)
+		}
+		if err := syncPassageMeta(passage.ID, doc.FrontMatter); err != nil {
+			log.Printf("Warning: 设置分享元数据失败: %v\n", err)
+		}
+
+		fmt.Printf("Created passage: %s (from %s)\n", passage.Title, relativePath)
 	}
 
 	return nil
 }
 
+// syncPassageSeries 按头部元数据设置文章所属的系列，头部没有系列时保留后台设置的系列
+func syncPassageSeries(passageID int, fm *frontmatter.FrontMatter) error {
+	if fm == nil || fm.Series == "" {
+		return nil
+	}
+	part := 0
+	if fm.SeriesPart != nil {
+		part = *fm.SeriesPart
+	}
+	return SetPassageSeries(passageID, fm.Series, part)
+}
+
 // findPassageByFilePath 通过文件路径查找文章
 func (s *SyncService) findPassageByFilePath(filePath string) (*models.Passage, error) {
 	passages, err := s.repo.GetAll(1000, 0)
@@ -300,7 +375,7 @@ func (s *SyncService) WatchAndSync() error {
 				fmt.Printf("File created: %s\n", event.Name)
 				// 等待一小段时间，确保文件写入完成
 				time.Sleep(100 * time.Millisecond)
-				if err := s.SyncFile(event.Name, ""); err != nil {
+				if err := s.SyncFileFrom(event.Name, "", models.RevisionSourceFileWatch, ""); err != nil {
 					fmt.Printf("Failed to sync created file: %v\n", err)
 				}
 
@@ -308,7 +383,7 @@ func (s *SyncService) WatchAndSync() error {
 				fmt.Printf("File modified: %s\n", event.Name)
 				// 等待一小段时间，确保文件写入完成
 				time.Sleep(100 * time.Millisecond)
-				if err := s.SyncFile(event.Name, ""); err != nil {
+				if err := s.SyncFileFrom(event.Name, "", models.RevisionSourceFileWatch, ""); err != nil {
 					fmt.Printf("Failed to sync modified file: %v\n", err)
 				}
 
@@ -375,6 +450,9 @@ func (s *SyncService) removePassageByFilePath(filePath string) error {
 		if err := s.repo.Delete(existingPassage.ID); err != nil {
 			return fmt.Errorf("failed to delete passage: %w", err)
 		}
+		if err := RemovePassageLinks(existingPassage); err != nil {
+			log.Printf("Warning: 更新双链失败: %v\n", err)
+		}
 		fmt.Printf("Deleted passage: %s (from %s)\n", existingPassage.Title, relativePath)
 	}
 
@@ -398,27 +476,8 @@ func (s *SyncService) UpdatePassageTags(passageID int, tagsStr string) error {
 		return nil
 	}
 
-	// 解析标签（支持 JSON 数组和逗号分隔的字符串）
-	var tagNames []string
-	if strings.HasPrefix(tagsStr, "[") {
-		// JSON 格式
-		if err := json.Unmarshal([]byte(tagsStr), &tagNames); err == nil {
-			// 解析成功
-		} else {
-			// 解析失败，尝试作为逗号分隔处理
-			tagNames = strings.Split(tagsStr, ",")
-		}
-	} else {
-		// 逗号分隔格式
-		tagNames = strings.Split(tagsStr, ",")
-	}
-
 	// 为每个标签创建关联
-	for _, tagName := range tagNames {
-		tagName = strings.TrimSpace(tagName)
-		if tagName == "" {
-			continue
-		}
+	for _, tagName := range ParseTagNames(tagsStr) {
 
 		// 查找或创建标签
 		var tagID int
@@ -462,3 +521,95 @@ func (s *SyncService) UpdatePassageTags(passageID int, tagsStr string) error {
 
 	return nil
 }
+
+// ParseTagNames 解析标签字符串（支持 JSON 数组和逗号分隔的字符串），返回去除空白后的标签名
+func ParseTagNames(tagsStr string) []string {
+	var rawNames []string
+	if strings.HasPrefix(tagsStr, "[") {
+		// JSON 格式，解析失败时按逗号分隔处理
+		if err := json.Unmarshal([]byte(tagsStr), &rawNames); err != nil {
+			rawNames = strings.Split(tagsStr, ",")
+		}
+	} else {
+		rawNames = strings.Split(tagsStr, ",")
+	}
+
+	tagNames := make([]string, 0, len(rawNames))
+	for _, name := range rawNames {
+		if name = strings.TrimSpace(name); name != "" {
+			tagNames = append(tagNames, name)
+		}
+	}
+	return tagNames
+}
+
+// GetPassageTagNames 获取文章关联的标签名
+func (s *SyncService) GetPassageTagNames(passageID int) ([]string, error) {
+	tagIDs, err := db.GetPassageTagRepository().GetTagIDsByPassageID(passageID)
+	if err != nil {
+		return nil, fmt.Errorf("获取文章标签关联失败: %w", err)
+	}
+
+	tagRepo := db.GetTagRepository()
+	tagNames := make([]string, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		tag, err := tagRepo.GetByID(tagID)
+		if err != nil || tag == nil {
+			continue
+		}
+		tagNames = append(tagNames, tag.Name)
+	}
+	return tagNames, nil
+}
+
+// RewriteFrontMatter 按数据库中的文章字段重写 markdown 文件的头部元数据
+// 文件不存在或原本没有头部时不做修改，避免给普通文件加上头部
+func (s *SyncService) RewriteFrontMatter(passage *models.Passage) error {
+	filePath := PassageMarkdownPath(passage.FilePath)
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	fm, body, err := frontmatter.Parse(content)
+	if err != nil || fm == nil {
+		return err
+	}
+
+	tagNames, err := s.GetPassageTagNames(passage.ID)
+	if err != nil {
+		return err
+	}
+
+	fm.Update(passage, tagNames)
+
+	// 系列写回文件，头部原本指定了顺序时同时更新顺序
+	series, member, err := GetPassageSeries(passage.ID)
+	if err != nil {
+		return err
+	}
+	if series != nil {
+		part := 0
+		if fm.SeriesPart != nil {
+			part = member.Position
+		}
+		fm.UpdateSeries(series.Name, part)
+	} else {
+		fm.UpdateSeries("", 0)
+	}
+
+	meta, err := GetPassageMeta(passage.ID)
+	if err != nil {
+		return err
+	}
+	fm.UpdateMeta(meta.Title, meta.Description, meta.Image)
+
+	fullContent := append(append(fm.Marshal(), '\n'), body...)
+	if err := os.WriteFile(filePath, fullContent, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
 )
 
 // UploadService 上传服务
@@ -108,7 +109,7 @@ func (s *UploadService) handleMarkdownUpload(content []byte, header *multipart.F
 	}
 
 	// 同步到数据库，传递标签参数
-	if err := s.syncService.SyncFile(filePath, tags); err != nil {
+	if err := s.syncService.SyncFileFrom(filePath, tags, models.RevisionSourceUpload, ""); err != nil {
 		// 记录错误但不返回，因为文件已经成功上传
 		fmt.Printf("同步到数据库失败: %v\n", err)
 	}
@@ -170,6 +171,9 @@ func (s *UploadService) handleImageUpload(content []byte, header *multipart.File
 		return nil, fmt.Errorf("写入文件失败: %w", err)
 	}
 
+	// 后台生成缩略图与 WebP 版本
+	QueueImageVariants(filePath)
+
 	// 构建访问URL
 	var urlBuilder strings.Builder
 	urlBuilder.Grow(len("/img/") + len(fileName))
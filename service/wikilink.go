@@ -0,0 +1,281 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"myblog-gogogo/db"
+	"myblog-gogogo/db/models"
+	"myblog-gogogo/pkg/slug"
+)
+
+// WikiLinkNode 双链节点，[[标题]]、[[别名|显示文本]]、[[标题#小节]]
+type WikiLinkNode struct {
+	ast.BaseInline
+	Target   string          // 链接目标，文章别名或标题
+	Fragment string          // # 之后的小节标题
+	Label    string          // 显示文本
+	Passage  *models.Passage // 解析到的文章，nil 表示目标不存在
+}
+
+// KindWikiLink 双链节点类型
+var KindWikiLink = ast.NewNodeKind("WikiLink")
+
+// Kind 实现 Node 接口
+func (n *WikiLinkNode) Kind() ast.NodeKind {
+	return KindWikiLink
+}
+
+// Dump 实现 Node 接口
+func (n *WikiLinkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Target": n.Target, "Label": n.Label}, nil)
+}
+
+// wikiLinksKey 解析上下文中记录本文所有双链的键
+var wikiLinksKey = parser.NewContextKey()
+
+// wikiLinkParser 解析 [[...]]，链接不能跨行
+type wikiLinkParser struct{}
+
+func (p *wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (p *wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+	end := bytes.Index(line[2:], []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+	inner := line[2 : 2+end]
+	if len(bytes.TrimSpace(inner)) == 0 || bytes.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+
+	target, label, _ := strings.Cut(string(inner), "|")
+	target, fragment, _ := strings.Cut(target, "#")
+	target = strings.TrimSpace(target)
+	fragment = strings.TrimSpace(fragment)
+	label = strings.TrimSpace(label)
+	if target == "" {
+		return nil
+	}
+	if label == "" {
+		label = target
+		if fragment != "" {
+			label += " > " + fragment
+		}
+	}
+
+	block.Advance(end + 4)
+	return &WikiLinkNode{Target: target, Fragment: fragment, Label: label}
+}
+
+// WikiLinkASTTransformer 将双链解析到文章，并记录到解析上下文供 passage_links 使用
+type WikiLinkASTTransformer struct{}
+
+// Transform 转换 AST
+func (t *WikiLinkASTTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	resolved := make(map[string]*models.Passage)
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*WikiLinkNode)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		key := wikiLinkKey(link.Target)
+		passage, seen := resolved[key]
+		if !seen {
+			passage = ResolveWikiLink(link.Target)
+			resolved[key] = passage
+		}
+		link.Passage = passage
+		return ast.WalkContinue, nil
+	})
+	pc.Set(wikiLinksKey, resolved)
+}
+
+// WikiLinkRenderer 双链渲染器，目标不存在时渲染为带 wikilink-dangling 类的 span
+type WikiLinkRenderer struct{}
+
+// RegisterFuncs 注册渲染函数
+func (r *WikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindWikiLink, r.renderWikiLink)
+}
+
+// renderWikiLink 渲染双链节点
+func (r *WikiLinkRenderer) renderWikiLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*WikiLinkNode)
+	label := html.EscapeString(n.Label)
+	if n.Passage == nil {
+		fmt.Fprintf(w, `<span class="wikilink wikilink-dangling" title="文章不存在：%s">%s</span>`, html.EscapeString(n.Target), label)
+		return ast.WalkSkipChildren, nil
+	}
+
+	href := PassagePath(n.Passage)
+	if n.Fragment != "" {
+		href += "#" + headingAnchor(n.Fragment)
+	}
+	fmt.Fprintf(w, `<a class="wikilink" href="%s">%s</a>`, html.EscapeString(href), label)
+	return ast.WalkSkipChildren, nil
+}
+
+// WikiLinkExtension 双链扩展
+type WikiLinkExtension struct{}
+
+// Extend 扩展 Goldmark
+func (e *WikiLinkExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// 需要先于普通链接解析
+		parser.WithInlineParsers(util.Prioritized(&wikiLinkParser{}, 199)),
+		parser.WithASTTransformers(util.Prioritized(&WikiLinkASTTransformer{}, 100)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&WikiLinkRenderer{}, 100)))
+}
+
+// ResolveWikiLink 按别名、标题的顺序查找已发布的双链目标文章，找不到时返回 nil
+func ResolveWikiLink(target string) *models.Passage {
+	repo := db.GetPassageRepository()
+	if repo == nil {
+		return nil
+	}
+
+	if s := slug.Normalize(target); s != "" {
+		if passage, err := repo.GetBySlug(s); err == nil && passage != nil && passage.Status == "published" {
+			return passage
+		}
+	}
+	if passage, err := repo.GetByTitle(strings.TrimSpace(target)); err == nil && passage != nil && passage.Status == "published" {
+		return passage
+	}
+	return nil
+}
+
+// wikiLinkKey passage_links 中保存的链接目标文本
+func wikiLinkKey(target string) string {
+	return strings.ToLower(strings.TrimSpace(target))
+}
+
+// headingAnchor 小节标题对应的锚点，与 toc 包生成的标题 ID 一致
+func headingAnchor(heading string) string {
+	if id := slug.Normalize(heading); id != "" {
+		return id
+	}
+	return "section"
+}
+
+// ExtractWikiLinks 解析文章正文中的双链，返回按目标文本排序的链接记录
+func ExtractWikiLinks(passage *models.Passage) []models.PassageLink {
+	pc := parser.NewContext()
+	md.Parser().Parse(text.NewReader([]byte(passage.OriginalContent)), parser.WithContext(pc))
+	resolved, _ := pc.Get(wikiLinksKey).(map[string]*models.Passage)
+
+	links := make([]models.PassageLink, 0, len(resolved))
+	for target, p := range resolved {
+		link := models.PassageLink{SourceID: passage.ID, Target: target}
+		if p != nil {
+			link.TargetID = p.ID
+		}
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Target < links[j].Target })
+	return links
+}
+
+// UpdatePassageLinks 保存文章正文中的双链，并重新渲染链接到该文章的其他文章
+// 文章创建、改名、发布或删除后，其他文章中指向它的双链随之变为有效或失效
+func UpdatePassageLinks(passage *models.Passage) error {
+	links := ExtractWikiLinks(passage)
+
+	// 新建文章渲染时自身尚未入库，链接到自身的双链需要重新渲染
+	if linksToSelf(links, passage) {
+		if err := RenderPassage(passage); err != nil {
+			return err
+		}
+		if err := db.GetPassageRepository().UpdateRendered(passage); err != nil {
+			return err
+		}
+		links = ExtractWikiLinks(passage)
+	}
+
+	if err := db.GetPassageLinkRepository().ReplaceForSource(passage.ID, links); err != nil {
+		return fmt.Errorf("failed to save passage links: %w", err)
+	}
+	return refreshReferrers(passage)
+}
+
+// linksToSelf 判断是否存在指向文章自身、且渲染结果中仍有未解析双链的情况
+func linksToSelf(links []models.PassageLink, passage *models.Passage) bool {
+	if !strings.Contains(passage.Content, "wikilink-dangling") {
+		return false
+	}
+	for _, link := range links {
+		if link.TargetID == passage.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// RemovePassageLinks 文章永久删除后调用，重新渲染链接到该文章的其他文章
+func RemovePassageLinks(passage *models.Passage) error {
+	if err := db.GetPassageLinkRepository().ReplaceForSource(passage.ID, nil); err != nil {
+		return fmt.Errorf("failed to delete passage links: %w", err)
+	}
+	return refreshReferrers(passage)
+}
+
+// refreshReferrers 重新渲染链接到 passage（按 ID、标题或别名）的文章
+func refreshReferrers(passage *models.Passage) error {
+	targets := []string{wikiLinkKey(passage.Title)}
+	if passage.Slug != "" {
+		targets = append(targets, wikiLinkKey(passage.Slug))
+	}
+
+	linkRepo := db.GetPassageLinkRepository()
+	ids, err := linkRepo.GetSourceIDs(passage.ID, targets)
+	if err != nil {
+		return fmt.Errorf("failed to find referrers: %w", err)
+	}
+
+	repo := db.GetPassageRepository()
+	for _, id := range ids {
+		if id == passage.ID {
+			continue
+		}
+		source, err := repo.GetByID(id)
+		if err != nil || source == nil {
+			continue
+		}
+		// 只更新渲染结果，不改变更新时间、不记录修订
+		if err := RenderPassage(source); err != nil {
+			log.Printf("Warning: 重新渲染文章 %d 失败: %v", id, err)
+			continue
+		}
+		if err := repo.UpdateRendered(source); err != nil {
+			log.Printf("Warning: 更新文章 %d 失败: %v", id, err)
+			continue
+		}
+		if err := linkRepo.ReplaceForSource(source.ID, ExtractWikiLinks(source)); err != nil {
+			log.Printf("Warning: 更新文章 %d 的双链失败: %v", id, err)
+		}
+	}
+	return nil
+}